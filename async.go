@@ -0,0 +1,53 @@
+package graphql
+
+import (
+	"context"
+)
+
+// Future is the pending result of an operation started with RunAsync.
+type Future struct {
+	done chan struct{}
+	err  Error
+}
+
+// RunAsync starts executing op in a new goroutine and returns at once.
+// The response is decoded into resp, which must not be read before Wait
+// returns.
+//
+//	merchant := client.RunAsync(ctx, merchantReq, &merchantResp)
+//	payouts := client.RunAsync(ctx, payoutsReq, &payoutsResp)
+//	if err := graphql.WaitAll(merchant, payouts); err != nil {
+//		return err
+//	}
+func (c *Client) RunAsync(ctx context.Context, op Operation, resp interface{}) *Future {
+	f := &Future{done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		f.err = c.Run(ctx, op, resp)
+	}()
+	return f
+}
+
+// Wait blocks until the operation completes and returns its error.
+func (f *Future) Wait() Error {
+	<-f.done
+	return f.err
+}
+
+// Done is closed when the operation completes, for use in select
+// statements.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// WaitAll waits for every future and returns the first error in argument
+// order, or nil when all operations succeeded.
+func WaitAll(futures ...*Future) Error {
+	var first Error
+	for _, f := range futures {
+		if err := f.Wait(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
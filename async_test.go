@@ -0,0 +1,46 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRunAsync(t *testing.T) {
+	is := is.New(t)
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		if r.Header.Get("X-Fail") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = io.WriteString(w, `{"data":{"value":"some data"}}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+
+	var a, b struct{ Value string }
+	fa := client.RunAsync(context.Background(), NewRequest("query {}"), &a)
+	failing := NewRequest("query {}")
+	failing.Header("X-Fail", "1")
+	fb := client.RunAsync(context.Background(), failing, &b)
+
+	select {
+	case <-fa.Done():
+		t.Fatal("future completed before the response")
+	default:
+	}
+	close(release)
+
+	err := WaitAll(fa, fb)
+	is.Equal(err.Error(), "request failed with status: 500 Internal Server Error")
+	is.NoErr(fa.Wait())
+	is.Equal(a.Value, "some data")
+}
@@ -0,0 +1,110 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// DoBatch sends ops in a single request whose body is the JSON array of
+// their payloads, as Apollo Server and Absinthe batching accept, and
+// decodes the array of responses into resps, which is indexed like ops
+// and may be nil, as may its entries, to skip decoding. The returned
+// errors are indexed like ops and nil for the operations that succeeded;
+// a failure of the request itself is returned for every operation.
+//
+// The headers, tags and endpoint of the first operation apply to the
+// whole batch. Operations with files cannot be batched.
+//
+//	errs := client.DoBatch(ctx, []Operation{merchantReq, payoutsReq}, []interface{}{&merchant, &payouts})
+func (c *Client) DoBatch(ctx context.Context, ops []Operation, resps []interface{}) []Error {
+	errs := make([]Error, len(ops))
+	if len(ops) == 0 {
+		return errs
+	}
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	if err := c.doBatch(ctx, ops, resps, errs); err != nil {
+		fill(errs, err)
+	}
+	for i, err := range errs {
+		c.countError(err)
+		errs[i] = c.translate(err)
+	}
+	return errs
+}
+
+// doBatch runs the batch, setting the errors of the operations in errs,
+// or returning the error of the whole batch.
+func (c *Client) doBatch(ctx context.Context, ops []Operation, resps []interface{}, errs []Error) Error {
+	if resps != nil && len(resps) != len(ops) {
+		return NewExecutionError(errors.Errorf("got %d responses for %d operations", len(resps), len(ops)))
+	}
+	payloads := make([]payload, len(ops))
+	for i, op := range ops {
+		if len(op.Files()) > 0 || len(findUploads(op.Vars())) > 0 {
+			return NewExecutionError(errors.New("cannot batch operations with files"))
+		}
+		req := c.withConvertedVars(op.Request())
+		if verr := checkVars(req.vars); verr != nil {
+			return NewExecutionError(errors.Wrapf(verr, "operation %d", i))
+		}
+		payloads[i] = c.payload(req)
+	}
+	body, merr := json.Marshal(payloads)
+	if merr != nil {
+		return NewExecutionError(errors.Wrap(merr, "encode body"))
+	}
+
+	release, err := c.acquire(ctx, ops[0])
+	if err != nil {
+		return err
+	}
+	defer release()
+	res, err := c.do(ctx, ops[0].Request(), body, "application/json; charset=utf-8", nil)
+	if err != nil {
+		return err
+	}
+	if !successful(res) {
+		return c.decodeErrorResponse(ctx, ops[0], res, nil)
+	}
+	body, err = c.readBody(ctx, res)
+	if err != nil {
+		return err
+	}
+	c.teeResponse(ops[0], res, body)
+	body, cerr := toUTF8(res.Header.Get("Content-Type"), body)
+	if cerr != nil {
+		return NewExecutionError(errors.Wrap(cerr, "decoding response"))
+	}
+
+	var results []json.RawMessage
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		// Servers without batching answer with a single response,
+		// usually errors.
+		if err := decodeJSON(ops[0], res, body, nil); err != nil {
+			return err
+		}
+		return NewExecutionError(errors.Wrapf(ErrNotJSON, "expected an array of responses: %s", excerpt(trimmed)))
+	}
+	if err := json.Unmarshal(body, &results); err != nil {
+		return NewExecutionError(errors.Wrap(err, "decoding response"))
+	}
+	if len(results) != len(ops) {
+		return NewExecutionError(errors.Errorf("got %d responses for %d operations", len(results), len(ops)))
+	}
+	for i, op := range ops {
+		var resp interface{}
+		if resps != nil {
+			resp = resps[i]
+		}
+		errs[i] = decodeJSON(op, res, results[i], resp)
+		if _, isExecErr := errs[i].(*ExecutionError); !isExecErr && resp != nil && len(c.enums) > 0 {
+			c.convertEnums(reflect.ValueOf(resp))
+		}
+	}
+	return nil
+}
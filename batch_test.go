@@ -0,0 +1,71 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDoBatch(t *testing.T) {
+	is := is.New(t)
+
+	var payloads []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.NoErr(json.NewDecoder(r.Body).Decode(&payloads))
+		_, _ = io.WriteString(w, `[
+			{"data":{"merchant":{"name":"Cafe"}}},
+			{"errors":[{"message":"no payouts","extensions":{"code":"NOT_FOUND"}}]},
+			{"data":{"close":{"successful":true,"result":{"closed":true}}}}
+		]`)
+	}))
+	defer srv.Close()
+
+	merchantReq := NewRequest("query ($id: ID!) { merchant(id: $id) { name } }")
+	merchantReq.Var("id", "m1")
+	payoutsReq := NewRequest("query { payouts { id } }")
+	closeReq := NewMutation("mutation { close { successful } }")
+
+	var merchant struct{ Merchant struct{ Name string } }
+	var closed struct {
+		Close struct {
+			Result struct{ Closed bool }
+		}
+	}
+	errs := NewClient(srv.URL).DoBatch(context.Background(),
+		[]Operation{merchantReq, payoutsReq, closeReq},
+		[]interface{}{&merchant, nil, &closed})
+
+	is.Equal(len(payloads), 3)
+	is.Equal(payloads[0]["variables"], map[string]interface{}{"id": "m1"})
+	is.Equal(payloads[2]["query"], "mutation { close { successful } }")
+
+	is.NoErr(errs[0])
+	is.Equal(merchant.Merchant.Name, "Cafe")
+	is.True(IsNotFound(errs[1]))
+	is.NoErr(errs[2])
+	is.True(closed.Close.Result.Closed)
+}
+
+func TestDoBatchUnsupported(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"errors":[{"message":"batching is disabled"}]}`)
+	}))
+	defer srv.Close()
+
+	errs := NewClient(srv.URL).DoBatch(context.Background(),
+		[]Operation{NewRequest("query { a }"), NewRequest("query { b }")}, nil)
+	is.Equal(len(errs), 2)
+	is.Equal(errs[0].Error(), "batching is disabled")
+	is.Equal(errs[1], errs[0])
+
+	errs = NewClient(srv.URL).DoBatch(context.Background(),
+		[]Operation{NewRequest("query { a }")}, []interface{}{nil, nil})
+	is.Equal(errs[0].Error(), "got 2 responses for 1 operations")
+}
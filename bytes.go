@@ -0,0 +1,46 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Bytes is binary data of a base64 scalar. It is sent in standard
+// base64 and decodes from the standard or URL-safe alphabets, padded or
+// not, since servers differ.
+type Bytes []byte
+
+// MarshalJSON encodes the data as a standard base64 string, or null when
+// it is nil.
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	if b == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(base64.StdEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON decodes a base64 string.
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*b = nil
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.Errorf("bytes must be a base64 string, got %s", data)
+	}
+	encoding := base64.RawStdEncoding
+	if strings.ContainsAny(s, "-_") {
+		encoding = base64.RawURLEncoding
+	}
+	decoded, err := encoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return errors.Wrap(err, "decoding base64")
+	}
+	*b = decoded
+	return nil
+}
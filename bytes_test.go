@@ -0,0 +1,53 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestBytes(t *testing.T) {
+	is := is.New(t)
+
+	var resp struct {
+		Std, Raw, URL, Null Bytes
+	}
+	is.NoErr(json.Unmarshal([]byte(`{"std":"+/8=","raw":"+/8","url":"-_8","null":null}`), &resp))
+	want := Bytes{0xfb, 0xff}
+	is.Equal(resp.Std, want)
+	is.Equal(resp.Raw, want)
+	is.Equal(resp.URL, want)
+	is.Equal(resp.Null, Bytes(nil))
+	is.True(json.Unmarshal([]byte(`{"std":"!!"}`), &resp) != nil)
+	is.True(json.Unmarshal([]byte(`{"std":1}`), &resp) != nil)
+
+	b, err := json.Marshal(map[string]Bytes{"data": want, "empty": {}, "nil": nil})
+	is.NoErr(err)
+	is.Equal(string(b), `{"data":"+/8=","empty":"","nil":null}`)
+}
+
+func TestBytesInMutationPayload(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"sign":{"successful":true,"result":{"signature":"+/8=","amount":"12.50"}}}}`)
+	}))
+	defer srv.Close()
+
+	var resp struct {
+		Sign struct {
+			Result struct {
+				Signature Bytes
+				Amount    Decimal
+			}
+		}
+	}
+	is.NoErr(NewClient(srv.URL).Run(context.Background(), NewMutation("mutation { sign { result { signature amount } } }"), &resp))
+	is.Equal(resp.Sign.Result.Signature, Bytes{0xfb, 0xff})
+	is.Equal(resp.Sign.Result.Amount, Decimal("12.50"))
+}
@@ -0,0 +1,129 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"mime"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// CharsetDecoder converts a response body from its charset to UTF-8.
+type CharsetDecoder func([]byte) ([]byte, error)
+
+var (
+	charsetsMu sync.RWMutex
+	charsets   = map[string]CharsetDecoder{
+		"utf-8":        decodeUTF8,
+		"utf8":         decodeUTF8,
+		"us-ascii":     decodeUTF8,
+		"ascii":        decodeUTF8,
+		"utf-16":       decodeUTF16(binary.BigEndian),
+		"utf-16be":     decodeUTF16(binary.BigEndian),
+		"utf-16le":     decodeUTF16(binary.LittleEndian),
+		"iso-8859-1":   decodeSingleByte(nil),
+		"latin1":       decodeSingleByte(nil),
+		"windows-1252": decodeSingleByte(&windows1252),
+		"cp1252":       decodeSingleByte(&windows1252),
+	}
+)
+
+// RegisterCharset registers the decoder of a charset named in the
+// Content-Type of responses, e.g. with golang.org/x/text:
+//
+//	graphql.RegisterCharset("shift_jis", func(b []byte) ([]byte, error) {
+//		return japanese.ShiftJIS.NewDecoder().Bytes(b)
+//	})
+//
+// UTF-8, UTF-16, US-ASCII, ISO-8859-1 and Windows-1252 are built in.
+// Names are case insensitive.
+func RegisterCharset(name string, decode CharsetDecoder) {
+	charsetsMu.Lock()
+	defer charsetsMu.Unlock()
+	charsets[strings.ToLower(name)] = decode
+}
+
+// toUTF8 converts body to UTF-8 according to the charset of contentType,
+// UTF-8 when none is given, and strips byte order marks. A UTF-16 byte
+// order mark takes precedence over the declared charset. Bodies of
+// unknown charsets are passed through when they are valid UTF-8, as
+// servers sending non-standard labels such as "UTF8MB4" usually mean it.
+func toUTF8(contentType string, body []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(body, []byte{0xFE, 0xFF}):
+		return decodeUTF16(binary.BigEndian)(body[2:])
+	case bytes.HasPrefix(body, []byte{0xFF, 0xFE}):
+		return decodeUTF16(binary.LittleEndian)(body[2:])
+	}
+	_, params, _ := mime.ParseMediaType(contentType)
+	charset := strings.ToLower(params["charset"])
+	if charset == "" {
+		return decodeUTF8(body)
+	}
+	charsetsMu.RLock()
+	decode, ok := charsets[charset]
+	charsetsMu.RUnlock()
+	if !ok {
+		if utf8.Valid(body) {
+			return decodeUTF8(body)
+		}
+		return nil, errors.Errorf("unsupported charset %q", charset)
+	}
+	return decode(body)
+}
+
+func decodeUTF8(body []byte) ([]byte, error) {
+	return bytes.TrimPrefix(body, []byte{0xEF, 0xBB, 0xBF}), nil
+}
+
+func decodeUTF16(order binary.ByteOrder) CharsetDecoder {
+	return func(body []byte) ([]byte, error) {
+		if len(body)%2 != 0 {
+			return nil, errors.New("odd length of UTF-16 body")
+		}
+		units := make([]uint16, len(body)/2)
+		for i := range units {
+			units[i] = order.Uint16(body[2*i:])
+		}
+		if len(units) > 0 && units[0] == 0xFEFF {
+			units = units[1:]
+		}
+		var buf bytes.Buffer
+		buf.Grow(len(units))
+		for _, r := range utf16.Decode(units) {
+			buf.WriteRune(r)
+		}
+		return buf.Bytes(), nil
+	}
+}
+
+// decodeSingleByte decodes charsets mapping each byte to a rune: bytes
+// below 0x80 are ASCII, 0x80 to 0x9F map through high when given and the
+// others to the Latin-1 code points.
+func decodeSingleByte(high *[32]rune) CharsetDecoder {
+	return func(body []byte) ([]byte, error) {
+		buf := make([]byte, 0, len(body))
+		for _, b := range body {
+			switch {
+			case b < utf8.RuneSelf:
+				buf = append(buf, b)
+			case high != nil && b < 0xA0:
+				buf = append(buf, string(high[b-0x80])...)
+			default:
+				buf = append(buf, string(rune(b))...)
+			}
+		}
+		return buf, nil
+	}
+}
+
+// windows1252 maps the bytes 0x80 to 0x9F of Windows-1252; undefined
+// ones map to the C1 controls like ISO-8859-1.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
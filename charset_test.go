@@ -0,0 +1,72 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/matryer/is"
+)
+
+func TestToUTF8(t *testing.T) {
+	is := is.New(t)
+
+	const want = `{"data":{"name":"Café €"}}`
+	utf16le := func(bom bool) []byte {
+		var buf bytes.Buffer
+		if bom {
+			buf.Write([]byte{0xFF, 0xFE})
+		}
+		for _, u := range utf16.Encode([]rune(want)) {
+			_ = binary.Write(&buf, binary.LittleEndian, u)
+		}
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		contentType string
+		body        []byte
+	}{
+		{"application/json", []byte(want)},
+		{"application/json; charset=utf-8", append([]byte{0xEF, 0xBB, 0xBF}, want...)},
+		{"application/json; charset=UTF-16LE", utf16le(false)},
+		{"application/json", utf16le(true)},
+		{"application/json; charset=windows-1252", []byte("{\"data\":{\"name\":\"Caf\xe9 \x80\"}}")},
+	}
+	for _, test := range tests {
+		got, err := toUTF8(test.contentType, test.body)
+		is.NoErr(err)
+		is.Equal(string(got), want)
+	}
+
+	// Unknown charsets pass valid UTF-8 through.
+	got, err := toUTF8("application/json; charset=utf8mb4", []byte(want))
+	is.NoErr(err)
+	is.Equal(string(got), want)
+
+	_, err = toUTF8("application/json; charset=koi8-r", []byte("{\"data\":{\"name\":\"\xf0\"}}"))
+	is.Equal(err.Error(), `unsupported charset "koi8-r"`)
+}
+
+func TestRunCharset(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=iso-8859-1")
+		_, _ = w.Write([]byte("{\"data\":{\"name\":\"Caf\xe9\"}}"))
+	}))
+	defer srv.Close()
+
+	var resp struct{ Name string }
+	is.NoErr(NewClient(srv.URL).Run(context.Background(), NewRequest("query {}"), &resp))
+	is.Equal(resp.Name, "Café")
+
+	RegisterCharset("ISO-8859-1", func(b []byte) ([]byte, error) { return []byte(`{"data":{"name":"custom"}}`), nil })
+	defer RegisterCharset("iso-8859-1", decodeSingleByte(nil))
+	is.NoErr(NewClient(srv.URL).Run(context.Background(), NewRequest("query {}"), &resp))
+	is.Equal(resp.Name, "custom")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sumup/graphql"
+)
+
+// clientFlags are the flags shared by every command talking to an
+// endpoint.
+type clientFlags struct {
+	endpoint string
+	headers  stringsFlag
+	timeout  time.Duration
+	verbose  bool
+}
+
+func (f *clientFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.endpoint, "endpoint", os.Getenv("GRAPHQL_ENDPOINT"), "GraphQL endpoint URL (default $GRAPHQL_ENDPOINT)")
+	fs.Var(&f.headers, "H", "request header as \"Key: Value\", can be repeated")
+	fs.DurationVar(&f.timeout, "timeout", 0, "timeout of each request, none by default")
+	fs.BoolVar(&f.verbose, "v", false, "log requests and responses to stderr")
+}
+
+// client creates a client for the endpoint.
+func (f *clientFlags) client(log func(string)) (*graphql.Client, error) {
+	if f.endpoint == "" {
+		return nil, fmt.Errorf("no endpoint: use -endpoint or set GRAPHQL_ENDPOINT")
+	}
+	header, err := f.header()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []graphql.ClientOption{graphql.WithHTTPClient(f.httpClient())}
+	for key := range header {
+		opts = append(opts, graphql.WithHeader(key, header.Get(key)))
+	}
+
+	client := graphql.NewClient(f.endpoint, opts...)
+	if f.verbose {
+		client.Log = log
+	}
+	return client, nil
+}
+
+// httpClient creates the http.Client sending the requests of a command,
+// so requests made outside of a graphql.Client get the same settings.
+func (f *clientFlags) httpClient() *http.Client {
+	return &http.Client{Timeout: f.timeout}
+}
+
+// header parses the -H flags. Authorization is read from
+// $GRAPHQL_AUTHORIZATION unless given with -H.
+func (f *clientFlags) header() (http.Header, error) {
+	header := make(http.Header)
+	if auth := os.Getenv("GRAPHQL_AUTHORIZATION"); auth != "" {
+		header.Set("Authorization", auth)
+	}
+	for _, h := range f.headers {
+		key, value, ok := cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header %q, expected \"Key: Value\"", h)
+		}
+		header.Set(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	return header, nil
+}
+
+// stringsFlag collects the values of a repeated flag.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/sumup/graphql/cost"
+	"github.com/sumup/graphql/introspection"
+)
+
+func runCost(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("cost", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: graphql cost [flags] [file...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Estimates the cost of operations read from files, or stdin when no file is given.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	var (
+		schemaFile  string
+		weightsFile string
+		operation   string
+		varsJSON    string
+		max         int
+	)
+	fs.StringVar(&schemaFile, "schema", "", "introspection JSON written by \"graphql schema -format json\"")
+	fs.StringVar(&weightsFile, "weights", "", "JSON object of field weights keyed by \"Type.field\" or \"field\"")
+	fs.StringVar(&operation, "operation", "", "operation to estimate in documents with several operations")
+	fs.StringVar(&varsJSON, "vars", "", "variables as a JSON object, used for list size arguments")
+	fs.IntVar(&max, "max", 0, "fail when an operation costs more than this")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := cost.DefaultConfig()
+	if schemaFile != "" {
+		schema, err := readSchema(schemaFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "graphql: %v\n", err)
+			return 2
+		}
+		cfg.Schema = schema
+	}
+	if weightsFile != "" {
+		b, err := ioutil.ReadFile(weightsFile)
+		if err == nil {
+			err = json.Unmarshal(b, &cfg.Weights)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "graphql: reading weights: %v\n", err)
+			return 2
+		}
+	}
+	variables, err := parseVars(varsJSON, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "graphql: %v\n", err)
+		return 2
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	code := 0
+	for _, file := range files {
+		query, err := readQuery(file, stdin)
+		if err == nil {
+			var c int
+			if c, err = cost.Estimate(query, operation, variables, cfg); err == nil {
+				if max > 0 && c > max {
+					fmt.Fprintf(stdout, "%s: cost %d exceeds maximum %d\n", file, c, max)
+					code = 1
+				} else {
+					fmt.Fprintf(stdout, "%s: cost %d\n", file, c)
+				}
+				continue
+			}
+		}
+		fmt.Fprintf(stderr, "%s: %v\n", file, err)
+		code = 1
+	}
+	return code
+}
+
+// readSchema reads introspection JSON, with or without the "data" key
+// of the response.
+func readSchema(file string) (*introspection.Schema, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		introspection.Response
+		Data *introspection.Response `json:"data"`
+	}
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, fmt.Errorf("reading schema: %v", err)
+	}
+	if resp.Data != nil {
+		return &resp.Data.Schema, nil
+	}
+	return &resp.Schema, nil
+}
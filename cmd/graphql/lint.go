@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/sumup/graphql/lint"
+)
+
+func runLint(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: graphql lint [dir...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Checks the documents passed to graphql.NewRequest and graphql.NewMutation in the")
+		fmt.Fprintln(stderr, "Go files below each directory, the current directory when none is given.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	dirs := fs.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+
+	code := 0
+	for _, dir := range dirs {
+		diags, err := lint.Dir(dir)
+		for _, d := range diags {
+			fmt.Fprintln(stdout, d)
+			code = 1
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "graphql: %v\n", err)
+			return 2
+		}
+	}
+	return code
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/sumup/graphql"
+	"github.com/sumup/graphql/loadtest"
+)
+
+func runLoadtest(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: graphql loadtest [flags] [file]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Fires the operation read from file, or stdin, at the endpoint and reports latencies and errors.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	var (
+		cf       clientFlags
+		varsJSON string
+		vars     stringsFlag
+		cfg      loadtest.Config
+	)
+	cf.register(fs)
+	fs.StringVar(&varsJSON, "vars", "", "variables as a JSON object")
+	fs.Var(&vars, "var", "variable as name=value, value is parsed as JSON when valid, can be repeated")
+	fs.Float64Var(&cfg.Rate, "rate", 10, "operations started per second")
+	fs.IntVar(&cfg.Concurrency, "c", 10, "maximum operations in flight")
+	fs.DurationVar(&cfg.Duration, "d", 10*time.Second, "test duration")
+	fs.IntVar(&cfg.Requests, "n", 0, "stop after this many operations")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	query, err := readQuery(fs.Arg(0), stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "graphql: %v\n", err)
+		return 1
+	}
+	variables, err := parseVars(varsJSON, vars)
+	if err != nil {
+		fmt.Fprintf(stderr, "graphql: %v\n", err)
+		return 2
+	}
+	client, err := cf.client(func(s string) { fmt.Fprintln(stderr, s) })
+	if err != nil {
+		fmt.Fprintf(stderr, "graphql: %v\n", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := loadtest.Run(ctx, client, func() graphql.Operation {
+		req := graphql.NewRequest(query)
+		for key, value := range variables {
+			req.Var(key, value)
+		}
+		return req
+	}, cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "graphql: %v\n", err)
+		return 2
+	}
+	report.Print(stdout)
+	return 0
+}
@@ -0,0 +1,59 @@
+// Command graphql executes GraphQL operations from the command line using
+// the github.com/sumup/graphql client.
+//
+//	graphql query -endpoint https://example.com/graphql -var id=1 query.graphql
+//	echo '{ viewer { id } }' | graphql query
+//	graphql schema -format sdl -o schema.graphql
+//
+// The endpoint defaults to $GRAPHQL_ENDPOINT and the Authorization header
+// to $GRAPHQL_AUTHORIZATION.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{name: "query", usage: "execute a query or mutation", run: runQuery},
+	{name: "schema", usage: "download the schema as SDL or introspection JSON", run: runSchema},
+	{name: "publish", usage: "register a persisted query manifest with a gateway", run: runPublish},
+	{name: "cost", usage: "estimate the cost of operations", run: runCost},
+	{name: "loadtest", usage: "fire an operation at a target rate and report latencies", run: runLoadtest},
+	{name: "lint", usage: "check the documents of graphql calls in Go files", run: runLint},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdin, stdout, stderr)
+		}
+	}
+	fmt.Fprintf(stderr, "graphql: unknown command %q\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: graphql <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.usage)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestQuery(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("Authorization"), "Bearer token")
+		is.Equal(r.Header.Get("X-Tenant"), "acme")
+		var body struct {
+			Query     string
+			Variables map[string]interface{}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		is.Equal(body.Query, "query ($id: ID!) { item(id: $id) { name } }\n")
+		is.Equal(body.Variables, map[string]interface{}{"id": float64(1), "name": "foo", "flag": true})
+		_, _ = io.WriteString(w, `{"data":{"item":{"name":"foo"}}}`)
+	}))
+	defer srv.Close()
+	t.Setenv("GRAPHQL_AUTHORIZATION", "Bearer token")
+
+	var stdout, stderr bytes.Buffer
+	code := run(
+		[]string{"query", "-endpoint", srv.URL, "-H", "X-Tenant: acme", "-vars", `{"flag":true}`, "-var", "id=1", "-var", "name=foo"},
+		strings.NewReader("query ($id: ID!) { item(id: $id) { name } }\n"),
+		&stdout, &stderr,
+	)
+	is.Equal(stderr.String(), "")
+	is.Equal(code, 0)
+	is.Equal(stdout.String(), "{\n  \"item\": {\n    \"name\": \"foo\"\n  }\n}\n")
+}
+
+func TestQueryError(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"errors":[{"message":"not found","path":["item"],"extensions":{"code":"NOT_FOUND"}}]}`)
+	}))
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"query", "-endpoint", srv.URL}, strings.NewReader("{ item { name } }"), &stdout, &stderr)
+	is.Equal(code, 1)
+	is.Equal(stderr.String(), "error: not found (code: not_found) at item\n")
+}
+
+func TestUnknownCommand(t *testing.T) {
+	is := is.New(t)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"nope"}, nil, &stdout, &stderr)
+	is.Equal(code, 2)
+	is.True(strings.HasPrefix(stderr.String(), "graphql: unknown command \"nope\"\n"))
+}
+
+func TestSchema(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"__schema":{
+			"queryType":{"name":"Query"},
+			"types":[{"kind":"OBJECT","name":"Query","fields":[{"name":"ping","args":[],"type":{"kind":"SCALAR","name":"String"}}]}]
+		}}}`)
+	}))
+	defer srv.Close()
+
+	output := filepath.Join(t.TempDir(), "schema.graphql")
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"schema", "-endpoint", srv.URL, "-o", output}, nil, &stdout, &stderr)
+	is.Equal(stderr.String(), "")
+	is.Equal(code, 0)
+
+	b, err := ioutil.ReadFile(output)
+	is.NoErr(err)
+	is.Equal(string(b), "type Query {\n  ping: String\n}\n")
+}
+
+const testManifest = `{
+	"format": "apollo-persisted-query-manifest",
+	"version": 1,
+	"operations": [{"id": "abc", "name": "Ping", "type": "query", "body": "query Ping { ping }"}]
+}`
+
+func TestPublishFile(t *testing.T) {
+	is := is.New(t)
+	manifest := filepath.Join(t.TempDir(), "manifest.json")
+	is.NoErr(ioutil.WriteFile(manifest, []byte(testManifest), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"publish", manifest}, nil, &stdout, &stderr)
+	is.Equal(code, 0)
+	is.Equal(stdout.String(), "{\n  \"abc\": \"query Ping { ping }\"\n}\n")
+	is.Equal(stderr.String(), "published 1 operations\n")
+}
+
+func TestPublishHasura(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("X-Hasura-Admin-Secret"), "secret")
+		b, err := ioutil.ReadAll(r.Body)
+		is.NoErr(err)
+		is.Equal(string(b), `{"args":[{"args":{"definition":{"queries":[{"name":"Ping","query":"query Ping { ping }"}]},"name":"allowed-queries"},"type":"create_query_collection"},{"args":{"collection":"allowed-queries"},"type":"add_collection_to_allowlist"}],"type":"bulk"}`)
+		_, _ = io.WriteString(w, `[{"message":"success"},{"message":"success"}]`)
+	}))
+	defer srv.Close()
+	manifest := filepath.Join(t.TempDir(), "manifest.json")
+	is.NoErr(ioutil.WriteFile(manifest, []byte(testManifest), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"publish", "-target", "hasura", "-endpoint", srv.URL, "-H", "X-Hasura-Admin-Secret: secret", manifest}, nil, &stdout, &stderr)
+	is.Equal(stderr.String(), "published 1 operations\n")
+	is.Equal(code, 0)
+}
+
+func TestPublishHasuraTimeout(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer srv.Close()
+	manifest := filepath.Join(t.TempDir(), "manifest.json")
+	is.NoErr(ioutil.WriteFile(manifest, []byte(testManifest), 0o644))
+
+	// The metadata request is sent with the client configured by the flags.
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"publish", "-target", "hasura", "-endpoint", srv.URL, "-timeout", "10ms", manifest}, nil, &stdout, &stderr)
+	is.Equal(code, 1)
+	is.True(strings.Contains(stderr.String(), "Client.Timeout exceeded"))
+}
+
+func TestCost(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	cheap := filepath.Join(dir, "cheap.graphql")
+	expensive := filepath.Join(dir, "expensive.graphql")
+	weights := filepath.Join(dir, "weights.json")
+	is.NoErr(ioutil.WriteFile(cheap, []byte(`{ merchant { id } }`), 0o644))
+	is.NoErr(ioutil.WriteFile(expensive, []byte(`{ merchants(first: 100) { id } }`), 0o644))
+	is.NoErr(ioutil.WriteFile(weights, []byte(`{"Query.merchant": 2}`), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"cost", "-weights", weights, "-max", "50", cheap, expensive}, nil, &stdout, &stderr)
+	is.Equal(stderr.String(), "")
+	is.Equal(code, 1)
+	is.Equal(stdout.String(), cheap+": cost 2\n"+expensive+": cost 100 exceeds maximum 50\n")
+}
+
+func TestLoadtest(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"ping":"pong"}}`)
+	}))
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"loadtest", "-endpoint", srv.URL, "-rate", "1000", "-n", "5"}, strings.NewReader("{ ping }"), &stdout, &stderr)
+	is.Equal(stderr.String(), "")
+	is.Equal(code, 0)
+	is.True(strings.Contains(stdout.String(), "errors:   0\n"))
+}
+
+func TestLint(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	src := "package example\n\nimport \"github.com/sumup/graphql\"\n\nvar q = graphql.NewRequest(`{ merchant(id: $id) { id } }`)\n"
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "example.go"), []byte(src), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"lint", dir}, nil, &stdout, &stderr)
+	is.Equal(stderr.String(), "")
+	is.Equal(code, 1)
+	is.Equal(stdout.String(), filepath.Join(dir, "example.go")+":5:31: graphql: variable $id is not declared\n")
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/sumup/graphql/persisted"
+)
+
+func runPublish(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("publish", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: graphql publish [flags] manifest.json")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Registers the operations of a persisted query manifest with a gateway.")
+		fmt.Fprintln(stderr, "Targets:")
+		fmt.Fprintln(stderr, "  file    write an {\"id\": \"body\"} map, as read by Relay style servers")
+		fmt.Fprintln(stderr, "  hasura  add the operations to a Hasura query collection and allow-list it,")
+		fmt.Fprintln(stderr, "          -endpoint is the metadata API, e.g. http://localhost:8080/v1/metadata")
+		fmt.Fprintln(stderr, "Apollo GraphOS lists are published from the manifest itself with rover.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	var (
+		cf         clientFlags
+		target     string
+		output     string
+		collection string
+		replace    bool
+	)
+	cf.register(fs)
+	fs.StringVar(&target, "target", "file", "where to publish: file or hasura")
+	fs.StringVar(&output, "o", "", "output file for the file target, stdout by default")
+	fs.StringVar(&collection, "collection", "allowed-queries", "Hasura query collection name")
+	fs.BoolVar(&replace, "replace", false, "drop the Hasura query collection before creating it")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	manifest, err := persisted.LoadManifest(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "graphql: %v\n", err)
+		return 1
+	}
+
+	switch target {
+	case "file":
+		err = publishFile(manifest, output, stdout)
+	case "hasura":
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		err = publishHasura(ctx, &cf, manifest, collection, replace, stderr)
+	default:
+		fmt.Fprintf(stderr, "graphql: unknown target %q\n", target)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "graphql: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stderr, "published %d operations\n", len(manifest.Operations))
+	return 0
+}
+
+func publishFile(manifest *persisted.Manifest, output string, stdout io.Writer) error {
+	documents := make(map[string]string, len(manifest.Operations))
+	for _, op := range manifest.Operations {
+		documents[op.ID] = op.Body
+	}
+	b, err := json.MarshalIndent(documents, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if output == "" {
+		_, err = stdout.Write(b)
+		return err
+	}
+	return ioutil.WriteFile(output, b, 0o644)
+}
+
+// publishHasura creates a query collection holding the operations of
+// manifest and adds it to the allow-list in a single bulk metadata
+// request. The request fails when the collection exists, unless replace
+// drops it first.
+func publishHasura(ctx context.Context, cf *clientFlags, manifest *persisted.Manifest, collection string, replace bool, stderr io.Writer) error {
+	if cf.endpoint == "" {
+		return fmt.Errorf("no endpoint: use -endpoint or set GRAPHQL_ENDPOINT")
+	}
+	header, err := cf.header()
+	if err != nil {
+		return err
+	}
+
+	type query struct {
+		Name  string `json:"name"`
+		Query string `json:"query"`
+	}
+	queries := make([]query, len(manifest.Operations))
+	for i, op := range manifest.Operations {
+		queries[i] = query{Name: op.Name, Query: op.Body}
+	}
+
+	var steps []interface{}
+	if replace {
+		steps = append(steps, map[string]interface{}{
+			"type": "drop_query_collection",
+			"args": map[string]interface{}{"collection": collection, "cascade": true},
+		})
+	}
+	steps = append(steps,
+		map[string]interface{}{
+			"type": "create_query_collection",
+			"args": map[string]interface{}{
+				"name":       collection,
+				"definition": map[string]interface{}{"queries": queries},
+			},
+		},
+		map[string]interface{}{
+			"type": "add_collection_to_allowlist",
+			"args": map[string]interface{}{"collection": collection},
+		},
+	)
+
+	body, err := json.Marshal(map[string]interface{}{"type": "bulk", "args": steps})
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, cf.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header = header
+	r.Header.Set("Content-Type", "application/json")
+
+	if cf.verbose {
+		fmt.Fprintf(stderr, ">> %s\n", body)
+	}
+	res, err := cf.httpClient().Do(r)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if cf.verbose {
+		fmt.Fprintf(stderr, "<< %s\n", res.Status)
+	}
+	if res.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("hasura metadata request failed with status %s: %s", res.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
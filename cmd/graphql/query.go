@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+
+	"github.com/sumup/graphql"
+)
+
+func runQuery(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: graphql query [flags] [file]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Reads the operation from file, or stdin when no file is given.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	var (
+		cf       clientFlags
+		varsJSON string
+		vars     stringsFlag
+	)
+	cf.register(fs)
+	fs.StringVar(&varsJSON, "vars", "", "variables as a JSON object")
+	fs.Var(&vars, "var", "variable as name=value, value is parsed as JSON when valid, can be repeated")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	query, err := readQuery(fs.Arg(0), stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "graphql: %v\n", err)
+		return 1
+	}
+	variables, err := parseVars(varsJSON, vars)
+	if err != nil {
+		fmt.Fprintf(stderr, "graphql: %v\n", err)
+		return 2
+	}
+	client, err := cf.client(func(s string) { fmt.Fprintln(stderr, s) })
+	if err != nil {
+		fmt.Fprintf(stderr, "graphql: %v\n", err)
+		return 2
+	}
+
+	req := graphql.NewRequest(query)
+	for key, value := range variables {
+		req.Var(key, value)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var data json.RawMessage
+	if err := client.Run(ctx, req, &data); err != nil {
+		printError(stderr, err)
+		return 1
+	}
+	return printJSON(stdout, stderr, data)
+}
+
+func readQuery(file string, stdin io.Reader) (string, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if file == "" || file == "-" {
+		b, err = ioutil.ReadAll(stdin)
+	} else {
+		b, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return "", fmt.Errorf("empty query")
+	}
+	return string(b), nil
+}
+
+// parseVars merges the -vars object with the -var flags, which take
+// precedence.
+func parseVars(varsJSON string, vars []string) (map[string]interface{}, error) {
+	variables := make(map[string]interface{})
+	if varsJSON != "" {
+		if err := json.Unmarshal([]byte(varsJSON), &variables); err != nil {
+			return nil, fmt.Errorf("invalid -vars: %v", err)
+		}
+	}
+	for _, v := range vars {
+		key, raw, ok := cut(v, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid -var %q, expected name=value", v)
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			value = raw
+		}
+		variables[key] = value
+	}
+	return variables, nil
+}
+
+func printJSON(stdout, stderr io.Writer, data []byte) int {
+	var out bytes.Buffer
+	if len(data) == 0 {
+		data = []byte("null")
+	}
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		fmt.Fprintf(stderr, "graphql: %v\n", err)
+		return 1
+	}
+	out.WriteByte('\n')
+	_, _ = out.WriteTo(stdout)
+	return 0
+}
+
+func printError(w io.Writer, err graphql.Error) {
+	for _, detail := range err.Details() {
+		fmt.Fprintf(w, "error: %s", detail.Message)
+		if detail.Code != "" {
+			fmt.Fprintf(w, " (code: %s)", detail.Code)
+		}
+		if detail.Domain != "" {
+			fmt.Fprintf(w, " at %s", detail.Domain)
+		}
+		fmt.Fprintln(w)
+	}
+}
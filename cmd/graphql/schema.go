@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+
+	"github.com/sumup/graphql/introspection"
+)
+
+func runSchema(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: graphql schema [flags]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Introspects the endpoint and writes its schema as SDL or introspection JSON.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	var (
+		cf     clientFlags
+		format string
+		output string
+	)
+	cf.register(fs)
+	fs.StringVar(&format, "format", "sdl", "output format: sdl or json")
+	fs.StringVar(&output, "o", "", "write the schema to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if format != "sdl" && format != "json" {
+		fmt.Fprintf(stderr, "graphql: unknown format %q\n", format)
+		return 2
+	}
+	client, err := cf.client(func(s string) { fmt.Fprintln(stderr, s) })
+	if err != nil {
+		fmt.Fprintf(stderr, "graphql: %v\n", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	resp, gqlErr := introspection.Fetch(ctx, client)
+	if gqlErr != nil {
+		printError(stderr, gqlErr)
+		return 1
+	}
+
+	var out bytes.Buffer
+	if format == "json" {
+		enc := json.NewEncoder(&out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(resp); err != nil {
+			fmt.Fprintf(stderr, "graphql: %v\n", err)
+			return 1
+		}
+	} else {
+		out.WriteString(resp.Schema.SDL())
+	}
+
+	if output == "" {
+		_, _ = out.WriteTo(stdout)
+		return 0
+	}
+	if err := ioutil.WriteFile(output, out.Bytes(), 0o644); err != nil {
+		fmt.Fprintf(stderr, "graphql: %v\n", err)
+		return 1
+	}
+	return 0
+}
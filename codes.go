@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// Error codes commonly reported in the extensions of an error, as Apollo
+// Server and gqlgen do. Codes are compared case-insensitively.
+const (
+	CodeUnauthenticated     = "UNAUTHENTICATED"
+	CodeForbidden           = "FORBIDDEN"
+	CodeBadUserInput        = "BAD_USER_INPUT"
+	CodeNotFound            = "NOT_FOUND"
+	CodeInternal            = "INTERNAL"
+	CodeInternalServerError = "INTERNAL_SERVER_ERROR"
+)
+
+// IsUnauthenticated reports whether err was caused by missing or invalid
+// credentials: an UNAUTHENTICATED error or a 401 status.
+func IsUnauthenticated(err error) bool {
+	return hasErrorCode(err, CodeUnauthenticated) || hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err was caused by the caller lacking the
+// permission: a FORBIDDEN error or a 403 status.
+func IsForbidden(err error) bool {
+	return hasErrorCode(err, CodeForbidden) || hasStatus(err, http.StatusForbidden)
+}
+
+// IsBadUserInput reports whether err was caused by invalid arguments: a
+// BAD_USER_INPUT error.
+func IsBadUserInput(err error) bool {
+	return hasErrorCode(err, CodeBadUserInput)
+}
+
+// IsNotFound reports whether err was caused by a missing object: a
+// NOT_FOUND error or a 404 status.
+func IsNotFound(err error) bool {
+	return hasErrorCode(err, CodeNotFound) || hasStatus(err, http.StatusNotFound)
+}
+
+// IsInternal reports whether err was caused by a failure of the server:
+// an INTERNAL or INTERNAL_SERVER_ERROR error or a 500 status.
+func IsInternal(err error) bool {
+	return hasErrorCode(err, CodeInternal, CodeInternalServerError) || hasStatus(err, http.StatusInternalServerError)
+}
+
+// IsTransient reports whether err was caused by a transient failure,
+// after which sending the operation again or resubscribing may succeed:
+// network errors and timeouts, an interrupted response or event stream,
+// ErrConcurrencyLimit, rate limiting and server errors. Other errors are
+// terminal, e.g. GraphQL errors, since the server processed the
+// operation, client errors such as a 401 status, variables that cannot be
+// encoded or responses that cannot be decoded.
+func IsTransient(err error) bool {
+	var rerr *RequestError
+	if errors.As(err, &rerr) {
+		if rerr.Response() == nil {
+			return false
+		}
+		status := rerr.Response().StatusCode
+		return status >= 500 || status == http.StatusTooManyRequests || status == http.StatusRequestTimeout
+	}
+	var (
+		urlErr *url.Error
+		netErr net.Error
+	)
+	return errors.As(err, &urlErr) || errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrConcurrencyLimit)
+}
+
+// hasStatus reports whether err is a *RequestError with the given status.
+func hasStatus(err error, status int) bool {
+	var rerr *RequestError
+	return errors.As(err, &rerr) && rerr.Response() != nil && rerr.Response().StatusCode == status
+}
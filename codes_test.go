@@ -0,0 +1,55 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+)
+
+func TestErrorCodePredicates(t *testing.T) {
+	is := is.New(t)
+
+	var status int
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	defer srv.Close()
+
+	run := func(s int, b string) error {
+		status, body = s, b
+		return NewClient(srv.URL).Run(context.Background(), NewRequest("query {}"), nil)
+	}
+
+	err := run(http.StatusOK, `{"errors":[{"message":"who are you","extensions":{"code":"UNAUTHENTICATED"}}]}`)
+	is.True(IsUnauthenticated(err))
+	is.True(!IsForbidden(err))
+	is.True(IsUnauthenticated(errors.Wrap(err, "loading merchant")))
+
+	err = run(http.StatusOK, `{"errors":[{"message":"no such merchant","extensions":{"code":"NOT_FOUND"}}]}`)
+	is.True(IsNotFound(err))
+
+	err = run(http.StatusOK, `{"errors":[{"message":"bad amount","extensions":{"code":"BAD_USER_INPUT"}}]}`)
+	is.True(IsBadUserInput(err))
+
+	err = run(http.StatusOK, `{"errors":[{"message":"oops","extensions":{"code":"INTERNAL_SERVER_ERROR"}}]}`)
+	is.True(IsInternal(err))
+
+	is.True(IsForbidden(run(http.StatusForbidden, "")))
+	is.True(IsUnauthenticated(run(http.StatusUnauthorized, "")))
+	is.True(!IsNotFound(run(http.StatusBadGateway, "")))
+	is.True(!IsInternal(nil))
+
+	is.True(IsTransient(run(http.StatusBadGateway, "")))
+	is.True(IsTransient(run(http.StatusTooManyRequests, "")))
+	is.True(!IsTransient(run(http.StatusUnauthorized, "")))
+	is.True(!IsTransient(run(http.StatusOK, `{"errors":[{"message":"oops"}]}`)))
+	is.True(IsTransient(NewExecutionError(errors.Wrap(io.ErrUnexpectedEOF, "reading event stream"))))
+	is.True(!IsTransient(NewExecutionError(ErrSubscriptionOverflow)))
+}
@@ -0,0 +1,123 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WithCompletenessCheck compares every successful response with the
+// struct it is decoded into and calls report with the paths of the
+// fields of the struct that were missing or null in the data, e.g.
+// "merchant.address.city", when there are any. It helps detecting
+// fields a server silently failed to resolve and schema mismatches;
+// the call itself still succeeds.
+//
+//	NewClient(endpoint, WithCompletenessCheck(func(op Operation, missing []string) {
+//		log.Printf("incomplete response: %v", missing)
+//	}))
+func WithCompletenessCheck(report func(op Operation, missing []string)) ClientOption {
+	return func(client *Client) {
+		client.reportMissing = report
+	}
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// checkCompleteness reports the fields of resp missing from the data of
+// body.
+func (c *Client) checkCompleteness(op Operation, body []byte, resp interface{}) {
+	var gr struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &gr); err != nil {
+		return
+	}
+	if missing := missingFields(nil, "", reflect.TypeOf(resp), gr.Data); len(missing) > 0 {
+		c.reportMissing(op, missing)
+	}
+}
+
+// missingFields appends the paths of the fields of t that are missing or
+// null in raw. Types decoding themselves, maps and interfaces are taken
+// as they are.
+func missingFields(missing []string, path string, t reflect.Type, raw json.RawMessage) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	ptr := reflect.PtrTo(t)
+	if ptr.Implements(jsonUnmarshalerType) || ptr.Implements(textUnmarshalerType) {
+		return missing
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		var obj map[string]json.RawMessage
+		if json.Unmarshal(raw, &obj) != nil {
+			return missing
+		}
+		return missingStructFields(missing, path, t, obj)
+	case reflect.Slice, reflect.Array:
+		var elems []json.RawMessage
+		if json.Unmarshal(raw, &elems) != nil {
+			return missing
+		}
+		for i, elem := range elems {
+			missing = missingOrNull(missing, joinPath(path, strconv.Itoa(i)), t.Elem(), elem)
+		}
+	}
+	return missing
+}
+
+func missingStructFields(missing []string, path string, t reflect.Type, obj map[string]json.RawMessage) []string {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, ok := jsonField(field)
+		if !ok {
+			continue
+		}
+		if ft := field.Type; field.Anonymous && field.Tag.Get("json") == "" {
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				missing = missingStructFields(missing, path, ft, obj)
+				continue
+			}
+		}
+		missing = missingOrNull(missing, joinPath(path, name), field.Type, lookupFold(obj, name))
+	}
+	return missing
+}
+
+// missingOrNull appends path when raw is missing or null, or the missing
+// fields within it.
+func missingOrNull(missing []string, path string, t reflect.Type, raw json.RawMessage) []string {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return append(missing, path)
+	}
+	return missingFields(missing, path, t, raw)
+}
+
+// lookupFold gets the value of key in obj, matching it case-insensitively
+// like encoding/json when there is no exact match.
+func lookupFold(obj map[string]json.RawMessage, key string) json.RawMessage {
+	if raw, ok := obj[key]; ok {
+		return raw
+	}
+	for k, raw := range obj {
+		if strings.EqualFold(k, key) {
+			return raw
+		}
+	}
+	return nil
+}
+
+func joinPath(path, elem string) string {
+	if path == "" {
+		return elem
+	}
+	return path + "." + elem
+}
@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithCompletenessCheck(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"merchant":{"ID":"m1","address":{"city":null},"readers":[{"serial":"a"},{}],"created":"2020-01-01T00:00:00Z"}}}`)
+	}))
+	defer srv.Close()
+
+	type Named struct {
+		Name string `json:"name"`
+	}
+	var resp struct {
+		Merchant struct {
+			Named
+			ID      ID
+			Created Time `json:"created"`
+			Address *struct {
+				City    string `json:"city"`
+				Country string `json:"country"`
+			} `json:"address"`
+			Readers []struct {
+				Serial string `json:"serial"`
+			} `json:"readers"`
+			Notes map[string]string `json:"-"`
+		} `json:"merchant"`
+	}
+
+	var reported []string
+	client := NewClient(srv.URL, WithCompletenessCheck(func(op Operation, missing []string) {
+		reported = missing
+	}))
+	is.NoErr(client.Run(context.Background(), NewRequest("query { merchant { id } }"), &resp))
+	is.Equal(resp.Merchant.ID, ID("m1"))
+	is.Equal(reported, []string{
+		"merchant.name",
+		"merchant.address.city",
+		"merchant.address.country",
+		"merchant.readers.1.serial",
+	})
+}
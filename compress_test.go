@@ -0,0 +1,62 @@
+package graphql
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithRequestCompression(t *testing.T) {
+	is := is.New(t)
+
+	var (
+		encoding string
+		payload  struct{ Variables struct{ Blob string } }
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		var body io.Reader = r.Body
+		switch encoding {
+		case "gzip":
+			zr, err := gzip.NewReader(r.Body)
+			is.NoErr(err)
+			body = zr
+		case "deflate":
+			zr, err := zlib.NewReader(r.Body)
+			is.NoErr(err)
+			body = zr
+		}
+		is.NoErr(json.NewDecoder(body).Decode(&payload))
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	run := func(client *Client, blob string) {
+		req := NewMutation("mutation ($blob: String!) { upload(blob: $blob) { successful } }")
+		req.Var("blob", blob)
+		is.NoErr(client.Run(context.Background(), req, nil))
+		is.Equal(payload.Variables.Blob, blob)
+	}
+
+	large := strings.Repeat("receipt line ", 200)
+	client := NewClient(srv.URL, WithRequestCompression(CompressionGzip, 1024))
+	run(client, large)
+	is.Equal(encoding, "gzip")
+	run(client, "small")
+	is.Equal(encoding, "")
+	is.True(client.Stats().BytesSent < int64(len(large)))
+
+	run(NewClient(srv.URL, WithRequestCompression(CompressionDeflate, 0)), large)
+	is.Equal(encoding, "deflate")
+
+	_, err := NewClientE(srv.URL, WithRequestCompression("br", 0))
+	is.Equal(err.Error(), `graphql: unsupported request compression "br"`)
+}
@@ -0,0 +1,317 @@
+package graphql
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// Config declares the settings of a client, so they can be kept in
+	// JSON or YAML configuration files. Settings that are functions, such
+	// as loggers and error translators, are passed as options to
+	// NewClientFromConfig.
+	Config struct {
+		// Endpoint is the URL of the GraphQL API.
+		Endpoint string `json:"endpoint" yaml:"endpoint"`
+		// Timeout bounds every call, see WithTimeout.
+		Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+		// Multipart sends requests as multipart/form-data, see
+		// UseMultipartForm.
+		Multipart bool `json:"multipart,omitempty" yaml:"multipart,omitempty"`
+		// UseGET sends queries as GET requests, see UseGET.
+		UseGET bool `json:"useGet,omitempty" yaml:"useGet,omitempty"`
+		// GraphQLBody posts bare documents, see UseGraphQLBody.
+		GraphQLBody bool `json:"graphqlBody,omitempty" yaml:"graphqlBody,omitempty"`
+		// OperationName sends the operationName field, see
+		// WithOperationName.
+		OperationName bool `json:"operationName,omitempty" yaml:"operationName,omitempty"`
+		// Compression compresses request bodies of at least
+		// CompressionMinSize bytes with "gzip" or "deflate", see
+		// WithRequestCompression.
+		Compression        string `json:"compression,omitempty" yaml:"compression,omitempty"`
+		CompressionMinSize int    `json:"compressionMinSize,omitempty" yaml:"compressionMinSize,omitempty"`
+		// Decompression lists the encodings of responses the client
+		// accepts and decodes, see WithResponseDecompression.
+		Decompression []string `json:"decompression,omitempty" yaml:"decompression,omitempty"`
+		// CloseRequestBody closes connections after each request, see
+		// ImmediatelyCloseReqBody.
+		CloseRequestBody bool `json:"closeRequestBody,omitempty" yaml:"closeRequestBody,omitempty"`
+		// TimeFormat encodes the times of variables: "rfc3339nano",
+		// "rfc3339", "unix" or "unixmilli", see WithTimeFormat.
+		TimeFormat string `json:"timeFormat,omitempty" yaml:"timeFormat,omitempty"`
+		// NumericStrings sends arbitrary-precision numbers as strings, see
+		// WithNumericStrings.
+		NumericStrings bool `json:"numericStrings,omitempty" yaml:"numericStrings,omitempty"`
+		// UUIDArrays sends [16]byte variables as UUID strings, see
+		// WithUUIDArrays.
+		UUIDArrays bool `json:"uuidArrays,omitempty" yaml:"uuidArrays,omitempty"`
+		// MoneyFormat sends Money variables as "object",
+		// "decimalString" or "minorUnits", see WithMoneyFormat.
+		MoneyFormat string `json:"moneyFormat,omitempty" yaml:"moneyFormat,omitempty"`
+		// GeoFormat sends GeoPoint variables as "object", "array" or
+		// "geojson", see WithGeoFormat.
+		GeoFormat string `json:"geoFormat,omitempty" yaml:"geoFormat,omitempty"`
+		// MaxConcurrency limits the calls in flight, see
+		// WithMaxConcurrency. FailFast fails the calls past the limit
+		// rather than making them wait.
+		MaxConcurrency int  `json:"maxConcurrency,omitempty" yaml:"maxConcurrency,omitempty"`
+		FailFast       bool `json:"failFast,omitempty" yaml:"failFast,omitempty"`
+		// StrictContentType rejects responses that are not JSON by their
+		// Content-Type, see WithStrictContentType.
+		StrictContentType bool `json:"strictContentType,omitempty" yaml:"strictContentType,omitempty"`
+		// NullDataAsError reports responses with null data and no errors
+		// as errors, see WithNullData.
+		NullDataAsError bool `json:"nullDataAsError,omitempty" yaml:"nullDataAsError,omitempty"`
+		// Accept is the Accept header of requests, see WithAccept.
+		Accept string `json:"accept,omitempty" yaml:"accept,omitempty"`
+		// QueryParams are added to the URL of every request, see
+		// WithQueryParams.
+		QueryParams map[string]string `json:"queryParams,omitempty" yaml:"queryParams,omitempty"`
+		// Headers are sent with every request, see WithHeader.
+		Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+		// ClientName and ClientVersion identify the client, see
+		// WithClientAwareness.
+		ClientName    string `json:"clientName,omitempty" yaml:"clientName,omitempty"`
+		ClientVersion string `json:"clientVersion,omitempty" yaml:"clientVersion,omitempty"`
+		// LogCategories names the categories logged: "request",
+		// "response", "body" and "timing". All are logged when empty.
+		LogCategories []string `json:"logCategories,omitempty" yaml:"logCategories,omitempty"`
+		// LogFormat is "text", "json" or "logfmt", text when empty.
+		LogFormat string `json:"logFormat,omitempty" yaml:"logFormat,omitempty"`
+		// RedactedHeaders are masked in logs in addition to the default
+		// credential headers, see WithRedactedHeaders.
+		RedactedHeaders []string `json:"redactedHeaders,omitempty" yaml:"redactedHeaders,omitempty"`
+		// DisableHeaderRedaction logs headers unmasked, see
+		// WithoutHeaderRedaction.
+		DisableHeaderRedaction bool `json:"disableHeaderRedaction,omitempty" yaml:"disableHeaderRedaction,omitempty"`
+		// TLS configures the connection when any field is set.
+		TLS TLSConfig `json:"tls,omitempty" yaml:"tls,omitempty"`
+	}
+
+	// TLSConfig declares the TLS settings of a client.
+	TLSConfig struct {
+		// CAFile is a PEM file of the certificate authorities to trust
+		// instead of the system ones.
+		CAFile string `json:"caFile,omitempty" yaml:"caFile,omitempty"`
+		// CertFile and KeyFile are the PEM client certificate and key.
+		CertFile string `json:"certFile,omitempty" yaml:"certFile,omitempty"`
+		KeyFile  string `json:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+		// ServerName is the name verified in the server certificate.
+		ServerName string `json:"serverName,omitempty" yaml:"serverName,omitempty"`
+		// InsecureSkipVerify disables verification, for local testing
+		// only.
+		InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+	}
+
+	// Duration is a time.Duration written as in time.ParseDuration, e.g.
+	// "10s", in configuration files.
+	Duration time.Duration
+)
+
+// NewClientFromConfig creates a client from cfg. opts are applied after
+// the configuration and take precedence. The client is validated like
+// NewClientE.
+func NewClientFromConfig(cfg Config, opts ...ClientOption) (*Client, error) {
+	cfgOpts, err := cfg.options()
+	if err != nil {
+		return nil, errors.Wrap(err, "graphql")
+	}
+	return NewClientE(cfg.Endpoint, append(cfgOpts, opts...)...)
+}
+
+// options translates the configuration to client options.
+func (cfg Config) options() ([]ClientOption, error) {
+	var opts []ClientOption
+	if cfg.Timeout != 0 {
+		opts = append(opts, WithTimeout(time.Duration(cfg.Timeout)))
+	}
+	if cfg.Multipart {
+		opts = append(opts, UseMultipartForm())
+	}
+	if cfg.UseGET {
+		opts = append(opts, UseGET())
+	}
+	if cfg.GraphQLBody {
+		opts = append(opts, UseGraphQLBody())
+	}
+	if cfg.OperationName {
+		opts = append(opts, WithOperationName())
+	}
+	if cfg.Compression != "" {
+		opts = append(opts, WithRequestCompression(cfg.Compression, cfg.CompressionMinSize))
+	}
+	if len(cfg.Decompression) > 0 {
+		opts = append(opts, WithResponseDecompression(cfg.Decompression...))
+	}
+	if cfg.CloseRequestBody {
+		opts = append(opts, ImmediatelyCloseReqBody())
+	}
+	if cfg.TimeFormat != "" {
+		format, ok := timeFormatNames[cfg.TimeFormat]
+		if !ok {
+			return nil, errors.Errorf("unknown time format %q", cfg.TimeFormat)
+		}
+		opts = append(opts, WithTimeFormat(format))
+	}
+	if cfg.NumericStrings {
+		opts = append(opts, WithNumericStrings())
+	}
+	if cfg.UUIDArrays {
+		opts = append(opts, WithUUIDArrays())
+	}
+	if cfg.MoneyFormat != "" {
+		format, ok := moneyFormatNames[cfg.MoneyFormat]
+		if !ok {
+			return nil, errors.Errorf("unknown money format %q", cfg.MoneyFormat)
+		}
+		opts = append(opts, WithMoneyFormat(format))
+	}
+	if cfg.GeoFormat != "" {
+		format, ok := geoFormatNames[cfg.GeoFormat]
+		if !ok {
+			return nil, errors.Errorf("unknown geo format %q", cfg.GeoFormat)
+		}
+		opts = append(opts, WithGeoFormat(format))
+	}
+	if cfg.MaxConcurrency > 0 {
+		opts = append(opts, WithMaxConcurrency(cfg.MaxConcurrency))
+	}
+	if cfg.FailFast {
+		opts = append(opts, WithLimitPolicy(LimitFailFast))
+	}
+	if cfg.StrictContentType {
+		opts = append(opts, WithStrictContentType())
+	}
+	if cfg.NullDataAsError {
+		opts = append(opts, WithNullData(NullDataError))
+	}
+	if cfg.Accept != "" {
+		opts = append(opts, WithAccept(cfg.Accept))
+	}
+	if len(cfg.QueryParams) > 0 {
+		params := make(url.Values, len(cfg.QueryParams))
+		for key, value := range cfg.QueryParams {
+			params.Set(key, value)
+		}
+		opts = append(opts, WithQueryParams(params))
+	}
+	for key, value := range cfg.Headers {
+		opts = append(opts, WithHeader(key, value))
+	}
+	if cfg.ClientName != "" {
+		opts = append(opts, WithClientAwareness(cfg.ClientName, cfg.ClientVersion))
+	}
+	if len(cfg.LogCategories) > 0 {
+		var categories LogCategory
+		for _, name := range cfg.LogCategories {
+			category, ok := logCategoryNames[name]
+			if !ok {
+				return nil, errors.Errorf("unknown log category %q", name)
+			}
+			categories |= category
+		}
+		opts = append(opts, WithLogCategories(categories))
+	}
+	switch cfg.LogFormat {
+	case "", "text":
+	case "json":
+		opts = append(opts, WithLogFormatter(JSONFormatter{}))
+	case "logfmt":
+		opts = append(opts, WithLogFormatter(LogfmtFormatter{}))
+	default:
+		return nil, errors.Errorf("unknown log format %q", cfg.LogFormat)
+	}
+	if len(cfg.RedactedHeaders) > 0 {
+		opts = append(opts, WithRedactedHeaders(cfg.RedactedHeaders...))
+	}
+	if cfg.DisableHeaderRedaction {
+		opts = append(opts, WithoutHeaderRedaction())
+	}
+	tlsConfig, err := cfg.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		opts = append(opts, WithHTTPClient(&http.Client{Transport: transport}))
+	}
+	return opts, nil
+}
+
+var logCategoryNames = map[string]LogCategory{
+	"request":  LogRequest,
+	"response": LogResponse,
+	"body":     LogBody,
+	"timing":   LogTiming,
+}
+
+var timeFormatNames = map[string]TimeFormat{
+	"rfc3339nano": TimeRFC3339Nano,
+	"rfc3339":     TimeRFC3339,
+	"unix":        TimeUnix,
+	"unixmilli":   TimeUnixMilli,
+}
+
+var moneyFormatNames = map[string]MoneyFormat{
+	"object":        MoneyObject,
+	"decimalString": MoneyDecimalString,
+	"minorUnits":    MoneyMinorUnits,
+}
+
+var geoFormatNames = map[string]GeoFormat{
+	"object":  GeoObject,
+	"array":   GeoArray,
+	"geojson": GeoJSONPoint,
+}
+
+// build creates the TLS configuration, or returns nil when no field is
+// set.
+func (t TLSConfig) build() (*tls.Config, error) {
+	if t == (TLSConfig{}) {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading CA file")
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates found in %s", t.CAFile)
+		}
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading client certificate")
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// MarshalText formats the duration, e.g. "1m30s".
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText parses a duration such as "10s".
+func (d *Duration) UnmarshalText(b []byte) error {
+	parsed, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
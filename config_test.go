@@ -0,0 +1,55 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestNewClientFromConfig(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("X-Tenant"), "acme")
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	var cfg Config
+	is.NoErr(json.Unmarshal([]byte(`{
+		"endpoint": "`+srv.URL+`",
+		"timeout": "2s",
+		"headers": {"X-Tenant": "acme"},
+		"logCategories": ["response"],
+		"logFormat": "json"
+	}`), &cfg))
+	is.Equal(time.Duration(cfg.Timeout), 2*time.Second)
+
+	var lines []string
+	client, err := NewClientFromConfig(cfg, WithLogger(func(s string) { lines = append(lines, s) }))
+	is.NoErr(err)
+	is.NoErr(client.Run(context.Background(), NewRequest("query {}"), nil))
+	is.Equal(lines[0], `{"category":"response","msg":"<< 200 OK","status":200}`)
+
+	b, err := json.Marshal(Config{Endpoint: "https://example.com", Timeout: Duration(90 * time.Second)})
+	is.NoErr(err)
+	is.True(strings.Contains(string(b), `"timeout":"1m30s"`))
+}
+
+func TestNewClientFromConfigErrors(t *testing.T) {
+	is := is.New(t)
+
+	_, err := NewClientFromConfig(Config{Endpoint: "https://example.com", LogCategories: []string{"everything"}})
+	is.Equal(err.Error(), `graphql: unknown log category "everything"`)
+	_, err = NewClientFromConfig(Config{Endpoint: "https://example.com", TLS: TLSConfig{CAFile: "missing.pem"}})
+	is.True(strings.HasPrefix(err.Error(), "graphql: reading CA file: open missing.pem"))
+	_, err = NewClientFromConfig(Config{})
+	is.Equal(err.Error(), `graphql: endpoint "" must use http or https`)
+}
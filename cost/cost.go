@@ -0,0 +1,271 @@
+// Package cost estimates how expensive an operation is to execute, so
+// overly expensive queries can be caught in CI before a gateway rejects
+// them.
+//
+// Every field costs its weight, and the fields of lists are counted once
+// per requested item:
+//
+//	cost(field) = size * (weight(field) + cost(selections))
+//
+// where size is the value of a list size argument such as first, the
+// configured default for other lists and 1 for everything else.
+// Introspection does not expose the directives applied to fields, so
+// weights that a schema declares with @cost directives have to be copied
+// into Config.Weights.
+package cost
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/sumup/graphql/introspection"
+	"github.com/sumup/graphql/parser"
+)
+
+// Config configures the estimation.
+type Config struct {
+	// Schema is used to resolve field types. Without it, fields with
+	// selections count as objects, all other fields as scalars, and only
+	// fields with a list size argument count as lists.
+	Schema *introspection.Schema
+
+	// Weights overrides the weight of fields, keyed by "Type.field" or by
+	// the field name alone for every type.
+	Weights map[string]int
+
+	// ObjectCost and ScalarCost are the default weights of fields
+	// returning composite and leaf types.
+	ObjectCost int
+	ScalarCost int
+
+	// ListSizeArguments are the arguments limiting the size of a list.
+	ListSizeArguments []string
+
+	// DefaultListSize is assumed for lists without a size argument.
+	DefaultListSize int
+}
+
+// DefaultConfig returns the configuration used when none is given:
+// objects cost 1, scalars 0 and unbounded lists are assumed to hold 10
+// items.
+func DefaultConfig() Config {
+	return Config{
+		ObjectCost:        1,
+		ScalarCost:        0,
+		ListSizeArguments: []string{"first", "last", "limit", "pageSize"},
+		DefaultListSize:   10,
+	}
+}
+
+type estimator struct {
+	cfg       Config
+	doc       *parser.Document
+	variables map[string]interface{}
+	visiting  map[string]bool
+}
+
+// Estimate returns the cost of the operation named operationName in
+// document, or of its only operation when the name is empty.
+func Estimate(document, operationName string, variables map[string]interface{}, cfg Config) (int, error) {
+	doc, err := parser.Parse(document)
+	if err != nil {
+		return 0, err
+	}
+	op, err := doc.Operation(operationName)
+	if err != nil {
+		return 0, err
+	}
+
+	e := &estimator{
+		cfg:       cfg,
+		doc:       doc,
+		variables: variables,
+		visiting:  make(map[string]bool),
+	}
+	cost, err := e.selectionSet(op.SelectionSet, e.rootType(op.Type))
+	if err != nil {
+		return 0, err
+	}
+	if cost > math.MaxInt32 {
+		return math.MaxInt32, nil
+	}
+	return int(cost), nil
+}
+
+func (e *estimator) rootType(opType string) string {
+	if s := e.cfg.Schema; s != nil {
+		switch {
+		case opType == parser.Query && s.QueryType != nil:
+			return s.QueryType.Name
+		case opType == parser.Mutation && s.MutationType != nil:
+			return s.MutationType.Name
+		case opType == parser.Subscription && s.SubscriptionType != nil:
+			return s.SubscriptionType.Name
+		}
+	}
+	switch opType {
+	case parser.Mutation:
+		return "Mutation"
+	case parser.Subscription:
+		return "Subscription"
+	}
+	return "Query"
+}
+
+func (e *estimator) selectionSet(set parser.SelectionSet, parent string) (float64, error) {
+	var total float64
+	for _, sel := range set {
+		var (
+			cost float64
+			err  error
+		)
+		switch sel := sel.(type) {
+		case *parser.Field:
+			if e.skipped(sel.Directives) {
+				continue
+			}
+			cost, err = e.field(sel, parent)
+		case *parser.InlineFragment:
+			if e.skipped(sel.Directives) {
+				continue
+			}
+			typ := parent
+			if sel.TypeCondition != "" {
+				typ = sel.TypeCondition
+			}
+			cost, err = e.selectionSet(sel.SelectionSet, typ)
+		case *parser.FragmentSpread:
+			if e.skipped(sel.Directives) {
+				continue
+			}
+			cost, err = e.fragment(sel.Name)
+		}
+		if err != nil {
+			return 0, err
+		}
+		total += cost
+	}
+	return total, nil
+}
+
+func (e *estimator) fragment(name string) (float64, error) {
+	f := e.doc.Fragment(name)
+	if f == nil {
+		return 0, errors.Errorf("unknown fragment %q", name)
+	}
+	if e.visiting[name] {
+		return 0, errors.Errorf("fragment %q spreads itself", name)
+	}
+	e.visiting[name] = true
+	defer delete(e.visiting, name)
+	return e.selectionSet(f.SelectionSet, f.TypeCondition)
+}
+
+func (e *estimator) field(f *parser.Field, parent string) (float64, error) {
+	if len(f.Name) > 1 && f.Name[:2] == "__" {
+		return 0, nil
+	}
+
+	typ, isList, known := e.fieldType(parent, f.Name)
+	composite := len(f.SelectionSet) > 0
+	if known {
+		composite = e.isComposite(typ)
+	}
+
+	weight := e.cfg.ScalarCost
+	if composite {
+		weight = e.cfg.ObjectCost
+	}
+	if w, ok := e.cfg.Weights[parent+"."+f.Name]; ok {
+		weight = w
+	} else if w, ok := e.cfg.Weights[f.Name]; ok {
+		weight = w
+	}
+
+	children, err := e.selectionSet(f.SelectionSet, typ)
+	if err != nil {
+		return 0, err
+	}
+	return e.listSize(f, isList) * (float64(weight) + children), nil
+}
+
+// fieldType resolves the named type of a field and whether it is a list.
+// known is false when there is no schema or the field is not in it.
+func (e *estimator) fieldType(parent, name string) (typ string, isList, known bool) {
+	if e.cfg.Schema == nil {
+		return "", false, false
+	}
+	t := e.cfg.Schema.Type(parent)
+	if t == nil {
+		return "", false, false
+	}
+	for _, f := range t.Fields {
+		if f.Name != name {
+			continue
+		}
+		for ref := &f.Type; ref != nil; ref = ref.OfType {
+			if ref.Kind == introspection.KindList {
+				isList = true
+			}
+		}
+		return f.Type.Named().Name, isList, true
+	}
+	return "", false, false
+}
+
+func (e *estimator) isComposite(name string) bool {
+	t := e.cfg.Schema.Type(name)
+	if t == nil {
+		return false
+	}
+	switch t.Kind {
+	case introspection.KindObject, introspection.KindInterface, introspection.KindUnion:
+		return true
+	}
+	return false
+}
+
+func (e *estimator) listSize(f *parser.Field, isList bool) float64 {
+	for _, arg := range f.Arguments {
+		for _, name := range e.cfg.ListSizeArguments {
+			if arg.Name != name {
+				continue
+			}
+			switch n := arg.Value.Resolve(e.variables).(type) {
+			case int64:
+				return math.Max(float64(n), 0)
+			case float64:
+				return math.Max(n, 0)
+			case int:
+				return math.Max(float64(n), 0)
+			}
+		}
+	}
+	if isList {
+		return float64(e.cfg.DefaultListSize)
+	}
+	return 1
+}
+
+// skipped evaluates @skip and @include.
+func (e *estimator) skipped(directives []*parser.Directive) bool {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			continue
+		}
+		for _, arg := range d.Arguments {
+			if arg.Name != "if" {
+				continue
+			}
+			cond, ok := arg.Value.Resolve(e.variables).(bool)
+			if !ok {
+				continue
+			}
+			if (d.Name == "skip" && cond) || (d.Name == "include" && !cond) {
+				return true
+			}
+		}
+	}
+	return false
+}
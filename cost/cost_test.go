@@ -0,0 +1,83 @@
+package cost
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/sumup/graphql/introspection"
+)
+
+const testSchema = `{
+	"queryType": {"name": "Query"},
+	"types": [
+		{"kind": "OBJECT", "name": "Query", "fields": [
+			{"name": "merchants", "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "Merchant"}}}},
+			{"name": "merchant", "type": {"kind": "OBJECT", "name": "Merchant"}}
+		]},
+		{"kind": "OBJECT", "name": "Merchant", "fields": [
+			{"name": "id", "type": {"kind": "SCALAR", "name": "ID"}},
+			{"name": "tags", "type": {"kind": "LIST", "ofType": {"kind": "SCALAR", "name": "String"}}},
+			{"name": "payouts", "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "Payout"}}}
+		]},
+		{"kind": "OBJECT", "name": "Payout", "fields": [
+			{"name": "amount", "type": {"kind": "SCALAR", "name": "Int"}}
+		]}
+	]
+}`
+
+func TestEstimateWithoutSchema(t *testing.T) {
+	is := is.New(t)
+
+	cost, err := Estimate(`
+		query ($n: Int) {
+			merchants(first: $n) {
+				id
+				payouts(first: 5) { amount }
+				...Owner
+			}
+		}
+		fragment Owner on Merchant { owner { name } }
+	`, "", map[string]interface{}{"n": float64(3)}, DefaultConfig())
+	is.NoErr(err)
+	// merchants: 3 * (1 + payouts 5 * (1 + 0) + owner 1)
+	is.Equal(cost, 21)
+}
+
+func TestEstimateWithSchema(t *testing.T) {
+	is := is.New(t)
+
+	var schema introspection.Schema
+	is.NoErr(json.Unmarshal([]byte(testSchema), &schema))
+	cfg := DefaultConfig()
+	cfg.Schema = &schema
+	cfg.Weights = map[string]int{"Merchant.payouts": 5}
+
+	cost, err := Estimate(`{
+		merchants {
+			id
+			tags
+			payouts { amount }
+			skipped: payouts @skip(if: true) { amount }
+			__typename
+		}
+		merchant { id }
+	}`, "", nil, cfg)
+	is.NoErr(err)
+	// merchants: 10 * (1 + tags 10 * 0 + payouts 10 * (5 + 0)) + merchant 1
+	is.Equal(cost, 511)
+}
+
+func TestEstimateErrors(t *testing.T) {
+	is := is.New(t)
+
+	_, err := Estimate(`{ a { ...F } } fragment F on A { ...F }`, "", nil, DefaultConfig())
+	is.Equal(err.Error(), `fragment "F" spreads itself`)
+
+	_, err = Estimate(`{ a { ...Missing } }`, "", nil, DefaultConfig())
+	is.Equal(err.Error(), `unknown fragment "Missing"`)
+
+	_, err = Estimate(`{ a `, "", nil, DefaultConfig())
+	is.True(err != nil)
+}
@@ -0,0 +1,112 @@
+package graphql
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Decompressor decodes a response body of a content coding.
+type Decompressor func(io.Reader) (io.ReadCloser, error)
+
+var (
+	decompressorsMu sync.RWMutex
+	decompressors   = map[string]Decompressor{
+		CompressionGzip: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		CompressionDeflate: zlib.NewReader,
+	}
+)
+
+// RegisterDecompressor registers the decoder of a content coding of
+// responses, e.g. brotli with github.com/andybalholm/brotli:
+//
+//	graphql.RegisterDecompressor("br", func(r io.Reader) (io.ReadCloser, error) {
+//		return io.NopCloser(brotli.NewReader(r)), nil
+//	})
+//
+// gzip and deflate are built in. Names are case insensitive.
+func RegisterDecompressor(encoding string, decompress Decompressor) {
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+	decompressors[strings.ToLower(encoding)] = decompress
+}
+
+func lookupDecompressor(encoding string) (Decompressor, bool) {
+	decompressorsMu.RLock()
+	defer decompressorsMu.RUnlock()
+	d, ok := decompressors[strings.ToLower(encoding)]
+	return d, ok
+}
+
+// WithResponseDecompression advertises encodings, in order of preference,
+// in the Accept-Encoding header and decodes the responses using them, so
+// response bodies are always plain whatever the transport. Only gzip and
+// deflate are built in: no decoder of br nor zstd ships with this
+// package, so they must be registered with RegisterDecompressor.
+// Encodings without a decompressor are left out of Accept-Encoding, and
+// NewClientE rejects them.
+//
+//	NewClient(endpoint, WithResponseDecompression("br", "zstd", "gzip"))
+func WithResponseDecompression(encodings ...string) ClientOption {
+	return func(client *Client) {
+		client.decompression = encodings
+	}
+}
+
+// acceptedEncodings returns the encodings that have a decompressor.
+func acceptedEncodings(encodings []string) []string {
+	accepted := make([]string, 0, len(encodings))
+	for _, encoding := range encodings {
+		if _, ok := lookupDecompressor(encoding); ok {
+			accepted = append(accepted, encoding)
+		}
+	}
+	return accepted
+}
+
+// decompressResponse replaces the body of res encoded with one of the
+// registered encodings with its decoded content. Other encodings are
+// reported, since their body cannot be decoded.
+func decompressResponse(res *http.Response) error {
+	encoding := strings.TrimSpace(res.Header.Get("Content-Encoding"))
+	if encoding == "" || strings.EqualFold(encoding, "identity") || res.Body == nil {
+		return nil
+	}
+	decompress, ok := lookupDecompressor(encoding)
+	if !ok {
+		res.Body.Close()
+		return errors.Errorf("no decompressor registered for %s response", encoding)
+	}
+	decoded, err := decompress(res.Body)
+	if err != nil {
+		res.Body.Close()
+		return errors.Wrapf(err, "decoding %s response", encoding)
+	}
+	res.Body = &decompressedBody{ReadCloser: decoded, raw: res.Body}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+	return nil
+}
+
+// decompressedBody closes both the decoder and the body it reads.
+type decompressedBody struct {
+	io.ReadCloser
+	raw io.Closer
+}
+
+func (b *decompressedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if rerr := b.raw.Close(); err == nil {
+		err = rerr
+	}
+	return err
+}
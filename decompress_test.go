@@ -0,0 +1,86 @@
+package graphql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithResponseDecompression(t *testing.T) {
+	is := is.New(t)
+
+	// x-reverse stands for codings net/http does not handle, e.g. br.
+	RegisterDecompressor("X-Reverse", func(r io.Reader) (io.ReadCloser, error) {
+		b, err := ioutil.ReadAll(r)
+		for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+		return ioutil.NopCloser(bytes.NewReader(b)), err
+	})
+
+	const body = `{"data":{"merchant":{"name":"Bakery"}}}`
+	var accepted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepted = r.Header.Get("Accept-Encoding")
+		switch r.Header.Get("X-Test-Encoding") {
+		case "x-reverse":
+			w.Header().Set("Content-Encoding", "x-reverse")
+			b := []byte(body)
+			for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+				b[i], b[j] = b[j], b[i]
+			}
+			_, _ = w.Write(b)
+		case "gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(w)
+			_, _ = io.WriteString(zw, body)
+			is.NoErr(zw.Close())
+		case "zstd":
+			w.Header().Set("Content-Encoding", "zstd")
+			_, _ = io.WriteString(w, body)
+		case "broken":
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = io.WriteString(w, body)
+		default:
+			_, _ = io.WriteString(w, body)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithResponseDecompression("x-reverse", "gzip"))
+	for _, encoding := range []string{"x-reverse", "gzip", "identity"} {
+		var resp struct{ Merchant struct{ Name string } }
+		req := NewRequest("{ merchant { name } }")
+		req.Header("X-Test-Encoding", encoding)
+		is.NoErr(client.Run(context.Background(), req, &resp))
+		is.Equal(resp.Merchant.Name, "Bakery")
+		is.Equal(accepted, "x-reverse, gzip")
+	}
+
+	req := NewRequest("{ merchant { name } }")
+	req.Header("X-Test-Encoding", "broken")
+	err := client.Run(context.Background(), req, nil)
+	_, ok := err.(*ExecutionError)
+	is.True(ok)
+
+	_, cerr := NewClientE(srv.URL, WithResponseDecompression("zstd"))
+	is.True(cerr != nil)
+
+	// zstd has no decompressor: it is not advertised, and a response
+	// using it is reported rather than decoded as JSON.
+	client = NewClient(srv.URL, WithResponseDecompression("zstd", "gzip"))
+	req = NewRequest("{ merchant { name } }")
+	req.Header("X-Test-Encoding", "zstd")
+	err = client.Run(context.Background(), req, nil)
+	is.Equal(accepted, "gzip")
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "no decompressor registered for zstd"))
+}
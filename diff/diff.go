@@ -0,0 +1,156 @@
+// Package diff compares the data returned for an operation by two
+// endpoints, or by two versions of an operation, which helps to verify
+// migrations between gateways and schema versions.
+package diff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sumup/graphql"
+)
+
+// Change kinds.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Difference is a value that differs between the two responses.
+type Difference struct {
+	// Path is the JSON path of the value, e.g. "merchant.payouts[0].amount".
+	Path string
+	Kind string
+	A    interface{}
+	B    interface{}
+}
+
+func (d Difference) String() string {
+	switch d.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", d.Path, format(d.B))
+	case Removed:
+		return fmt.Sprintf("- %s: %s", d.Path, format(d.A))
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", d.Path, format(d.A), format(d.B))
+}
+
+// Result holds the outcome of running an operation against both sides.
+type Result struct {
+	// ErrA and ErrB are the errors returned by each side.
+	ErrA, ErrB graphql.Error
+	// Differences between the data, sorted by path.
+	Differences []Difference
+}
+
+// Equal reports whether both sides returned the same data and errors.
+func (r *Result) Equal() bool {
+	return len(r.Differences) == 0 && errorString(r.ErrA) == errorString(r.ErrB)
+}
+
+// Run executes opA with a and opB with b and compares the data. Pass the
+// same operation twice to compare endpoints, or the same client twice to
+// compare operation versions.
+func Run(ctx context.Context, a graphql.GraphClient, opA graphql.Operation, b graphql.GraphClient, opB graphql.Operation) *Result {
+	var dataA, dataB json.RawMessage
+	res := &Result{
+		ErrA: a.Run(ctx, opA, &dataA),
+		ErrB: b.Run(ctx, opB, &dataB),
+	}
+	res.Differences = Compare(decode(dataA), decode(dataB))
+	return res
+}
+
+// Compare returns the differences between two decoded JSON values.
+func Compare(a, b interface{}) []Difference {
+	var diffs []Difference
+	compare("", a, b, &diffs)
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+func compare(path string, a, b interface{}, diffs *[]Difference) {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for key, va := range a {
+			vb, ok := b[key]
+			if !ok {
+				*diffs = append(*diffs, Difference{Path: join(path, key), Kind: Removed, A: va})
+				continue
+			}
+			compare(join(path, key), va, vb, diffs)
+		}
+		for key, vb := range b {
+			if _, ok := a[key]; !ok {
+				*diffs = append(*diffs, Difference{Path: join(path, key), Kind: Added, B: vb})
+			}
+		}
+		return
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(a) || i < len(b); i++ {
+			p := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(b):
+				*diffs = append(*diffs, Difference{Path: p, Kind: Removed, A: a[i]})
+			case i >= len(a):
+				*diffs = append(*diffs, Difference{Path: p, Kind: Added, B: b[i]})
+			default:
+				compare(p, a[i], b[i], diffs)
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		*diffs = append(*diffs, Difference{Path: path, Kind: Changed, A: a, B: b})
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func decode(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return string(data)
+	}
+	return v
+}
+
+func format(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func errorString(err graphql.Error) string {
+	if err == nil {
+		return ""
+	}
+	return strings.Join(err.Errors(), "\n")
+}
@@ -0,0 +1,79 @@
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/sumup/graphql"
+)
+
+func TestRun(t *testing.T) {
+	is := is.New(t)
+	a := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"merchant":{"id":"1","name":"Old","tags":["a","b"],"legacy":true,"amount":1.10}}}`)
+	}))
+	defer a.Close()
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"merchant":{"id":"1","name":"New","tags":["a"],"country":"DE","amount":1.1}}}`)
+	}))
+	defer b.Close()
+
+	op := graphql.NewRequest("{ merchant { id name tags } }")
+	res := Run(context.Background(), graphql.NewClient(a.URL), op, graphql.NewClient(b.URL), op)
+	is.True(!res.Equal())
+
+	var lines []string
+	for _, d := range res.Differences {
+		lines = append(lines, d.String())
+	}
+	is.Equal(lines, []string{
+		`~ merchant.amount: 1.10 -> 1.1`,
+		`+ merchant.country: "DE"`,
+		`- merchant.legacy: true`,
+		`~ merchant.name: "Old" -> "New"`,
+		`- merchant.tags[1]: "b"`,
+	})
+}
+
+func TestRunErrors(t *testing.T) {
+	is := is.New(t)
+	a := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer a.Close()
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"errors":[{"message":"Cannot query field \"ok\""}]}`)
+	}))
+	defer b.Close()
+
+	op := graphql.NewRequest("{ ok }")
+	res := Run(context.Background(), graphql.NewClient(a.URL), op, graphql.NewClient(b.URL), op)
+	is.True(!res.Equal())
+	is.True(res.ErrA == nil)
+	is.Equal(res.ErrB.Error(), `Cannot query field "ok"`)
+
+	same := Run(context.Background(), graphql.NewClient(a.URL), op, graphql.NewClient(a.URL), op)
+	is.True(same.Equal())
+}
+
+type staticClient string
+
+func (c staticClient) Run(ctx context.Context, op graphql.Operation, resp interface{}) graphql.Error {
+	*resp.(*json.RawMessage) = json.RawMessage(c)
+	return nil
+}
+
+func TestRunWithGraphClient(t *testing.T) {
+	is := is.New(t)
+
+	op := graphql.NewRequest("{ ok }")
+	res := Run(context.Background(), staticClient(`{"ok":true}`), op, staticClient(`{"ok":false}`), op)
+	is.Equal(len(res.Differences), 1)
+	is.Equal(res.Differences[0].String(), `~ ok: true -> false`)
+}
@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// endpointKey and endpointParamsKey are the context keys of the per-call
+// endpoint settings.
+type (
+	endpointKey       struct{}
+	endpointParamsKey struct{}
+)
+
+// WithEndpoint returns a context that makes Run send the operation to
+// endpoint instead of the one of the client. The endpoint may contain
+// placeholders like the one of the client.
+func WithEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointKey{}, endpoint)
+}
+
+// WithEndpointParams returns a context that resolves the placeholders of
+// the endpoint with params. The endpoint of a client shared by several
+// tenants may then contain placeholders in braces, e.g.
+//
+//	client := NewClient("https://{tenant}.example.com/graphql")
+//	ctx = WithEndpointParams(ctx, map[string]string{"tenant": "acme"})
+//
+// Placeholders not found in params are resolved with the tags of the
+// operation, see Req.Tag. Values are escaped as URL path segments.
+func WithEndpointParams(ctx context.Context, params map[string]string) context.Context {
+	return context.WithValue(ctx, endpointParamsKey{}, params)
+}
+
+// resolveEndpoint gets the endpoint of req, replacing its placeholders.
+func (c *Client) resolveEndpoint(ctx context.Context, req *Req) (string, error) {
+	endpoint := c.endpoint
+	if e, ok := ctx.Value(endpointKey{}).(string); ok {
+		endpoint = e
+	}
+	if !strings.Contains(endpoint, "{") {
+		return endpoint, nil
+	}
+	params, _ := ctx.Value(endpointParamsKey{}).(map[string]string)
+
+	var b strings.Builder
+	rest := endpoint
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", errors.Errorf("endpoint %q has an unterminated placeholder", endpoint)
+		}
+		name := rest[start+1 : start+end]
+		value, ok := params[name]
+		if !ok {
+			value, ok = req.tags[name]
+		}
+		if !ok {
+			return "", errors.Errorf("endpoint %q: no value for placeholder %q", endpoint, name)
+		}
+		b.WriteString(rest[:start])
+		b.WriteString(url.PathEscape(value))
+		rest = rest[start+end+1:]
+	}
+	b.WriteString(rest)
+	return b.String(), nil
+}
+
+// withoutPlaceholders replaces the placeholders of endpoint with a valid
+// host or path segment, so a templated endpoint can be validated.
+func withoutPlaceholders(endpoint string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(endpoint, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(endpoint[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(endpoint[:start])
+		b.WriteString("placeholder")
+		endpoint = endpoint[start+end+1:]
+	}
+	b.WriteString(endpoint)
+	return b.String()
+}
@@ -0,0 +1,44 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestEndpointPlaceholders(t *testing.T) {
+	is := is.New(t)
+
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClientE(srv.URL + "/{tenant}/graphql")
+	is.NoErr(err)
+	ctx := context.Background()
+
+	req := NewRequest("query {}")
+	req.Tag("tenant", "acme")
+	is.NoErr(client.Run(ctx, req, nil))
+
+	is.NoErr(client.Run(WithEndpointParams(ctx, map[string]string{"tenant": "a/b"}), req, nil))
+	is.NoErr(client.Run(WithEndpoint(ctx, srv.URL+"/other"), req, nil))
+	is.Equal(paths, []string{"/acme/graphql", "/a%2Fb/graphql", "/other"})
+
+	err = client.Run(ctx, NewRequest("query {}"), nil)
+	is.Equal(err.Error(), `endpoint "`+srv.URL+`/{tenant}/graphql": no value for placeholder "tenant"`)
+}
+
+func TestEndpointPlaceholderInHost(t *testing.T) {
+	is := is.New(t)
+
+	_, err := NewClientE("https://{tenant}.example.com/graphql")
+	is.NoErr(err)
+}
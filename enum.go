@@ -0,0 +1,137 @@
+package graphql
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Case is a naming convention of enum values.
+type Case int
+
+const (
+	// ScreamingSnakeCase, e.g. CARD_PRESENT, is the usual convention of
+	// GraphQL enums.
+	ScreamingSnakeCase Case = iota
+	// SnakeCase, e.g. card_present.
+	SnakeCase
+	// CamelCase, e.g. cardPresent.
+	CamelCase
+	// PascalCase, e.g. CardPresent.
+	PascalCase
+)
+
+// enumMapping converts the values of an enum type between its Go and
+// GraphQL conventions.
+type enumMapping struct {
+	goCase, graphqlCase Case
+}
+
+// WithEnumCase converts the values of the given Go enum types, string
+// types passed as sample values, from goCase to graphqlCase in variables
+// and back in responses, so the types need no MarshalJSON methods:
+//
+//	type Status string // "cardPresent", "refunded", ...
+//
+//	NewClient(endpoint, WithEnumCase(CamelCase, ScreamingSnakeCase, Status("")))
+func WithEnumCase(goCase, graphqlCase Case, types ...interface{}) ClientOption {
+	return func(client *Client) {
+		if client.enums == nil {
+			client.enums = make(map[reflect.Type]enumMapping)
+		}
+		for _, sample := range types {
+			client.enums[reflect.TypeOf(sample)] = enumMapping{goCase: goCase, graphqlCase: graphqlCase}
+		}
+	}
+}
+
+// enumConverter sends the values of enum types in GraphQL case.
+func (c *Client) enumConverter(v reflect.Value) (interface{}, bool) {
+	mapping, ok := c.enums[v.Type()]
+	if !ok || v.Kind() != reflect.String {
+		return nil, false
+	}
+	return convertCase(v.String(), mapping.graphqlCase), true
+}
+
+// convertEnums converts the enum values of the decoded response v back to
+// Go case.
+func (c *Client) convertEnums(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			c.convertEnums(v.Elem())
+		}
+	case reflect.String:
+		if mapping, ok := c.enums[v.Type()]; ok && v.CanSet() {
+			v.SetString(convertCase(v.String(), mapping.goCase))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				c.convertEnums(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.convertEnums(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			c.convertEnums(elem)
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+}
+
+// convertCase rewrites s, in any of the cases, in c.
+func convertCase(s string, c Case) string {
+	words := splitWords(s)
+	for i, w := range words {
+		switch {
+		case c == ScreamingSnakeCase:
+			words[i] = strings.ToUpper(w)
+		case c == SnakeCase || (c == CamelCase && i == 0):
+			words[i] = strings.ToLower(w)
+		default:
+			r := []rune(strings.ToLower(w))
+			r[0] = unicode.ToUpper(r[0])
+			words[i] = string(r)
+		}
+	}
+	if c == ScreamingSnakeCase || c == SnakeCase {
+		return strings.Join(words, "_")
+	}
+	return strings.Join(words, "")
+}
+
+// splitWords splits s at underscores, dashes, spaces and case changes,
+// keeping acronyms together, e.g. "HTTPStatus_code" into "HTTP",
+// "Status" and "code".
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		if i == len(runes) || runes[i] == '_' || runes[i] == '-' || runes[i] == ' ' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i > start && unicode.IsUpper(runes[i]) &&
+			(unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return words
+}
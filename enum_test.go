@@ -0,0 +1,69 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type paymentStatus string
+
+func TestConvertCase(t *testing.T) {
+	is := is.New(t)
+
+	tests := []struct {
+		in   string
+		c    Case
+		want string
+	}{
+		{"cardPresent", ScreamingSnakeCase, "CARD_PRESENT"},
+		{"CARD_PRESENT", CamelCase, "cardPresent"},
+		{"CARD_PRESENT", PascalCase, "CardPresent"},
+		{"HTTPStatus", SnakeCase, "http_status"},
+		{"card-present", ScreamingSnakeCase, "CARD_PRESENT"},
+		{"refunded", ScreamingSnakeCase, "REFUNDED"},
+		{"PAYOUT_V2", CamelCase, "payoutV2"},
+	}
+	for _, test := range tests {
+		is.Equal(convertCase(test.in, test.c), test.want)
+	}
+}
+
+func TestWithEnumCase(t *testing.T) {
+	is := is.New(t)
+
+	var vars map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct{ Variables map[string]interface{} }
+		is.NoErr(json.NewDecoder(r.Body).Decode(&payload))
+		vars = payload.Variables
+		_, _ = io.WriteString(w, `{"data":{"payment":{"status":"CARD_PRESENT","history":["REFUNDED"],"byMerchant":{"m1":"CARD_PRESENT"},"note":"KEEP_ME"}}}`)
+	}))
+	defer srv.Close()
+
+	req := NewRequest("query ($status: PaymentStatus, $any: [PaymentStatus]) { payment { status } }")
+	req.Var("status", paymentStatus("cardPresent"))
+	req.Var("any", []paymentStatus{"refunded"})
+
+	var resp struct {
+		Payment struct {
+			Status     paymentStatus
+			History    []paymentStatus
+			ByMerchant map[string]paymentStatus
+			Note       string
+		}
+	}
+	client := NewClient(srv.URL, WithEnumCase(CamelCase, ScreamingSnakeCase, paymentStatus("")))
+	is.NoErr(client.Run(context.Background(), req, &resp))
+	is.Equal(vars["status"], "CARD_PRESENT")
+	is.Equal(vars["any"], []interface{}{"REFUNDED"})
+	is.Equal(resp.Payment.Status, paymentStatus("cardPresent"))
+	is.Equal(resp.Payment.History, []paymentStatus{"refunded"})
+	is.Equal(resp.Payment.ByMerchant, map[string]paymentStatus{"m1": "cardPresent"})
+	is.Equal(resp.Payment.Note, "KEEP_ME")
+}
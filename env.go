@@ -0,0 +1,123 @@
+package graphql
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultEnvPrefix is used by NewClientFromEnv when no prefix is given.
+const DefaultEnvPrefix = "GRAPHQL"
+
+// NewClientFromEnv creates a client configured by environment variables
+// named after prefix, e.g. with the prefix "PAYMENTS":
+//
+//	PAYMENTS_ENDPOINT                  endpoint URL, required
+//	PAYMENTS_TIMEOUT                   timeout of every call, e.g. "10s"
+//	PAYMENTS_MULTIPART                 "true" to use multipart/form-data
+//	PAYMENTS_CLIENT_NAME               client awareness name
+//	PAYMENTS_CLIENT_VERSION            client awareness version
+//	PAYMENTS_HEADER_<NAME>             header sent with every request, e.g.
+//	                                   PAYMENTS_HEADER_X_TENANT for X-Tenant
+//	PAYMENTS_TLS_CA_FILE               PEM file of CAs to trust
+//	PAYMENTS_TLS_CERT_FILE             PEM client certificate
+//	PAYMENTS_TLS_KEY_FILE              PEM client key
+//	PAYMENTS_TLS_SERVER_NAME           server name to verify
+//	PAYMENTS_TLS_INSECURE_SKIP_VERIFY  "true" to skip verification
+//
+// The prefix defaults to DefaultEnvPrefix. opts are applied after the
+// environment and take precedence. The client is validated like
+// NewClientE.
+func NewClientFromEnv(prefix string, opts ...ClientOption) (*Client, error) {
+	cfg, err := ConfigFromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientFromConfig(cfg, opts...)
+}
+
+// ConfigFromEnv reads the Config of NewClientFromEnv, so it can be
+// adjusted before creating the client.
+func ConfigFromEnv(prefix string) (Config, error) {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	env := envReader{prefix: prefix + "_"}
+
+	cfg := Config{
+		Endpoint:      env.get("ENDPOINT"),
+		Timeout:       Duration(env.duration("TIMEOUT")),
+		Multipart:     env.bool("MULTIPART"),
+		ClientName:    env.get("CLIENT_NAME"),
+		ClientVersion: env.get("CLIENT_VERSION"),
+		TLS: TLSConfig{
+			CAFile:             env.get("TLS_CA_FILE"),
+			CertFile:           env.get("TLS_CERT_FILE"),
+			KeyFile:            env.get("TLS_KEY_FILE"),
+			ServerName:         env.get("TLS_SERVER_NAME"),
+			InsecureSkipVerify: env.bool("TLS_INSECURE_SKIP_VERIFY"),
+		},
+	}
+	if cfg.Endpoint == "" {
+		return Config{}, errors.Errorf("graphql: %sENDPOINT is not set", env.prefix)
+	}
+	if env.err != nil {
+		return Config{}, errors.Wrap(env.err, "graphql")
+	}
+	for _, kv := range os.Environ() {
+		key, value := kv, ""
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			key, value = kv[:i], kv[i+1:]
+		}
+		if name := strings.TrimPrefix(key, env.prefix+"HEADER_"); name != key && name != "" {
+			if cfg.Headers == nil {
+				cfg.Headers = make(map[string]string)
+			}
+			cfg.Headers[strings.ReplaceAll(name, "_", "-")] = value
+		}
+	}
+	return cfg, nil
+}
+
+// envReader reads prefixed variables, keeping the first error.
+type envReader struct {
+	prefix string
+	err    error
+}
+
+func (e *envReader) get(name string) string {
+	return os.Getenv(e.prefix + name)
+}
+
+func (e *envReader) fail(name string, err error) {
+	if e.err == nil {
+		e.err = errors.Wrapf(err, "%s%s", e.prefix, name)
+	}
+}
+
+func (e *envReader) duration(name string) time.Duration {
+	s := e.get(name)
+	if s == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		e.fail(name, err)
+	}
+	return d
+}
+
+func (e *envReader) bool(name string) bool {
+	s := e.get(name)
+	if s == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		e.fail(name, err)
+	}
+	return b
+}
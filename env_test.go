@@ -0,0 +1,54 @@
+package graphql
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestNewClientFromEnv(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("X-Tenant"), "acme")
+		is.Equal(r.Header.Get("Apollographql-Client-Name"), "checkout")
+		_, _ = io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	is.NoErr(ioutil.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o644))
+
+	t.Setenv("PAYMENTS_ENDPOINT", srv.URL)
+	t.Setenv("PAYMENTS_TIMEOUT", "5s")
+	t.Setenv("PAYMENTS_HEADER_X_TENANT", "acme")
+	t.Setenv("PAYMENTS_CLIENT_NAME", "checkout")
+	t.Setenv("PAYMENTS_TLS_CA_FILE", ca)
+
+	client, err := NewClientFromEnv("PAYMENTS")
+	is.NoErr(err)
+	is.Equal(client.timeout.String(), "5s")
+
+	var resp struct{ OK bool }
+	is.NoErr(client.Run(context.Background(), NewRequest("{ ok }"), &resp))
+	is.True(resp.OK)
+}
+
+func TestNewClientFromEnvErrors(t *testing.T) {
+	is := is.New(t)
+
+	_, err := NewClientFromEnv("")
+	is.Equal(err.Error(), "graphql: GRAPHQL_ENDPOINT is not set")
+
+	t.Setenv("GRAPHQL_ENDPOINT", "https://example.com/graphql")
+	t.Setenv("GRAPHQL_TIMEOUT", "soon")
+	_, err = NewClientFromEnv("")
+	is.Equal(err.Error(), `graphql: GRAPHQL_TIMEOUT: time: invalid duration "soon"`)
+}
@@ -1,9 +1,12 @@
 package graphql
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
 type (
@@ -30,22 +33,49 @@ type (
 
 	GraphErr struct {
 		Code       string
-		Extentions GraphExt
+		Extentions GraphExt `json:"extensions"`
 		Message    string
 		Path       []string
 	}
 
 	GraphExt struct {
 		Code string
+		// Path and Internal are set by Hasura, which reports the JSON path
+		// of the failing input and the underlying database error.
+		Path     string
+		Internal json.RawMessage
 	}
 
 	ErrorDetail struct {
 		Code    string
 		Message string
 		Domain  string
+		// Internal holds the raw JSON of the internal error details some
+		// servers (e.g. Hasura) attach to an error.
+		Internal string
 	}
 )
 
+// ErrEmptyResponse is the cause of the *ExecutionError returned when a
+// server, usually a proxy, answers with a 2xx status and no body. Check
+// for it with errors.Is.
+var ErrEmptyResponse = errors.New("empty response body")
+
+// ErrNotJSON is the cause of the *ExecutionError returned when a server,
+// usually a load balancer or gateway, answers with a 2xx status and a
+// body that is not a JSON object, such as an HTML error page. The error
+// quotes the beginning of the body.
+var ErrNotJSON = errors.New("response is not JSON")
+
+// ErrUnexpectedContentType is the cause of the *ExecutionError returned
+// for responses that are not JSON by their Content-Type when the client
+// uses WithStrictContentType.
+var ErrUnexpectedContentType = errors.New("unexpected content type")
+
+// ErrNullData is the cause of the *ExecutionError returned for responses
+// with null data and no errors when the client uses NullDataError.
+var ErrNullData = errors.New("response data is null")
+
 var (
 	// Type assertions
 	_ Error = &RequestError{}
@@ -72,15 +102,36 @@ func (e GraphErr) ErrCode() string {
 }
 
 func (e GraphErr) ErrPath() string {
+	if len(e.Path) == 0 {
+		return e.Extentions.Path
+	}
 	return strings.Join(e.Path, ".")
 }
 
 func (e GraphErr) ToErrorDetail() ErrorDetail {
 	return ErrorDetail{
-		Code:    e.ErrCode(),
-		Message: e.Message,
-		Domain:  e.ErrPath(),
+		Code:     e.ErrCode(),
+		Message:  e.Message,
+		Domain:   e.ErrPath(),
+		Internal: string(e.Extentions.Internal),
+	}
+}
+
+// hasErrorCode reports whether err is an Error with a detail matching
+// one of codes. Codes are compared case-insensitively.
+func hasErrorCode(err error, codes ...string) bool {
+	var e Error
+	if !errors.As(err, &e) {
+		return false
 	}
+	for _, detail := range e.Details() {
+		for _, code := range codes {
+			if strings.EqualFold(detail.Code, code) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func NewRequestError(response *http.Response) *RequestError {
@@ -111,6 +162,23 @@ func (r *RequestError) Details() []ErrorDetail {
 	}
 }
 
+// VariableError is the cause of the *ExecutionError returned when a
+// variable cannot be encoded to JSON. Get it with errors.As.
+type VariableError struct {
+	// Name is the name of the variable, without $.
+	Name string
+	// Err is the encoding error.
+	Err error
+}
+
+func (e *VariableError) Error() string {
+	return fmt.Sprintf("variable $%s cannot be encoded: %v", e.Name, e.Err)
+}
+
+func (e *VariableError) Unwrap() error {
+	return e.Err
+}
+
 func NewExecutionError(message error) *ExecutionError {
 	return &ExecutionError{
 		message: message,
@@ -125,6 +193,12 @@ func (e *ExecutionError) Error() string {
 	return e.message.Error()
 }
 
+// Unwrap gets the underlying error, e.g. context.Canceled or
+// context.DeadlineExceeded when the call was interrupted.
+func (e *ExecutionError) Unwrap() error {
+	return e.message
+}
+
 func (e *ExecutionError) Errors() []string {
 	return []string{e.message.Error()}
 }
@@ -1,6 +1,8 @@
 package graphql
 
 import (
+	"context"
+	"io/ioutil"
 	"net/http"
 	"strings"
 	"testing"
@@ -288,3 +290,35 @@ func TestGraphQLErrorDetails(t *testing.T) {
 		Domain:  "field.path",
 	})
 }
+
+type domainError struct {
+	*GraphQLError
+}
+
+func (d domainError) Error() string {
+	return "user not found"
+}
+
+func TestWithErrorTranslator(t *testing.T) {
+	is := is.New(t)
+	testClient := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"errors":[{"message":"no user","extensions":{"code":"NOT_FOUND"}}]}`)),
+			}, nil
+		}),
+	}
+
+	client := NewClient("", WithHTTPClient(testClient), WithErrorTranslator(func(err Error) Error {
+		if gqlErr, ok := err.(*GraphQLError); ok && err.Code() == "not_found" {
+			return domainError{gqlErr}
+		}
+		return nil
+	}))
+
+	err := client.Run(context.Background(), NewRequest("query {}"), nil)
+	is.Equal(err.Error(), "user not found")
+	_, ok := err.(domainError)
+	is.True(ok)
+}
@@ -0,0 +1,125 @@
+package graphql
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// TenantConfig is what sets the client of a tenant apart.
+	TenantConfig struct {
+		Endpoint string
+		// Header is sent with every request of the tenant, e.g. its
+		// Authorization.
+		Header http.Header
+		// Options are applied after the shared options of the factory.
+		Options []ClientOption
+	}
+
+	// ClientFactory creates the clients of the tenants of a multi-tenant
+	// service. They share one HTTP client, so one transport, connection
+	// pool and chain of transport middleware, rather than opening
+	// connections per tenant. Tenants with a unix:// endpoint get a copy
+	// of the transport dialing their socket.
+	//
+	// The limit of WithMaxConcurrency in the shared options bounds the
+	// calls of all the tenants together, and the Stats of every client
+	// count the calls of all the tenants.
+	ClientFactory struct {
+		resolve func(tenant string) (TenantConfig, error)
+		shared  []ClientOption
+		// base holds the HTTP client, limiter and stats shared by the
+		// clients of the tenants.
+		base *Client
+
+		mu      sync.Mutex
+		clients map[string]*Client
+		// forgotten counts the calls to Forget by tenant, so a client
+		// resolved before one of them is not kept.
+		forgotten map[string]int
+	}
+)
+
+// NewClientFactory creates a ClientFactory getting the configuration of
+// tenants from resolve. shared applies to every client; the HTTP client
+// it sets with WithHTTPClient, http.DefaultClient otherwise, is used by
+// every tenant even if their options set another one.
+//
+//	factory := graphql.NewClientFactory(func(tenant string) (graphql.TenantConfig, error) {
+//		return graphql.TenantConfig{
+//			Endpoint: "https://" + tenant + ".example.com/graphql",
+//			Header:   http.Header{"Authorization": {"Bearer " + tokens[tenant]}},
+//		}, nil
+//	}, graphql.WithHTTPClient(tunedClient), graphql.WithTimeout(5*time.Second))
+//
+//	client, err := factory.Client("acme")
+func NewClientFactory(resolve func(tenant string) (TenantConfig, error), shared ...ClientOption) *ClientFactory {
+	return &ClientFactory{
+		resolve:   resolve,
+		shared:    shared,
+		base:      NewClient("", shared...),
+		clients:   make(map[string]*Client),
+		forgotten: make(map[string]int),
+	}
+}
+
+// Client gets the client of tenant, creating it on first use. Tenants
+// are resolved without holding the lock, so a slow resolution does not
+// hold up the other tenants.
+func (f *ClientFactory) Client(tenant string) (*Client, error) {
+	f.mu.Lock()
+	c, ok := f.clients[tenant]
+	generation := f.forgotten[tenant]
+	f.mu.Unlock()
+	if ok {
+		return c, nil
+	}
+	cfg, err := f.resolve(tenant)
+	if err != nil {
+		return nil, errors.Wrapf(err, "graphql: resolving tenant %q", tenant)
+	}
+	opts := make([]ClientOption, 0, len(f.shared)+len(cfg.Options)+3)
+	opts = append(opts, f.shared...)
+	opts = append(opts, func(client *Client) {
+		client.limiter = f.base.limiter
+		client.stats = f.base.stats
+		for key, values := range cfg.Header {
+			client.header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+		}
+	})
+	opts = append(opts, cfg.Options...)
+	opts = append(opts, WithHTTPClient(f.base.httpClient))
+	c, err = NewClientE(cfg.Endpoint, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "tenant %q", tenant)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// Keep the client of a concurrent call that won the race.
+	if existing, ok := f.clients[tenant]; ok {
+		return existing, nil
+	}
+	// Do not keep a client resolved before the tenant was forgotten.
+	if f.forgotten[tenant] == generation {
+		f.clients[tenant] = c
+	}
+	return c, nil
+}
+
+// Forget drops the client of tenant, so the next call to Client resolves
+// its configuration again, e.g. after its credentials changed.
+func (f *ClientFactory) Forget(tenant string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.clients, tenant)
+	f.forgotten[tenant]++
+}
+
+// Stats returns a snapshot of the counters of the clients of all the
+// tenants.
+func (f *ClientFactory) Stats() Stats {
+	return f.base.Stats()
+}
@@ -0,0 +1,185 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+)
+
+type countingTransport struct {
+	requests int32
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.requests, 1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestClientFactory(t *testing.T) {
+	is := is.New(t)
+
+	seen := make(map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen[r.URL.Path] = r.Header.Get("Authorization")
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	transport := &countingTransport{}
+	resolved := 0
+	factory := NewClientFactory(func(tenant string) (TenantConfig, error) {
+		resolved++
+		if tenant == "unknown" {
+			return TenantConfig{}, errors.New("no such tenant")
+		}
+		return TenantConfig{
+			Endpoint: srv.URL + "/" + tenant,
+			Header:   http.Header{"Authorization": {"Bearer " + tenant}},
+			// The shared HTTP client wins over the ones of tenants.
+			Options: []ClientOption{WithHTTPClient(http.DefaultClient)},
+		}, nil
+	}, WithHTTPClient(&http.Client{Transport: transport}))
+
+	for _, tenant := range []string{"acme", "globex", "acme"} {
+		client, err := factory.Client(tenant)
+		is.NoErr(err)
+		is.NoErr(client.Run(context.Background(), NewRequest("query {}"), nil))
+	}
+	is.Equal(seen, map[string]string{"/acme": "Bearer acme", "/globex": "Bearer globex"})
+	is.Equal(atomic.LoadInt32(&transport.requests), int32(3))
+	is.Equal(resolved, 2)
+
+	factory.Forget("acme")
+	_, err := factory.Client("acme")
+	is.NoErr(err)
+	is.Equal(resolved, 3)
+
+	_, err = factory.Client("unknown")
+	is.Equal(err.Error(), `graphql: resolving tenant "unknown": no such tenant`)
+}
+
+func TestClientFactoryUnixTenant(t *testing.T) {
+	is := is.New(t)
+
+	socket := filepath.Join(t.TempDir(), "gateway.sock")
+	l, err := net.Listen("unix", socket)
+	is.NoErr(err)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	})}
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Close()
+
+	factory := NewClientFactory(func(tenant string) (TenantConfig, error) {
+		return TenantConfig{Endpoint: "unix://" + socket + "?path=/graphql"}, nil
+	})
+	client, cerr := factory.Client("acme")
+	is.NoErr(cerr)
+	is.NoErr(client.Run(context.Background(), NewRequest("query {}"), nil))
+}
+
+func TestClientFactorySlowResolve(t *testing.T) {
+	is := is.New(t)
+
+	resolving, release := make(chan struct{}), make(chan struct{})
+	factory := NewClientFactory(func(tenant string) (TenantConfig, error) {
+		if tenant == "slow" {
+			close(resolving)
+			<-release
+		}
+		return TenantConfig{Endpoint: "https://" + tenant + ".example.com/graphql"}, nil
+	})
+
+	done := make(chan *Client)
+	go func() {
+		client, _ := factory.Client("slow")
+		done <- client
+	}()
+	<-resolving
+	// Other tenants are served while the slow one resolves.
+	_, err := factory.Client("fast")
+	is.NoErr(err)
+
+	close(release)
+	slow := <-done
+	is.True(slow != nil)
+	again, err := factory.Client("slow")
+	is.NoErr(err)
+	is.True(again == slow)
+}
+
+func TestClientFactorySharedLimit(t *testing.T) {
+	is := is.New(t)
+
+	arrived, release := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/acme" {
+			close(arrived)
+			<-release
+		}
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	factory := NewClientFactory(func(tenant string) (TenantConfig, error) {
+		return TenantConfig{Endpoint: srv.URL + "/" + tenant}, nil
+	}, WithMaxConcurrency(1), WithLimitPolicy(LimitFailFast))
+	acme, err := factory.Client("acme")
+	is.NoErr(err)
+	globex, err := factory.Client("globex")
+	is.NoErr(err)
+
+	done := make(chan Error)
+	go func() { done <- acme.Run(context.Background(), NewRequest("query {}"), nil) }()
+	<-arrived
+	// The limit bounds the calls of all the tenants together.
+	gerr := globex.Run(context.Background(), NewRequest("query {}"), nil)
+	is.True(errors.Is(gerr, ErrConcurrencyLimit))
+	close(release)
+	is.NoErr(<-done)
+
+	is.NoErr(globex.Run(context.Background(), NewRequest("query {}"), nil))
+	is.Equal(factory.Stats().Requests, int64(2))
+	is.Equal(acme.Stats().Requests, int64(2))
+	is.Equal(factory.Stats().Errors["execution"], int64(1))
+}
+
+func TestClientFactoryForgetWhileResolving(t *testing.T) {
+	is := is.New(t)
+
+	resolving, release := make(chan struct{}), make(chan struct{})
+	resolved := 0
+	factory := NewClientFactory(func(tenant string) (TenantConfig, error) {
+		resolved++
+		if resolved == 1 {
+			close(resolving)
+			<-release
+		}
+		return TenantConfig{Endpoint: "https://" + tenant + ".example.com/graphql"}, nil
+	})
+
+	done := make(chan *Client)
+	go func() {
+		client, _ := factory.Client("acme")
+		done <- client
+	}()
+	<-resolving
+	factory.Forget("acme")
+	close(release)
+	stale := <-done
+	is.True(stale != nil)
+
+	// The client resolved before Forget is not kept.
+	fresh, err := factory.Client("acme")
+	is.NoErr(err)
+	is.True(fresh != stale)
+	is.Equal(resolved, 2)
+}
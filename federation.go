@@ -0,0 +1,131 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// Representation identifies an entity of a federated graph by its
+	// __typename and key fields.
+	Representation map[string]interface{}
+
+	// Entity is an entity returned by an _entities query. Data is nil when
+	// the subgraph could not resolve the representation.
+	Entity struct {
+		Typename string
+		Data     json.RawMessage
+	}
+)
+
+// NewRepresentation creates the representation of an entity of type
+// typename with the given key fields.
+func NewRepresentation(typename string, keys map[string]interface{}) Representation {
+	r := Representation{"__typename": typename}
+	for key, value := range keys {
+		r[key] = value
+	}
+	return r
+}
+
+// RepresentationOf creates the representation of an entity of type
+// typename from keys, a struct or map holding its key fields, encoded to
+// JSON as variables are, so typed keys follow their json tags:
+//
+//	type productKey struct {
+//		UPC string `json:"upc"`
+//	}
+//	r, err := graphql.RepresentationOf("Product", productKey{UPC: "1"})
+//
+// keys must encode to a JSON object.
+func RepresentationOf(typename string, keys interface{}) (Representation, error) {
+	b, err := json.Marshal(keys)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding representation keys")
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil || fields == nil {
+		return nil, errors.Errorf("representation keys must encode to an object, got %T", keys)
+	}
+	return NewRepresentation(typename, fields), nil
+}
+
+// Typename gets the __typename of the representation.
+func (r Representation) Typename() string {
+	typename, _ := r["__typename"].(string)
+	return typename
+}
+
+// NewEntitiesRequest builds an _entities query for representations.
+// selections maps each __typename to the fields selected on it, with or
+// without the surrounding braces, e.g. {"Product": "upc name"}.
+func NewEntitiesRequest(representations []Representation, selections map[string]string) *Request {
+	typenames := make([]string, 0, len(selections))
+	for typename := range selections {
+		typenames = append(typenames, typename)
+	}
+	sort.Strings(typenames)
+
+	var q strings.Builder
+	q.WriteString("query($representations:[_Any!]!){_entities(representations:$representations){__typename")
+	for _, typename := range typenames {
+		selection := strings.TrimSpace(selections[typename])
+		if !strings.HasPrefix(selection, "{") {
+			selection = "{" + selection + "}"
+		}
+		q.WriteString(" ... on " + typename + " " + selection)
+	}
+	q.WriteString("}}")
+
+	req := NewRequest(q.String())
+	req.Var("representations", representations)
+	return req
+}
+
+// Entities resolves representations through the _entities field of a
+// federated graph. The entities are returned in the order of the
+// representations so they can be decoded per __typename:
+//
+//	for _, e := range entities {
+//	    switch e.Typename {
+//	    case "Product":
+//	        var p Product
+//	        err = e.Decode(&p)
+//	    }
+//	}
+func (c *Client) Entities(ctx context.Context, representations []Representation, selections map[string]string) ([]Entity, Error) {
+	var resp struct {
+		Entities []json.RawMessage `json:"_entities"`
+	}
+	if err := c.Run(ctx, NewEntitiesRequest(representations, selections), &resp); err != nil {
+		return nil, err
+	}
+
+	entities := make([]Entity, len(resp.Entities))
+	for i, data := range resp.Entities {
+		if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+			continue
+		}
+		var typename struct {
+			Typename string `json:"__typename"`
+		}
+		if err := json.Unmarshal(data, &typename); err != nil {
+			return nil, NewExecutionError(errors.Wrap(err, "decoding entity"))
+		}
+		entities[i] = Entity{Typename: typename.Typename, Data: data}
+	}
+	return entities, nil
+}
+
+// Decode unmarshals the entity data into v.
+func (e Entity) Decode(v interface{}) error {
+	if e.Data == nil {
+		return errors.New("entity was not resolved")
+	}
+	return json.Unmarshal(e.Data, v)
+}
@@ -0,0 +1,79 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestNewEntitiesRequest(t *testing.T) {
+	is := is.New(t)
+
+	req := NewEntitiesRequest(
+		[]Representation{NewRepresentation("Product", map[string]interface{}{"upc": "1"})},
+		map[string]string{"User": "{ id }", "Product": "upc name"},
+	)
+
+	is.Equal(req.Request().Query(), "query($representations:[_Any!]!){_entities(representations:$representations){__typename ... on Product {upc name} ... on User { id }}}")
+	is.Equal(req.Vars()["representations"], []Representation{{"__typename": "Product", "upc": "1"}})
+}
+
+func TestEntities(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables struct {
+				Representations []map[string]interface{}
+			}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		is.Equal(len(body.Variables.Representations), 3)
+		_, _ = io.WriteString(w, `{"data":{"_entities":[
+			{"__typename":"Product","upc":"1","name":"Solo"},
+			null,
+			{"__typename":"User","id":"u1"}
+		]}}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	entities, err := client.Entities(context.Background(), []Representation{
+		NewRepresentation("Product", map[string]interface{}{"upc": "1"}),
+		NewRepresentation("Product", map[string]interface{}{"upc": "2"}),
+		NewRepresentation("User", map[string]interface{}{"id": "u1"}),
+	}, map[string]string{"Product": "upc name", "User": "id"})
+	is.NoErr(err)
+	is.Equal(len(entities), 3)
+
+	var product struct {
+		UPC  string
+		Name string
+	}
+	is.Equal(entities[0].Typename, "Product")
+	is.NoErr(entities[0].Decode(&product))
+	is.Equal(product.Name, "Solo")
+	is.Equal(entities[1].Typename, "")
+	is.True(entities[1].Decode(&product) != nil)
+	is.Equal(entities[2].Typename, "User")
+}
+
+func TestRepresentationOf(t *testing.T) {
+	is := is.New(t)
+
+	type productKey struct {
+		UPC   string `json:"upc"`
+		Store int    `json:"store,omitempty"`
+	}
+	r, err := RepresentationOf("Product", productKey{UPC: "1"})
+	is.NoErr(err)
+	is.Equal(r, Representation{"__typename": "Product", "upc": "1"})
+	is.Equal(r.Typename(), "Product")
+
+	_, err = RepresentationOf("Product", "1")
+	is.True(err != nil)
+}
@@ -0,0 +1,181 @@
+package graphql
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// structField is a field of a struct as encoding/json encodes it.
+type structField struct {
+	name string
+	// index is the path of the field through embedded structs, as for
+	// reflect.Value.FieldByIndex.
+	index     []int
+	tagged    bool
+	omitEmpty bool
+	// quoted is set by the string option on fields of scalar types.
+	quoted bool
+}
+
+var structFieldsCache sync.Map // map[reflect.Type][]structField
+
+// jsonFields gets the fields encoding/json encodes for the struct type t,
+// in order: the exported fields and the ones promoted from embedded
+// structs, exported or not, under their JSON names. As in encoding/json,
+// of the fields sharing a name the shallowest wins, a tagged one when
+// there are several, and none when that leaves a tie.
+func jsonFields(t reflect.Type) []structField {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	fields, _ := structFieldsCache.LoadOrStore(t, typeFields(t))
+	return fields.([]structField)
+}
+
+// typeFields walks t and its embedded structs breadth first, following
+// typeFields of encoding/json.
+func typeFields(t reflect.Type) []structField {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	var (
+		fields  []structField
+		next    = []embedded{{typ: t}}
+		count   map[reflect.Type]int
+		visited = make(map[reflect.Type]bool)
+	)
+	nextCount := map[reflect.Type]int{}
+	for len(next) > 0 {
+		current := next
+		next = nil
+		count, nextCount = nextCount, map[reflect.Type]int{}
+		for _, f := range current {
+			if visited[f.typ] {
+				continue
+			}
+			visited[f.typ] = true
+			for i := 0; i < f.typ.NumField(); i++ {
+				sf := f.typ.Field(i)
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if sf.PkgPath != "" && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if sf.PkgPath != "" {
+					continue
+				}
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts := tag, ""
+				if i := strings.IndexByte(tag, ','); i >= 0 {
+					name, opts = tag[:i], tag[i:]
+				}
+				index := make([]int, len(f.index)+1)
+				copy(index, f.index)
+				index[len(f.index)] = i
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if name != "" || !sf.Anonymous || ft.Kind() != reflect.Struct {
+					field := structField{
+						name:      name,
+						index:     index,
+						tagged:    name != "",
+						omitEmpty: strings.Contains(opts, ",omitempty"),
+						quoted:    strings.Contains(opts, ",string") && isQuotable(ft.Kind()),
+					}
+					if name == "" {
+						field.name = sf.Name
+					}
+					fields = append(fields, field)
+					if count[f.typ] > 1 {
+						// The type is embedded several times at this depth,
+						// so its fields annihilate each other.
+						fields = append(fields, field)
+					}
+					continue
+				}
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					next = append(next, embedded{typ: ft, index: index})
+				}
+			}
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if len(a.index) != len(b.index) {
+			return len(a.index) < len(b.index)
+		}
+		if a.tagged != b.tagged {
+			return a.tagged
+		}
+		return indexLess(a.index, b.index)
+	})
+	dominant := fields[:0]
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+		group := fields[i:j]
+		if len(group) == 1 || len(group[0].index) < len(group[1].index) || group[0].tagged != group[1].tagged {
+			dominant = append(dominant, group[0])
+		}
+		i = j
+	}
+	sort.Slice(dominant, func(i, j int) bool {
+		return indexLess(dominant[i].index, dominant[j].index)
+	})
+	return dominant
+}
+
+func indexLess(a, b []int) bool {
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// isQuotable reports whether the string option applies to kind.
+func isQuotable(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// fieldByIndex gets the field of v at index, reporting false when it is
+// reached through a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// RunForEach executes the document of op once per variable set,
+// concurrently. Each set is merged over the variables of op. resps must
+// be a pointer to a slice, or nil to skip decoding; it is resized to hold
+// one response per set, in order. The returned errors are indexed like
+// varSets and nil for the sets that succeeded.
+//
+//	var resps []struct{ Merchant Merchant }
+//	errs := client.RunForEach(ctx, req, []map[string]interface{}{{"id": "1"}, {"id": "2"}}, &resps)
+func (c *Client) RunForEach(ctx context.Context, op Operation, varSets []map[string]interface{}, resps interface{}) []Error {
+	errs := make([]Error, len(varSets))
+	if len(op.Files()) > 0 {
+		return fill(errs, NewExecutionError(errors.New("cannot run operations with files for each variable set")))
+	}
+
+	var slice reflect.Value
+	if resps != nil {
+		v := reflect.ValueOf(resps)
+		if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+			return fill(errs, NewExecutionError(errors.Errorf("resps must be a pointer to a slice, got %T", resps)))
+		}
+		slice = reflect.MakeSlice(v.Elem().Type(), len(varSets), len(varSets))
+		v.Elem().Set(slice)
+	}
+
+	futures := make([]*Future, len(varSets))
+	for i, vars := range varSets {
+		each := withRequest(op, op.Request().clone())
+		for key, value := range vars {
+			each.Var(key, value)
+		}
+		var resp interface{}
+		if slice.IsValid() {
+			resp = slice.Index(i).Addr().Interface()
+		}
+		futures[i] = c.RunAsync(ctx, each, resp)
+	}
+	for i, f := range futures {
+		errs[i] = f.Wait()
+	}
+	return errs
+}
+
+// withRequest wraps req in an operation of the same kind as op.
+func withRequest(op Operation, req *Req) Operation {
+	if _, ok := op.(*Mutation); ok {
+		return &Mutation{Req: req}
+	}
+	return &Request{Req: req}
+}
+
+func fill(errs []Error, err Error) []Error {
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
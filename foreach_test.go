@@ -0,0 +1,66 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRunForEach(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables struct {
+				ID      string
+				Country string
+			}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		is.Equal(body.Variables.Country, "DE")
+		is.Equal(r.Header.Get("X-Tenant"), "acme")
+		if body.Variables.ID == "missing" {
+			_, _ = io.WriteString(w, `{"errors":[{"message":"not found"}]}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"data":{"merchant":{"id":"`+body.Variables.ID+`"}}}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	req := NewRequest(`query ($id: ID!, $country: String) { merchant(id: $id, country: $country) { id } }`)
+	req.Var("country", "DE")
+	req.Header("X-Tenant", "acme")
+
+	var resps []struct {
+		Merchant struct{ ID string }
+	}
+	errs := client.RunForEach(context.Background(), req, []map[string]interface{}{
+		{"id": "1"},
+		{"id": "missing"},
+		{"id": "3"},
+	}, &resps)
+
+	is.Equal(len(errs), 3)
+	is.NoErr(errs[0])
+	is.Equal(errs[1].Error(), "not found")
+	is.NoErr(errs[2])
+	is.Equal(resps[0].Merchant.ID, "1")
+	is.Equal(resps[2].Merchant.ID, "3")
+	is.Equal(req.Vars(), map[string]interface{}{"country": "DE"})
+}
+
+func TestRunForEachInvalidResps(t *testing.T) {
+	is := is.New(t)
+
+	client := NewClient("http://localhost")
+	var resp struct{}
+	errs := client.RunForEach(context.Background(), NewRequest("{ a }"), []map[string]interface{}{{}, {}}, &resp)
+	is.Equal(len(errs), 2)
+	is.Equal(errs[1].Error(), "resps must be a pointer to a slice, got *struct {}")
+}
@@ -0,0 +1,123 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// GeoPoint is a latitude/longitude pair scalar, in degrees.
+type GeoPoint struct {
+	Lat float64
+	Lng float64
+}
+
+// GeoFormat selects how GeoPoint variables are sent.
+type GeoFormat int
+
+const (
+	// GeoObject sends {"lat": 52.52, "lng": 13.40}. It is the default.
+	GeoObject GeoFormat = iota + 1
+	// GeoArray sends [52.52, 13.40], latitude first.
+	GeoArray
+	// GeoJSONPoint sends a GeoJSON point,
+	// {"type": "Point", "coordinates": [13.40, 52.52]}, longitude first.
+	GeoJSONPoint
+)
+
+// WithGeoFormat sets how GeoPoint variables are sent. GeoPoint decodes
+// from any of the formats.
+func WithGeoFormat(format GeoFormat) ClientOption {
+	return func(client *Client) {
+		client.geoFormat = format
+	}
+}
+
+// MarshalJSON encodes the point as a GeoObject.
+func (p GeoPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.wire(GeoObject))
+}
+
+// wire gets the value sent for the point in format.
+func (p GeoPoint) wire(format GeoFormat) interface{} {
+	switch format {
+	case GeoArray:
+		return [2]float64{p.Lat, p.Lng}
+	case GeoJSONPoint:
+		return geoJSONPoint{Type: "Point", Coordinates: []float64{p.Lng, p.Lat}}
+	}
+	return geoObject{Lat: &p.Lat, Lng: &p.Lng}
+}
+
+type geoObject struct {
+	Lat *float64 `json:"lat,omitempty"`
+	Lng *float64 `json:"lng,omitempty"`
+}
+
+type geoJSONPoint struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// UnmarshalJSON decodes any of the formats. Objects may also name their
+// fields latitude and longitude, or lon.
+func (p *GeoPoint) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	switch {
+	case bytes.Equal(b, []byte("null")):
+		return nil
+	case len(b) > 0 && b[0] == '[':
+		var pair [2]float64
+		if err := json.Unmarshal(b, &pair); err != nil {
+			return errors.Wrap(err, "geo point")
+		}
+		*p = GeoPoint{Lat: pair[0], Lng: pair[1]}
+		return nil
+	case len(b) > 0 && b[0] == '{':
+		var obj struct {
+			geoJSONPoint
+			Lat, Latitude       *float64
+			Lng, Lon, Longitude *float64
+		}
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return errors.Wrap(err, "geo point")
+		}
+		if obj.Type != "" {
+			if obj.Type != "Point" || len(obj.Coordinates) < 2 {
+				return errors.Errorf("geo point must be a GeoJSON Point, got %s", b)
+			}
+			*p = GeoPoint{Lat: obj.Coordinates[1], Lng: obj.Coordinates[0]}
+			return nil
+		}
+		lat, lng := firstFloat(obj.Lat, obj.Latitude), firstFloat(obj.Lng, obj.Lon, obj.Longitude)
+		if lat == nil || lng == nil {
+			return errors.Errorf("geo point has no latitude or longitude: %s", b)
+		}
+		*p = GeoPoint{Lat: *lat, Lng: *lng}
+		return nil
+	}
+	return errors.Errorf("geo point must be an object or an array, got %s", b)
+}
+
+func firstFloat(fs ...*float64) *float64 {
+	for _, f := range fs {
+		if f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+var geoPointType = reflect.TypeOf(GeoPoint{})
+
+// geoConverter sends GeoPoint values in format.
+func geoConverter(format GeoFormat) varConverter {
+	return func(v reflect.Value) (interface{}, bool) {
+		if v.Type() != geoPointType {
+			return nil, false
+		}
+		return v.Interface().(GeoPoint).wire(format), true
+	}
+}
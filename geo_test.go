@@ -0,0 +1,58 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestGeoPointUnmarshalJSON(t *testing.T) {
+	is := is.New(t)
+
+	want := GeoPoint{Lat: 52.52, Lng: 13.4}
+	for _, in := range []string{
+		`{"lat": 52.52, "lng": 13.4}`,
+		`{"latitude": 52.52, "longitude": 13.4}`,
+		`[52.52, 13.4]`,
+		`{"type": "Point", "coordinates": [13.4, 52.52]}`,
+	} {
+		var p GeoPoint
+		is.NoErr(json.Unmarshal([]byte(in), &p))
+		is.Equal(p, want)
+	}
+
+	var p GeoPoint
+	is.True(json.Unmarshal([]byte(`{"lat": 52.52}`), &p) != nil)
+	is.True(json.Unmarshal([]byte(`{"type": "LineString", "coordinates": [[1, 2]]}`), &p) != nil)
+	is.True(json.Unmarshal([]byte(`"52.52,13.4"`), &p) != nil)
+}
+
+func TestWithGeoFormat(t *testing.T) {
+	is := is.New(t)
+
+	var vars map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct{ Variables map[string]json.RawMessage }
+		is.NoErr(json.NewDecoder(r.Body).Decode(&payload))
+		vars = payload.Variables
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	req := NewRequest("query ($near: GeoPoint!) { merchants(near: $near) { id } }")
+	req.Var("near", GeoPoint{Lat: 52.52, Lng: 13.4})
+
+	for format, want := range map[GeoFormat]string{
+		0:            `{"lat":52.52,"lng":13.4}`,
+		GeoArray:     `[52.52,13.4]`,
+		GeoJSONPoint: `{"type":"Point","coordinates":[13.4,52.52]}`,
+	} {
+		is.NoErr(NewClient(srv.URL, WithGeoFormat(format)).Run(context.Background(), req, nil))
+		is.Equal(string(vars["near"]), want)
+	}
+}
@@ -0,0 +1,61 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/sumup/graphql/parser"
+)
+
+// UseGET sends queries as GET requests, with the query, operationName
+// and variables, encoded as JSON, in the URL as the GraphQL over HTTP
+// specification describes, so CDNs and HTTP caches can cache their
+// responses. Mutations are still posted, as are documents that are not
+// a single valid query and requests with files, since GET must not
+// change anything. Requests executed by document ID are sent with the ID
+// in place of the query, unless they are a Mutation or their document,
+// when known, is not a query.
+func UseGET() ClientOption {
+	return func(client *Client) {
+		client.useGET = true
+	}
+}
+
+// getParams gets the URL parameters req is sent with as a GET request, or
+// nil when it must be posted.
+func getParams(op Operation, req *Req) url.Values {
+	if _, mutation := op.(*Mutation); mutation || len(req.files) > 0 || len(findUploads(req.vars)) > 0 {
+		return nil
+	}
+	params := make(url.Values)
+	// The document is checked even when it is sent by ID, so persisted
+	// mutations are posted too.
+	if req.q != "" || req.documentID == "" {
+		doc, err := parser.Parse(req.q)
+		if err != nil {
+			return nil
+		}
+		def, err := doc.Operation("")
+		if err != nil || def.Type != parser.Query {
+			return nil
+		}
+		if req.documentID == "" {
+			params.Set("query", req.q)
+			if def.Name != "" {
+				params.Set("operationName", def.Name)
+			}
+		}
+	}
+	if req.documentID != "" {
+		params.Set(req.documentIDKey, req.documentID)
+	}
+	if len(req.vars) > 0 {
+		vars, err := json.Marshal(req.vars)
+		if err != nil {
+			// Posting reports the variable that cannot be encoded.
+			return nil
+		}
+		params.Set("variables", string(vars))
+	}
+	return params
+}
@@ -0,0 +1,77 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestUseGET(t *testing.T) {
+	is := is.New(t)
+
+	var (
+		method string
+		query  url.Values
+		body   string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, query = r.Method, r.URL.Query()
+		b, err := io.ReadAll(r.Body)
+		is.NoErr(err)
+		body = string(b)
+		_, _ = io.WriteString(w, `{"data":{"merchant":{"name":"Cafe"}}}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL+"?tenant=acme", UseGET())
+	ctx := context.Background()
+
+	req := NewRequest("query Merchant($id: ID!) { merchant(id: $id) { name } }")
+	req.Var("id", "m1")
+	var resp struct{ Merchant struct{ Name string } }
+	is.NoErr(client.Run(ctx, req, &resp))
+	is.Equal(resp.Merchant.Name, "Cafe")
+	is.Equal(method, http.MethodGet)
+	is.Equal(body, "")
+	is.Equal(query, url.Values{
+		"tenant":        {"acme"},
+		"query":         {"query Merchant($id: ID!) { merchant(id: $id) { name } }"},
+		"operationName": {"Merchant"},
+		"variables":     {`{"id":"m1"}`},
+	})
+
+	is.NoErr(client.Run(ctx, NewRequest("{ merchant { name } }"), nil))
+	is.Equal(method, http.MethodGet)
+	is.Equal(query.Get("operationName"), "")
+
+	// Mutations are posted whether they are a Mutation or a Request.
+	is.NoErr(client.Run(ctx, NewMutation("mutation { close { successful } }"), nil))
+	is.Equal(method, http.MethodPost)
+	is.NoErr(client.Run(ctx, NewRequest("mutation { close { successful } }"), nil))
+	is.Equal(method, http.MethodPost)
+	is.Equal(body, `{"query":"mutation { close { successful } }","variables":null}`+"\n")
+
+	persisted := NewRequest("")
+	persisted.Request().SetDocumentID(DocumentIDKey, "abc")
+	is.NoErr(client.Run(ctx, persisted, nil))
+	is.Equal(method, http.MethodGet)
+	is.Equal(query.Get(DocumentIDKey), "abc")
+
+	// Persisted queries are sent by ID, persisted mutations are posted.
+	persisted = NewRequest("query Merchant { merchant { name } }")
+	persisted.Request().SetDocumentID(DocumentIDKey, "abc")
+	is.NoErr(client.Run(ctx, persisted, nil))
+	is.Equal(method, http.MethodGet)
+	is.Equal(query.Get(DocumentIDKey), "abc")
+	is.Equal(query.Get("query"), "")
+	persisted = NewRequest("mutation Pay { pay { id } }")
+	persisted.Request().SetDocumentID(DocumentIDKey, "def")
+	is.NoErr(client.Run(ctx, persisted, nil))
+	is.Equal(method, http.MethodPost)
+	is.Equal(body, `{"documentId":"def","variables":null}`+"\n")
+}
@@ -0,0 +1,121 @@
+// Package gqlgentest mirrors the API of gqlgen's client package on top of
+// graphql.Client, so services owning both the server and its clients can
+// exercise resolvers in tests through this package's Operation and Error
+// model.
+//
+//	c := gqlgentest.New(handler.NewDefaultServer(schema))
+//	var resp struct {
+//	    Todo struct{ Text string }
+//	}
+//	c.MustPost(`query($id: ID!) { todo(id: $id) { text } }`, &resp, gqlgentest.Var("id", 1))
+package gqlgentest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sumup/graphql"
+)
+
+type (
+	// Client executes operations against an http.Handler in process.
+	Client struct {
+		handler http.Handler
+		opts    []Option
+	}
+
+	// Option modifies a single request before it is executed.
+	Option func(*Request)
+
+	// Request is the request being built for a Post call.
+	Request struct {
+		Operation graphql.Operation
+		Path      string
+		Context   context.Context
+		mutation  bool
+	}
+)
+
+// New creates a Client for handler. The options are applied to every
+// request made with it.
+func New(handler http.Handler, opts ...Option) *Client {
+	return &Client{
+		handler: handler,
+		opts:    opts,
+	}
+}
+
+// Var sets a variable on the request.
+func Var(name string, value interface{}) Option {
+	return func(r *Request) {
+		r.Operation.Var(name, value)
+	}
+}
+
+// AddHeader adds a header to the request.
+func AddHeader(key, value string) Option {
+	return func(r *Request) {
+		r.Operation.Header(key, value)
+	}
+}
+
+// Path sets the URL path the request is served on, "/query" by default.
+func Path(path string) Option {
+	return func(r *Request) {
+		r.Path = path
+	}
+}
+
+// WithContext sets the context the request is executed with.
+func WithContext(ctx context.Context) Option {
+	return func(r *Request) {
+		r.Context = ctx
+	}
+}
+
+// AsMutation sends the operation as a graphql.Mutation, decoding the
+// successful/messages/result payload convention.
+func AsMutation() Option {
+	return func(r *Request) {
+		r.mutation = true
+	}
+}
+
+// Post executes query and decodes the response data into response.
+func (c *Client) Post(query string, response interface{}, options ...Option) graphql.Error {
+	r := &Request{
+		Operation: graphql.NewRequest(query),
+		Path:      "/query",
+		Context:   context.Background(),
+	}
+	// Copy the client's options so concurrent calls do not append to a
+	// shared backing array.
+	all := make([]Option, 0, len(c.opts)+len(options))
+	all = append(append(all, c.opts...), options...)
+	for _, option := range all {
+		if option == nil {
+			continue
+		}
+		option(r)
+	}
+	if r.mutation {
+		r.Operation = toMutation(r.Operation)
+	}
+
+	client := graphql.NewClient(r.Path, graphql.WithHandler(c.handler))
+	return client.Run(r.Context, r.Operation, response)
+}
+
+// MustPost is like Post but panics when the request fails.
+func (c *Client) MustPost(query string, response interface{}, options ...Option) {
+	if err := c.Post(query, response, options...); err != nil {
+		panic(err)
+	}
+}
+
+// toMutation sends the request built for op as a mutation, keeping its
+// variables, headers, header overrides and removals, tags, files and
+// document ID.
+func toMutation(op graphql.Operation) graphql.Operation {
+	return &graphql.Mutation{Req: op.Request()}
+}
@@ -0,0 +1,104 @@
+package gqlgentest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/sumup/graphql"
+)
+
+func TestPost(t *testing.T) {
+	is := is.New(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/graphql")
+		is.Equal(r.Header.Get("Authorization"), "Bearer token")
+		var body struct {
+			Variables map[string]interface{}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		is.Equal(body.Variables["id"], float64(1))
+		_, _ = io.WriteString(w, `{"data":{"todo":{"text":"write tests"}}}`)
+	})
+
+	c := New(handler, Path("/graphql"), AddHeader("Authorization", "Bearer token"))
+
+	var resp struct {
+		Todo struct {
+			Text string
+		}
+	}
+	c.MustPost(`query($id: ID!) { todo(id: $id) { text } }`, &resp, Var("id", 1))
+	is.Equal(resp.Todo.Text, "write tests")
+}
+
+func TestPostMutationError(t *testing.T) {
+	is := is.New(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"createTodo":{"successful":false,"messages":[{"code":"invalid","message":"text is required"}],"result":null}}}`)
+	})
+
+	c := New(handler)
+
+	var resp map[string]interface{}
+	err := c.Post(`mutation { createTodo(text: "") { successful } }`, &resp, AsMutation())
+	is.True(err != nil)
+	is.Equal(err.Code(), "invalid")
+	is.Equal(err.Error(), "text is required")
+}
+
+func TestPostMutationKeepsRequest(t *testing.T) {
+	is := is.New(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("Content-Type"), "application/graphql-response+json")
+		is.Equal(r.Header.Get("Accept"), "")
+		var body map[string]interface{}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		is.Equal(body["documentId"], "abc123")
+		is.Equal(body["variables"], map[string]interface{}{"text": "hi"})
+		_, _ = io.WriteString(w, `{"data":{"createTodo":{"successful":true,"messages":[],"result":{"id":"1"}}}}`)
+	})
+
+	c := New(handler)
+
+	var resp map[string]interface{}
+	err := c.Post(`mutation($text: String!) { createTodo(text: $text) { successful } }`, &resp, AsMutation(),
+		Var("text", "hi"),
+		func(r *Request) {
+			req := r.Operation.Request()
+			req.SetHeader("Content-Type", "application/graphql-response+json")
+			req.DelHeader("Accept")
+			req.SetDocumentID(graphql.DocumentIDKey, "abc123")
+		})
+	is.NoErr(err)
+}
+
+func TestPostConcurrentOptions(t *testing.T) {
+	is := is.New(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]interface{}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		_, _ = io.WriteString(w, `{"data":{"id":"`+body.Variables["id"].(string)+`"}}`)
+	})
+
+	opts := make([]Option, 1, 8)
+	opts[0] = AddHeader("X-Test", "1")
+	c := New(handler, opts...)
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"a", "b", "c", "d"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			var resp struct{ ID string }
+			is.NoErr(c.Post(`query($id: ID!) { id(id: $id) }`, &resp, Var("id", id)))
+			is.Equal(resp.ID, id)
+		}(id)
+	}
+	wg.Wait()
+}
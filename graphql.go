@@ -34,13 +34,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
+
+	"github.com/sumup/graphql/parser"
 )
 
 type (
@@ -53,12 +61,133 @@ type (
 		// closeReq will close the request body immediately allowing for reuse of client
 		closeReq bool
 
+		// header is sent with every request made by the client.
+		header http.Header
+
+		// translateErr is applied to every error returned by Run.
+		translateErr func(Error) Error
+
+		// queryParams are added to the URL of every request.
+		queryParams url.Values
+
+		// strictContentType rejects responses that are not JSON by
+		// their Content-Type.
+		strictContentType bool
+
+		// numericStrings sends arbitrary-precision numbers as strings.
+		numericStrings bool
+
+		// uuidArrays sends [16]byte values as UUID strings.
+		uuidArrays bool
+
+		// moneyFormat sends Money variables in a format when set.
+		moneyFormat MoneyFormat
+
+		// geoFormat sends GeoPoint variables in a format when set.
+		geoFormat GeoFormat
+
+		// tee is called with the raw body of every response, cut at
+		// teeBytes.
+		tee      func(op Operation, status int, body []byte)
+		teeBytes int
+
+		// limiter hands out a slot per call in flight when the
+		// concurrency is limited.
+		limiter     *limiter
+		limitPolicy LimitPolicy
+
+		// reportMissing is called with the fields of responses missing
+		// from their data when set.
+		reportMissing func(op Operation, missing []string)
+
+		// useGET sends queries as GET requests.
+		useGET bool
+
+		// graphqlBody posts bare documents as application/graphql.
+		graphqlBody bool
+
+		// operationName sends the operationName field in JSON payloads.
+		operationName bool
+
+		// compression is the content coding of request bodies of at
+		// least compressionMin bytes.
+		compression    string
+		compressionMin int
+
+		// decompression lists the content codings of responses the
+		// client accepts and decodes itself.
+		decompression []string
+
+		// unixSocket is the Unix domain socket of a unix:// endpoint and
+		// dialsSocket whether httpClient connects to it.
+		unixSocket  string
+		dialsSocket bool
+
+		// stats counts the calls for Stats. Clients of a ClientFactory
+		// share it.
+		stats *stats
+
+		// enums maps the case of enum types.
+		enums map[reflect.Type]enumMapping
+
+		// timeFormat encodes the times of variables when set.
+		timeFormat TimeFormat
+
+		// nullData decides how responses without data nor errors are
+		// reported.
+		nullData NullDataPolicy
+
+		// timeout bounds every call when set.
+		timeout time.Duration
+
+		// logCategories selects the information passed to Log.
+		logCategories LogCategory
+
+		// redactedHeaders are masked in logged headers, nil disables
+		// redaction.
+		redactedHeaders map[string]struct{}
+
+		// logFormatter formats the lines passed to Log.
+		logFormatter Formatter
+
 		// Log is called with various debug information.
 		// To log to standard out, use:
 		//  client.Log = func(s string) { log.Println(s) }
+		// While it is nil, the default, nothing is logged nor traced, so
+		// the logs cost nothing.
 		Log func(s string)
 	}
 
+	// GraphClient executes operations. *Client implements it over HTTP;
+	// packages built on top of this one accept a GraphClient so other
+	// transports and fakes can be used in its place.
+	GraphClient interface {
+		Run(ctx context.Context, op Operation, resp interface{}) Error
+	}
+
+	// BatchClient is a GraphClient that also sends several operations in
+	// one request, see Client.DoBatch.
+	BatchClient interface {
+		GraphClient
+		DoBatch(ctx context.Context, ops []Operation, resps []interface{}) []Error
+	}
+
+	// SubscribeClient is a GraphClient that also streams the events of
+	// subscriptions, see Client.Subscribe.
+	SubscribeClient interface {
+		GraphClient
+		Subscribe(ctx context.Context, op Operation, resp interface{}, opts ...SubscribeOption) (<-chan SubscriptionEvent, Error)
+	}
+
+	// GraphTransport covers every way *Client executes operations: Run,
+	// DoBatch and Subscribe. Other transports and fakes implement it to
+	// stand in for *Client entirely; libraries needing only part of it
+	// accept a GraphClient, BatchClient or SubscribeClient instead.
+	GraphTransport interface {
+		BatchClient
+		SubscribeClient
+	}
+
 	// CustomHttpClient allows a custom http.Client to be used other than the default one provided by golang.
 	CustomHttpClient interface {
 		Do(*http.Request) (*http.Response, error)
@@ -84,22 +213,111 @@ type (
 	}
 )
 
+// Media types of GraphQL responses, see WithAccept.
+const (
+	MediaTypeJSON            = "application/json"
+	MediaTypeGraphQLResponse = "application/graphql-response+json"
+)
+
+var (
+	_ GraphClient     = (*Client)(nil)
+	_ BatchClient     = (*Client)(nil)
+	_ SubscribeClient = (*Client)(nil)
+	_ GraphTransport  = (*Client)(nil)
+)
+
 // NewClient makes a new Client capable of making GraphQL requests.
 // In case no option for http.Client is provided the default one is used in place.
+//
+// The endpoint may be a Unix domain socket with the HTTP path in the path
+// query parameter, e.g. unix:///var/run/gateway.sock?path=/graphql. The
+// http.Client then dials the socket: a provided one is copied with a copy
+// of its *http.Transport that does, and NewClientE rejects clients with
+// other transports.
 func NewClient(endpoint string, opts ...ClientOption) *Client {
 	c := &Client{
-		endpoint: endpoint,
-		Log:      func(string) {},
+		endpoint:      endpoint,
+		header:        make(http.Header),
+		stats:         &stats{},
+		logCategories: LogAll,
+		logFormatter:  TextFormatter{},
+		redactedHeaders: map[string]struct{}{
+			"Authorization":       {},
+			"Proxy-Authorization": {},
+			"Cookie":              {},
+			"Set-Cookie":          {},
+		},
 	}
 	for _, optionFunc := range opts {
 		optionFunc(c)
 	}
+	if socket, endpoint, ok := unixEndpoint(c.endpoint); ok {
+		c.endpoint = endpoint
+		c.unixSocket = socket
+		c.httpClient, c.dialsSocket = dialUnix(c.httpClient, socket)
+	}
 	if c.httpClient == nil {
 		c.httpClient = http.DefaultClient
 	}
 	return c
 }
 
+// NewClientE is like NewClient but validates the endpoint and options,
+// so misconfiguration is reported at construction rather than by the
+// first request. UseMultipartForm excludes UseGET and UseGraphQLBody,
+// which encode requests differently. The endpoint must be an absolute http or https URL or a
+// unix socket, unless requests are served by WithHandler.
+func NewClientE(endpoint string, opts ...ClientOption) (*Client, error) {
+	c := NewClient(endpoint, opts...)
+	if err := c.validate(); err != nil {
+		return nil, errors.Wrap(err, "graphql")
+	}
+	return c, nil
+}
+
+func (c *Client) validate() error {
+	u, err := url.Parse(withoutPlaceholders(c.endpoint))
+	if err != nil {
+		return errors.Wrap(err, "invalid endpoint")
+	}
+	if c.unixSocket != "" && !c.dialsSocket {
+		return errors.Errorf("endpoint %q needs an *http.Client with an *http.Transport to dial the socket", "unix://"+c.unixSocket)
+	}
+	if _, inProcess := c.httpClient.(*handlerClient); !inProcess {
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.Errorf("endpoint %q must use http or https", c.endpoint)
+		}
+		if u.Host == "" {
+			return errors.Errorf("endpoint %q has no host", c.endpoint)
+		}
+	}
+	if c.timeout < 0 {
+		return errors.Errorf("timeout %s must not be negative", c.timeout)
+	}
+	if c.useMultipartForm && c.useGET {
+		return errors.New("UseGET cannot be combined with UseMultipartForm")
+	}
+	if c.useMultipartForm && c.graphqlBody {
+		return errors.New("UseGraphQLBody cannot be combined with UseMultipartForm")
+	}
+	if c.compression != "" && c.compression != CompressionGzip && c.compression != CompressionDeflate {
+		return errors.Errorf("unsupported request compression %q", c.compression)
+	}
+	for _, encoding := range c.decompression {
+		if _, ok := lookupDecompressor(encoding); !ok {
+			return errors.Errorf("no decompressor registered for %q", encoding)
+		}
+	}
+	for key, values := range c.header {
+		for _, value := range values {
+			if strings.ContainsAny(key, "\r\n: ") || strings.ContainsAny(value, "\r\n") {
+				return errors.Errorf("invalid header %q", key)
+			}
+		}
+	}
+	return nil
+}
+
 // WithHTTPClient specifies the underlying http.Client to use when
 // making requests.
 //  NewClient(endpoint, WithHTTPClient(specificHTTPClient))
@@ -124,8 +342,128 @@ func ImmediatelyCloseReqBody() ClientOption {
 	}
 }
 
-func (c *Client) logf(format string, args ...interface{}) {
-	c.Log(fmt.Sprintf(format, args...))
+// WithHeader sets a header sent with every request made by the client.
+// The values a request sets for the same key, in its Header or with
+// SetHeader, replace it.
+func WithHeader(key, value string) ClientOption {
+	return func(client *Client) {
+		client.header.Set(key, value)
+	}
+}
+
+// WithOperationName sends the name of the operation in the
+// operationName field of JSON payloads, as some gateways require to
+// route or trace them. Documents that are invalid or have several
+// operations are sent without it, for the server to report.
+func WithOperationName() ClientOption {
+	return func(client *Client) {
+		client.operationName = true
+	}
+}
+
+// payload gets the JSON payload of req as c sends it.
+func (c *Client) payload(req *Req) payload {
+	p := req.payload()
+	if c.operationName && req.documentID == "" {
+		p.OperationName, _ = parser.OperationName(req.q, "")
+	}
+	return p
+}
+
+// WithQueryParams adds params to the URL of every request, e.g. API keys
+// or version pins required by some gateways. They replace parameters of
+// the same name in the endpoint, and middleware of the http.Client such
+// as http.SetGraphqlOperation adds to them. They are not logged.
+func WithQueryParams(params url.Values) ClientOption {
+	return func(client *Client) {
+		if client.queryParams == nil {
+			client.queryParams = make(url.Values)
+		}
+		for key, values := range params {
+			client.queryParams[key] = append([]string(nil), values...)
+		}
+	}
+}
+
+// WithStrictContentType checks that the Content-Type of responses is a
+// JSON media type, such as application/json or
+// application/graphql-response+json, before decoding them. Other
+// responses fail with an *ExecutionError caused by
+// ErrUnexpectedContentType, which catches misrouted requests early.
+func WithStrictContentType() ClientOption {
+	return func(client *Client) {
+		client.strictContentType = true
+	}
+}
+
+// NullDataPolicy decides how Run reports a response whose data is null
+// or missing while it has no errors either.
+type NullDataPolicy int
+
+const (
+	// NullDataIgnore leaves the response object untouched, as for an
+	// empty result. It is the default.
+	NullDataIgnore NullDataPolicy = iota
+	// NullDataError returns an *ExecutionError caused by ErrNullData.
+	NullDataError
+)
+
+// WithNullData sets how responses with null data and no errors are
+// reported, see NullDataPolicy.
+func WithNullData(policy NullDataPolicy) ClientOption {
+	return func(client *Client) {
+		client.nullData = policy
+	}
+}
+
+// WithAccept sets the media types accepted in responses, e.g.
+// MediaTypeGraphQLResponse to follow the GraphQL over HTTP specification.
+// Responses of that type with a status other than 2xx that carry
+// GraphQL errors are reported as *GraphQLError rather than *RequestError.
+func WithAccept(accept string) ClientOption {
+	return func(client *Client) {
+		client.header.Set("Accept", accept)
+	}
+}
+
+// WithClientAwareness identifies the client to Apollo Studio and Router
+// through the apollographql-client-name and apollographql-client-version
+// headers. Use WithHeader for the equivalents of other gateways.
+func WithClientAwareness(name, version string) ClientOption {
+	return func(client *Client) {
+		client.header.Set("apollographql-client-name", name)
+		if version != "" {
+			client.header.Set("apollographql-client-version", version)
+		}
+	}
+}
+
+// WithErrorTranslator registers a function applied to every error before
+// Run returns it, e.g. to map GraphQL error codes to domain errors or
+// localized messages in one place. Returning nil keeps the original error.
+func WithErrorTranslator(translate func(Error) Error) ClientOption {
+	return func(client *Client) {
+		client.translateErr = translate
+	}
+}
+
+// WithTimeout bounds every call to Run and RunRaw, including reading the
+// response, even when the context passed has no deadline. An earlier
+// deadline of the context still applies.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(client *Client) {
+		client.timeout = timeout
+	}
+}
+
+// WithLogger sets the function called with debug information, the same
+// as setting Client.Log.
+//
+//	NewClient(endpoint, WithLogger(func(s string) { log.Println(s) }))
+func WithLogger(log func(string)) ClientOption {
+	return func(client *Client) {
+		client.Log = log
+	}
 }
 
 // Run executes the query and unmarshals the response from the data field
@@ -134,61 +472,292 @@ func (c *Client) logf(format string, args ...interface{}) {
 // If the request fails or the server returns an error, the first error
 // will be returned.
 func (c *Client) Run(ctx context.Context, op Operation, resp interface{}) Error {
-	select {
-	case <-ctx.Done():
-		return NewExecutionError(ctx.Err())
-	default:
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	err := c.run(ctx, op, resp)
+	c.countError(err)
+	return c.translate(err)
+}
+
+// withTimeout applies the timeout of the client to ctx.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// translate applies the error translator of the client to err.
+func (c *Client) translate(err Error) Error {
+	if err == nil || c.translateErr == nil {
+		return err
+	}
+	if translated := c.translateErr(err); translated != nil {
+		return translated
+	}
+	return err
+}
+
+func (c *Client) run(ctx context.Context, op Operation, resp interface{}) Error {
+	release, err := c.acquire(ctx, op)
+	if err != nil {
+		return err
+	}
+	defer release()
+	res, err := c.send(ctx, op)
+	if err != nil {
+		return err
+	}
+	if !successful(res) {
+		return c.decodeErrorResponse(ctx, op, res, resp)
+	}
+	if c.strictContentType {
+		contentType := res.Header.Get("Content-Type")
+		if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != MediaTypeJSON && !strings.HasSuffix(mediaType, "+json") {
+			if err := c.teeUnread(ctx, op, res); err != nil {
+				return err
+			}
+			if res.Body != nil {
+				res.Body.Close()
+			}
+			return NewExecutionError(errors.Wrapf(ErrUnexpectedContentType, "%s with %q", res.Status, contentType))
+		}
+	}
+	body, err := c.readBody(ctx, res)
+	if err != nil {
+		return err
 	}
-	if len(op.Files()) > 0 && !c.useMultipartForm {
-		return NewExecutionError(errors.New("cannot send files with PostFields option"))
+	c.teeResponse(op, res, body)
+	body, cerr := toUTF8(res.Header.Get("Content-Type"), body)
+	if cerr != nil {
+		return NewExecutionError(errors.Wrap(cerr, "decoding response"))
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return NewExecutionError(errors.Wrapf(ErrEmptyResponse, "%s with %q", res.Status, res.Header.Get("Content-Type")))
+	}
+	if trimmed := bytes.TrimSpace(body); trimmed[0] != '{' {
+		return NewExecutionError(errors.Wrapf(ErrNotJSON, "%s with %q: %s", res.Status, res.Header.Get("Content-Type"), excerpt(trimmed)))
 	}
 	if c.useMultipartForm {
-		return c.runWithPostFields(ctx, op, resp)
+		err = decodePostFields(res, body, resp)
+	} else {
+		err = decodeJSON(op, res, body, resp)
+	}
+	if _, isExecErr := err.(*ExecutionError); !isExecErr && resp != nil && len(c.enums) > 0 {
+		c.convertEnums(reflect.ValueOf(resp))
+	}
+	if err == nil && c.reportMissing != nil && resp != nil {
+		c.checkCompleteness(op, body, resp)
 	}
-	return c.runWithJSON(ctx, op, resp)
+	if err == nil && c.nullData == NullDataError && hasNullData(body) {
+		return NewExecutionError(ErrNullData)
+	}
+	return err
+}
+
+// checkVars finds the first variable, by name, that cannot be encoded to
+// JSON, such as channels, functions or NaN floats.
+func checkVars(vars map[string]interface{}) *VariableError {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := json.Marshal(vars[name]); err != nil {
+			return &VariableError{Name: name, Err: err}
+		}
+	}
+	return nil
 }
 
-func (c *Client) runWithJSON(ctx context.Context, op Operation, resp interface{}) Error {
-	req := op.Request()
+// excerptSize is the number of bytes of a body quoted in errors.
+const excerptSize = 200
 
-	var requestBody bytes.Buffer
-	requestBodyObj := struct {
-		Query     string                 `json:"query"`
-		Variables map[string]interface{} `json:"variables"`
-	}{
-		Query:     req.q,
-		Variables: req.vars,
+// excerpt quotes the beginning of body for error messages.
+func excerpt(body []byte) string {
+	if len(body) <= excerptSize {
+		return strconv.Quote(string(body))
 	}
-	if err := json.NewEncoder(&requestBody).Encode(requestBodyObj); err != nil {
-		return NewExecutionError(errors.Wrap(err, "encode body"))
+	return strconv.Quote(string(body[:excerptSize])) + "..."
+}
+
+// hasNullData reports whether the data of the response body is null or
+// missing.
+func hasNullData(body []byte) bool {
+	var gr struct {
+		Data json.RawMessage `json:"data"`
 	}
-	r, err := http.NewRequest(http.MethodPost, c.endpoint, &requestBody)
+	if err := json.Unmarshal(body, &gr); err != nil {
+		return false
+	}
+	return len(gr.Data) == 0 || bytes.Equal(gr.Data, []byte("null"))
+}
+
+// RunRaw executes the operation and returns the response body without
+// decoding it, for proxying responses or custom parsing. GraphQL errors
+// in the body are not reported. When the status is not 2xx the body is
+// returned along with a *RequestError.
+func (c *Client) RunRaw(ctx context.Context, op Operation) ([]byte, *http.Response, Error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	body, res, err := c.runRaw(ctx, op)
+	c.countError(err)
 	if err != nil {
-		return NewExecutionError(err)
+		return body, res, c.translate(err)
 	}
-	r.Close = c.closeReq
-	r.Header.Set("Content-Type", "application/json; charset=utf-8")
-	r.Header.Set("Accept", "application/json; charset=utf-8")
-	for key, values := range req.Header {
-		for _, value := range values {
-			r.Header.Add(key, value)
-		}
+	return body, res, nil
+}
+
+func (c *Client) runRaw(ctx context.Context, op Operation) ([]byte, *http.Response, Error) {
+	release, err := c.acquire(ctx, op)
+	if err != nil {
+		return nil, nil, err
 	}
-	c.logf(">> headers: %v", r.Header)
-	r = r.WithContext(ctx)
-	res, err := c.httpClient.Do(r)
+	defer release()
+	res, err := c.send(ctx, op)
 	if err != nil {
-		return NewExecutionError(err)
+		return nil, nil, err
 	}
-	if res.StatusCode != http.StatusOK {
-		return NewRequestError(res)
+	body, err := c.readBody(ctx, res)
+	if err != nil {
+		return nil, res, err
+	}
+	c.teeResponse(op, res, body)
+	if !successful(res) {
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		return body, res, NewRequestError(res)
+	}
+	return body, res, nil
+}
+
+// send encodes op for the configured content type and sends it. The body
+// of the response is left to the caller.
+func (c *Client) send(ctx context.Context, op Operation) (*http.Response, Error) {
+	select {
+	case <-ctx.Done():
+		return nil, NewExecutionError(ctx.Err())
+	default:
+	}
+	if (len(op.Files()) > 0 || len(findUploads(op.Vars())) > 0) && !c.useMultipartForm {
+		return nil, NewExecutionError(errors.New("cannot send files with PostFields option"))
+	}
+
+	req := c.withConvertedVars(op.Request())
+	var (
+		body        []byte
+		contentType string
+		params      url.Values
+		err         Error
+	)
+	if c.useGET && !c.useMultipartForm {
+		params = getParams(op, req)
+	}
+	switch {
+	case params != nil:
+	case c.useMultipartForm:
+		body, contentType, err = c.encodePostFields(req)
+	case c.graphqlBody && req.documentID == "":
+		body, contentType, params, err = encodeGraphQL(req)
+	default:
+		body, contentType, err = encodeJSON(req, c.payload(req))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.do(ctx, req, body, contentType, params)
+}
+
+// do sends the encoded body of req with the URL parameters params, as a
+// GET request when there is no body, and logs the response. Its body is
+// left to the caller.
+func (c *Client) do(ctx context.Context, req *Req, body []byte, contentType string, params url.Values) (*http.Response, Error) {
+	var trace timings
+	if c.logs(LogTiming) {
+		ctx = trace.trace(ctx)
+	}
+	r, rerr := c.newRequest(ctx, req, body, contentType, params)
+	if rerr != nil {
+		return nil, NewExecutionError(rerr)
+	}
+	c.countRequest(ctx, int(r.ContentLength))
+	start := time.Now()
+	res, rerr := c.httpClient.Do(r)
+	if rerr != nil {
+		if c.logs(LogTiming) {
+			elapsed := time.Since(start)
+			c.logf(LogTiming, append([]LogField{{"duration_ms", milliseconds(elapsed)}, {"error", rerr.Error()}}, trace.fields()...),
+				"<< failed after %s%s", elapsed, &trace)
+		}
+		return nil, NewExecutionError(rerr)
+	}
+	if c.logs(LogTiming) {
+		elapsed := time.Since(start)
+		c.logf(LogTiming, append([]LogField{{"duration_ms", milliseconds(elapsed)}}, trace.fields()...),
+			"<< response after %s%s", elapsed, &trace)
+	}
+	if c.logs(LogResponse) {
+		c.logf(LogResponse, []LogField{{"status", res.StatusCode}}, "<< %s", res.Status)
+		headers := c.redact(res.Header)
+		c.logf(LogResponse, []LogField{{"headers", headers}}, "<< headers: %v", headers)
+	}
+	captureResponseHeaders(ctx, res)
+	c.countResponse(res)
+	if len(c.decompression) > 0 {
+		if err := decompressResponse(res); err != nil {
+			return nil, NewExecutionError(err)
+		}
+	}
+	return res, nil
+}
+
+// readBody reads and closes the body of res. The body is closed early
+// when ctx is done, so a slow or streaming server cannot block the call
+// past its deadline whatever the http.Client in use; the error of ctx is
+// returned then.
+func (c *Client) readBody(ctx context.Context, res *http.Response) ([]byte, Error) {
+	if res.Body == nil {
+		c.logf(LogResponse, []LogField{{"bytes", 0}}, "<< %d bytes", 0)
+		return nil, nil
 	}
 	defer res.Body.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			res.Body.Close()
+		case <-done:
+		}
+	}()
 	var buf bytes.Buffer
 	if _, err := io.Copy(&buf, res.Body); err != nil {
-		return NewExecutionError(errors.Wrap(err, "reading body"))
+		if ctx.Err() != nil {
+			return nil, NewExecutionError(ctx.Err())
+		}
+		return nil, NewExecutionError(errors.Wrap(err, "reading body"))
+	}
+	c.countReceived(buf.Len())
+	c.logf(LogResponse, []LogField{{"bytes", buf.Len()}}, "<< %d bytes", buf.Len())
+	if c.logs(LogBody) {
+		c.logf(LogBody, []LogField{{"body", buf.String()}}, "<< %s", buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeJSON(req *Req, p payload) ([]byte, string, Error) {
+	var requestBody bytes.Buffer
+	if err := json.NewEncoder(&requestBody).Encode(p); err != nil {
+		if verr := checkVars(req.vars); verr != nil {
+			return nil, "", NewExecutionError(verr)
+		}
+		return nil, "", NewExecutionError(errors.Wrap(err, "encode body"))
 	}
-	c.logf("<< %s", buf.String())
+	return requestBody.Bytes(), "application/json; charset=utf-8", nil
+}
+
+func decodeJSON(op Operation, res *http.Response, body []byte, resp interface{}) Error {
+	buf := bytes.NewReader(body)
 
 	var gr *graphResponse
 	switch op.(type) {
@@ -197,7 +766,7 @@ func (c *Client) runWithJSON(ctx context.Context, op Operation, resp interface{}
 			Data map[string]graphMutationPayload
 		}
 
-		if err := json.NewDecoder(&buf).Decode(&results); err != nil {
+		if err := json.NewDecoder(buf).Decode(&results); err != nil {
 			return NewExecutionError(errors.Wrap(err, "decoding response"))
 		}
 		gr = &graphResponse{}
@@ -216,7 +785,13 @@ func (c *Client) runWithJSON(ctx context.Context, op Operation, resp interface{}
 
 				gr.Errors = append(gr.Errors, errors...)
 			} else {
-				err := mapstructure.Decode(results.Data, &resp)
+				dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+					DecodeHook: mutationDecodeHook,
+					Result:     &resp,
+				})
+				if err == nil {
+					err = dec.Decode(results.Data)
+				}
 				if err != nil {
 					return NewExecutionError(errors.Wrap(err, "decoding response"))
 				}
@@ -227,7 +802,7 @@ func (c *Client) runWithJSON(ctx context.Context, op Operation, resp interface{}
 
 	default:
 		gr = &graphResponse{Data: resp}
-		if err := json.NewDecoder(&buf).Decode(&gr); err != nil {
+		if err := json.NewDecoder(buf).Decode(&gr); err != nil {
 			return NewExecutionError(errors.Wrap(err, "decoding response"))
 		}
 	}
@@ -238,75 +813,213 @@ func (c *Client) runWithJSON(ctx context.Context, op Operation, resp interface{}
 	return nil
 }
 
-func (c *Client) runWithPostFields(ctx context.Context, op Operation, resp interface{}) Error {
-	req := op.Request()
+func (c *Client) encodePostFields(req *Req) ([]byte, string, Error) {
 	var requestBody bytes.Buffer
 	writer := multipart.NewWriter(&requestBody)
-	if err := writer.WriteField("query", req.q); err != nil {
-		return NewExecutionError(errors.Wrap(err, "write query field"))
+	if req.documentID != "" {
+		if err := writer.WriteField(req.documentIDKey, req.documentID); err != nil {
+			return nil, "", NewExecutionError(errors.Wrap(err, "write document id field"))
+		}
+	} else if err := writer.WriteField("query", req.q); err != nil {
+		return nil, "", NewExecutionError(errors.Wrap(err, "write query field"))
 	}
 	var variablesBuf bytes.Buffer
 	if len(req.vars) > 0 {
 		variablesField, err := writer.CreateFormField("variables")
 		if err != nil {
-			return NewExecutionError(errors.Wrap(err, "create variables field"))
+			return nil, "", NewExecutionError(errors.Wrap(err, "create variables field"))
 		}
 		if err := json.NewEncoder(io.MultiWriter(variablesField, &variablesBuf)).Encode(req.vars); err != nil {
-			return NewExecutionError(errors.Wrap(err, "encode variables"))
+			if verr := checkVars(req.vars); verr != nil {
+				return nil, "", NewExecutionError(verr)
+			}
+			return nil, "", NewExecutionError(errors.Wrap(err, "encode variables"))
+		}
+	}
+	uploads := findUploads(req.vars)
+	if len(uploads) > 0 {
+		if err := writeUploads(writer, uploads); err != nil {
+			return nil, "", NewExecutionError(err)
 		}
 	}
 	for i := range req.files {
 		part, err := writer.CreateFormFile(req.files[i].Field, req.files[i].Name)
 		if err != nil {
-			return NewExecutionError(errors.Wrap(err, "create form file"))
+			return nil, "", NewExecutionError(errors.Wrap(err, "create form file"))
 		}
 		if _, err := io.Copy(part, req.files[i].R); err != nil {
-			return NewExecutionError(errors.Wrap(err, "preparing file"))
+			return nil, "", NewExecutionError(errors.Wrap(err, "preparing file"))
 		}
 	}
 	if err := writer.Close(); err != nil {
-		return NewExecutionError(errors.Wrap(err, "close writer"))
+		return nil, "", NewExecutionError(errors.Wrap(err, "close writer"))
 	}
-	c.logf(">> variables: %s", variablesBuf.String())
-	c.logf(">> files: %d", len(req.files))
-	c.logf(">> query: %s", req.q)
+	if c.logs(LogBody) {
+		// Redacted variables are masked in the logs only.
+		if len(req.redacted) > 0 && len(req.vars) > 0 {
+			variablesBuf.Reset()
+			_ = json.NewEncoder(&variablesBuf).Encode(req.redactedVars())
+		}
+		c.logf(LogBody, []LogField{{"variables", variablesBuf.String()}}, ">> variables: %s", variablesBuf.String())
+	}
+	c.logf(LogRequest, []LogField{{"files", len(req.files) + len(uploads)}}, ">> files: %d", len(req.files)+len(uploads))
+	c.logf(LogBody, []LogField{{"query", req.q}}, ">> query: %s", req.q)
+	return requestBody.Bytes(), writer.FormDataContentType(), nil
+}
+
+func decodePostFields(res *http.Response, body []byte, resp interface{}) Error {
 	gr := &graphResponse{
 		Data: resp,
 	}
-	r, err := http.NewRequest(http.MethodPost, c.endpoint, &requestBody)
-	if err != nil {
-		return NewExecutionError(err)
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&gr); err != nil {
+		return NewExecutionError(errors.Wrap(err, "decoding response"))
 	}
-	r.Close = c.closeReq
-	r.Header.Set("Content-Type", writer.FormDataContentType())
-	r.Header.Set("Accept", "application/json; charset=utf-8")
-	for key, values := range req.Header {
-		for _, value := range values {
-			r.Header.Add(key, value)
+	if len(gr.Errors) > 0 {
+		return NewGraphQLError(gr.Errors, res)
+	}
+	return nil
+}
+
+// successful reports whether res has a 2xx status. Following the GraphQL
+// over HTTP specification, such responses hold a GraphQL response, with
+// or without errors.
+func successful(res *http.Response) bool {
+	return res.StatusCode >= 200 && res.StatusCode < 300
+}
+
+// decodeErrorResponse reports a response with a status other than 2xx.
+// Following the GraphQL over HTTP specification, servers answering with
+// MediaTypeGraphQLResponse send well-formed GraphQL responses along with
+// 4xx and 5xx statuses, so their errors are decoded. Other responses
+// become a *RequestError with the body left for the caller to read.
+func (c *Client) decodeErrorResponse(ctx context.Context, op Operation, res *http.Response, resp interface{}) Error {
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType != MediaTypeGraphQLResponse {
+		if err := c.teeUnread(ctx, op, res); err != nil {
+			return err
 		}
+		return NewRequestError(res)
 	}
-	c.logf(">> headers: %v", r.Header)
-	r = r.WithContext(ctx)
-	res, err := c.httpClient.Do(r)
+	body, err := c.readBody(ctx, res)
 	if err != nil {
-		return NewExecutionError(err)
+		return err
 	}
-	if res.StatusCode != http.StatusOK {
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	c.teeResponse(op, res, body)
+	if decoded, err := toUTF8(res.Header.Get("Content-Type"), body); err == nil {
+		body = decoded
+	}
+	gr := &graphResponse{Data: resp}
+	if err := json.Unmarshal(body, gr); err != nil || len(gr.Errors) == 0 {
 		return NewRequestError(res)
 	}
-	defer res.Body.Close()
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, res.Body); err != nil {
-		return NewExecutionError(errors.Wrap(err, "reading body"))
+	return NewGraphQLError(gr.Errors, res)
+}
+
+// newRequest builds the http.Request for req. Client headers are set
+// first so the ones of the request can add to or override them.
+func (c *Client) newRequest(ctx context.Context, req *Req, body []byte, contentType string, params url.Values) (*http.Request, error) {
+	if len(req.tags) > 0 {
+		tags := make(map[string]string, len(req.tags))
+		for key, value := range req.tags {
+			tags[key] = value
+		}
+		ctx = context.WithValue(ctx, tagsKey{}, tags)
 	}
-	c.logf("<< %s", buf.String())
-	if err := json.NewDecoder(&buf).Decode(&gr); err != nil {
-		return NewExecutionError(errors.Wrap(err, "decoding response"))
+	endpoint, err := c.resolveEndpoint(ctx, req)
+	if err != nil {
+		return nil, err
 	}
-	if len(gr.Errors) > 0 {
-		return NewGraphQLError(gr.Errors, res)
+	get := body == nil && params != nil
+	body, encoding, err := c.compressBody(body)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	var r *http.Request
+	if get {
+		r, err = http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	} else {
+		r, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !get {
+		// The body is fully encoded in memory, so it can always be
+		// replayed by transport retries and middleware reading it.
+		r.ContentLength = int64(len(body))
+		r.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		r.Header.Set("Content-Type", contentType)
+		if encoding != "" {
+			r.Header.Set("Content-Encoding", encoding)
+		}
+	}
+	if len(c.queryParams) > 0 || len(params) > 0 {
+		query := r.URL.Query()
+		for key, values := range c.queryParams {
+			query[key] = append([]string(nil), values...)
+		}
+		for key, values := range params {
+			query[key] = values
+		}
+		r.URL.RawQuery = query.Encode()
+	}
+	r.Close = c.closeReq
+	r.Header.Set("Accept", "application/json; charset=utf-8")
+	if accepted := acceptedEncodings(c.decompression); len(accepted) > 0 {
+		r.Header.Set("Accept-Encoding", strings.Join(accepted, ", "))
+	}
+	for key, values := range c.header {
+		key = http.CanonicalHeaderKey(key)
+		// The values a request sets replace the ones of the client.
+		if req.setsHeader(key) {
+			continue
+		}
+		r.Header[key] = append([]string(nil), values...)
+	}
+	if accept, ok := ctx.Value(acceptKey{}).(string); ok {
+		r.Header.Set("Accept", accept)
+	}
+	req.applyHeaders(r.Header)
+	if c.logs(LogRequest) {
+		c.logf(LogRequest, []LogField{{"method", r.Method}, {"endpoint", endpoint}, {"bytes", r.ContentLength}},
+			">> %s %s (%d bytes)", r.Method, endpoint, r.ContentLength)
+		headers := c.redact(r.Header)
+		c.logf(LogRequest, []LogField{{"headers", headers}}, ">> headers: %v", headers)
+	}
+	return r, nil
+}
+
+// tagsKey is the context key of the tags of the request being made.
+type tagsKey struct{}
+
+// TagsFromContext gets the tags set with Req.Tag on the request
+// being made. Middleware wrapping the http.Client of the Client call it
+// with the context of the http.Request.
+func TagsFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(tagsKey{}).(map[string]string)
+	return tags
+}
+
+// responseHeadersKey is the context key of the header filled by
+// captureResponseHeaders.
+type responseHeadersKey struct{}
+
+// WithResponseHeaders returns a context that makes Run copy the headers of
+// the HTTP response into h, including for error responses, so callers can
+// read rate limit or pagination headers.
+func WithResponseHeaders(ctx context.Context, h *http.Header) context.Context {
+	return context.WithValue(ctx, responseHeadersKey{}, h)
+}
+
+func captureResponseHeaders(ctx context.Context, res *http.Response) {
+	h, ok := ctx.Value(responseHeadersKey{}).(*http.Header)
+	if !ok || h == nil {
+		return
+	}
+	*h = res.Header.Clone()
 }
 
 func emptyOrString(pointer *string) string {
@@ -2,10 +2,14 @@ package graphql
 
 import (
 	"context"
+	"errors"
 	"io"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -341,3 +345,443 @@ func TestHeader(t *testing.T) {
 
 	is.Equal(resp.Value, "some data")
 }
+
+func TestSetHeaderOverridesDefaults(t *testing.T) {
+	is := is.New(t)
+
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		is.Equal(r.Header.Values("Content-Type"), []string{"application/vnd.custom+json"})
+		is.Equal(r.Header.Values("Accept"), []string(nil))
+
+		_, err := io.WriteString(w, `{"data":{"value":"some data"}}`)
+		is.NoErr(err)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+
+	req := NewRequest("query {}")
+	req.SetHeader("content-type", "application/vnd.custom+json")
+	req.DelHeader("Accept")
+
+	var resp struct {
+		Value string
+	}
+	err := client.Run(ctx, req, &resp)
+	is.NoErr(err)
+	is.Equal(calls, 1)
+}
+
+func TestClientAwarenessHeaders(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("Apollographql-Client-Name"), "checkout")
+		is.Equal(r.Header.Get("Apollographql-Client-Version"), "1.2.3")
+		is.Equal(r.Header.Values("X-Tenant"), []string{"acme"})
+
+		_, err := io.WriteString(w, `{"data":{}}`)
+		is.NoErr(err)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithClientAwareness("checkout", "1.2.3"), WithHeader("X-Tenant", "default"))
+
+	req := NewRequest("query {}")
+	req.SetHeader("X-Tenant", "acme")
+
+	err := client.Run(context.Background(), req, nil)
+	is.NoErr(err)
+
+	// Plain request headers replace the client's too.
+	req = NewRequest("query {}")
+	req.Header("X-Tenant", "acme")
+
+	err = client.Run(context.Background(), req, nil)
+	is.NoErr(err)
+}
+
+func TestTagsFromContext(t *testing.T) {
+	is := is.New(t)
+
+	var calls int
+	httpClient := &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			is.Equal(TagsFromContext(r.Context()), map[string]string{"team": "payments", "priority": "high"})
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"data":{}}`)),
+			}, nil
+		}),
+	}
+	client := NewClient("https://example.com/graphql", WithHTTPClient(httpClient))
+
+	req := NewRequest("query {}")
+	req.Tag("team", "payments")
+	req.Tag("priority", "high")
+
+	err := client.Run(context.Background(), req, nil)
+	is.NoErr(err)
+	is.Equal(calls, 1)
+	is.Equal(TagsFromContext(context.Background()), map[string]string(nil))
+}
+
+func TestWithResponseHeaders(t *testing.T) {
+	is := is.New(t)
+
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "41")
+		w.WriteHeader(status)
+		_, err := io.WriteString(w, `{"data":{}}`)
+		is.NoErr(err)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+
+	var header http.Header
+	ctx := WithResponseHeaders(context.Background(), &header)
+	err := client.Run(ctx, NewRequest("query {}"), nil)
+	is.NoErr(err)
+	is.Equal(header.Get("X-RateLimit-Remaining"), "41")
+
+	header = nil
+	status = http.StatusTooManyRequests
+	err = client.Run(ctx, NewRequest("query {}"), nil)
+	is.True(err != nil)
+	is.Equal(header.Get("X-RateLimit-Remaining"), "41")
+}
+
+func TestRunRaw(t *testing.T) {
+	is := is.New(t)
+
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, err := io.WriteString(w, `{"data":null,"errors":[{"message":"not found"}]}`)
+		is.NoErr(err)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+
+	body, res, err := client.RunRaw(context.Background(), NewRequest("query {}"))
+	is.NoErr(err)
+	is.Equal(res.StatusCode, http.StatusOK)
+	is.Equal(string(body), `{"data":null,"errors":[{"message":"not found"}]}`)
+
+	status = http.StatusBadGateway
+	body, res, err = client.RunRaw(context.Background(), NewRequest("query {}"))
+	is.Equal(err.Error(), "request failed with status: 502 Bad Gateway")
+	is.Equal(res.StatusCode, http.StatusBadGateway)
+	is.Equal(string(body), `{"data":null,"errors":[{"message":"not found"}]}`)
+	b, rerr := ioutil.ReadAll(err.Response().Body)
+	is.NoErr(rerr)
+	is.Equal(b, body)
+}
+
+func TestWithTimeout(t *testing.T) {
+	is := is.New(t)
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := NewClient(srv.URL, WithTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	err := client.Run(context.Background(), NewRequest("query {}"), nil)
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), context.DeadlineExceeded.Error()))
+	is.True(time.Since(start) < 500*time.Millisecond)
+}
+
+func TestNewClientE(t *testing.T) {
+	is := is.New(t)
+
+	_, err := NewClientE("https://example.com/graphql", WithTimeout(time.Second))
+	is.NoErr(err)
+	_, err = NewClientE("/graphql", WithHandler(http.NotFoundHandler()))
+	is.NoErr(err)
+
+	for endpoint, msg := range map[string]string{
+		"example.com/graphql":  `graphql: endpoint "example.com/graphql" must use http or https`,
+		"ftp://example.com":    `graphql: endpoint "ftp://example.com" must use http or https`,
+		"https:///graphql":     `graphql: endpoint "https:///graphql" has no host`,
+		"https://example.com%": `graphql: invalid endpoint: parse "https://example.com%": invalid URL escape "%"`,
+	} {
+		_, err := NewClientE(endpoint)
+		is.Equal(err.Error(), msg)
+	}
+
+	_, err = NewClientE("https://example.com", WithTimeout(-time.Second))
+	is.Equal(err.Error(), "graphql: timeout -1s must not be negative")
+	_, err = NewClientE("https://example.com", WithHeader("X-Token", "a\r\nb"))
+	is.Equal(err.Error(), `graphql: invalid header "X-Token"`)
+	_, err = NewClientE("https://example.com", UseGET(), UseMultipartForm())
+	is.Equal(err.Error(), "graphql: UseGET cannot be combined with UseMultipartForm")
+	_, err = NewClientE("https://example.com", UseMultipartForm(), UseGraphQLBody())
+	is.Equal(err.Error(), "graphql: UseGraphQLBody cannot be combined with UseMultipartForm")
+}
+
+func TestWithLogger(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	var lines []string
+	client := NewClient(srv.URL, WithLogger(func(s string) { lines = append(lines, s) }))
+	is.NoErr(client.Run(context.Background(), NewRequest("query {}"), nil))
+	is.Equal(lines[len(lines)-1], `<< {"data":{}}`)
+
+	client = NewClient(srv.URL, WithLogger(nil))
+	is.NoErr(client.Run(context.Background(), NewRequest("query {}"), nil))
+}
+
+func TestWithAccept(t *testing.T) {
+	is := is.New(t)
+
+	status := http.StatusBadRequest
+	contentType := MediaTypeGraphQLResponse
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("Accept"), MediaTypeGraphQLResponse+", "+MediaTypeJSON)
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, `{"errors":[{"message":"unknown field"}]}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithAccept(MediaTypeGraphQLResponse+", "+MediaTypeJSON))
+	ctx := context.Background()
+
+	err := client.Run(ctx, NewRequest("query {}"), nil)
+	gerr, ok := err.(*GraphQLError)
+	is.True(ok)
+	is.Equal(gerr.Errors(), []string{"unknown field"})
+
+	contentType = "text/html"
+	err = client.Run(ctx, NewRequest("query {}"), nil)
+	rerr, ok := err.(*RequestError)
+	is.True(ok)
+	is.Equal(rerr.Response().StatusCode, http.StatusBadRequest)
+	body, _ := io.ReadAll(rerr.Response().Body)
+	is.Equal(string(body), `{"errors":[{"message":"unknown field"}]}`)
+}
+
+func TestRunStatusSemantics(t *testing.T) {
+	is := is.New(t)
+
+	var (
+		status int
+		body   string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", MediaTypeGraphQLResponse)
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	ctx := context.Background()
+
+	// Any 2xx status carries a GraphQL response, with or without errors.
+	status, body = http.StatusAccepted, `{"data":{"name":"Cafe"}}`
+	var resp struct{ Name string }
+	is.NoErr(client.Run(ctx, NewRequest("query { name }"), &resp))
+	is.Equal(resp.Name, "Cafe")
+
+	status, body = http.StatusCreated, `{"data":null,"errors":[{"message":"denied"}]}`
+	_, ok := client.Run(ctx, NewRequest("query { name }"), nil).(*GraphQLError)
+	is.True(ok)
+
+	// Errors and partial data of 4xx and 5xx responses are decoded too.
+	status, body = http.StatusInternalServerError, `{"data":{"name":"Bar"},"errors":[{"message":"partial failure"}]}`
+	err := client.Run(ctx, NewRequest("query { name }"), &resp)
+	gerr, ok := err.(*GraphQLError)
+	is.True(ok)
+	is.Equal(gerr.Error(), "partial failure")
+	is.Equal(gerr.Response().StatusCode, http.StatusInternalServerError)
+	is.Equal(resp.Name, "Bar")
+
+	raw, _, rawErr := client.RunRaw(ctx, NewRequest("query { name }"))
+	is.True(rawErr != nil)
+	is.Equal(string(raw), body)
+	status = http.StatusNonAuthoritativeInfo
+	_, _, rawErr = client.RunRaw(ctx, NewRequest("query { name }"))
+	is.NoErr(rawErr)
+}
+
+func TestWithQueryParams(t *testing.T) {
+	is := is.New(t)
+
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL+"?version=1&region=eu",
+		WithQueryParams(url.Values{"version": {"2"}}),
+		WithQueryParams(url.Values{"api_key": {"secret"}}),
+	)
+	is.NoErr(client.Run(context.Background(), NewRequest("query {}"), nil))
+	is.Equal(query, url.Values{"version": {"2"}, "region": {"eu"}, "api_key": {"secret"}})
+}
+
+func TestRunCanceledWhileReadingBody(t *testing.T) {
+	is := is.New(t)
+
+	body, w := io.Pipe()
+	defer w.Close()
+	client := NewClient("https://example.com", WithHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Header: make(http.Header), Body: body}, nil
+		}),
+	}))
+	go func() { _, _ = io.WriteString(w, `{"data":`) }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := client.Run(ctx, NewRequest("query {}"), nil)
+	is.True(errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestRunEmptyResponse(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	err := NewClient(srv.URL).Run(context.Background(), NewRequest("query {}"), nil)
+	is.True(errors.Is(err, ErrEmptyResponse))
+	is.Equal(err.Error(), `200 OK with "": empty response body`)
+
+	client := NewClient("https://example.com", WithHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Header: make(http.Header)}, nil
+		}),
+	}))
+	err = client.Run(context.Background(), NewRequest("query {}"), nil)
+	is.True(errors.Is(err, ErrEmptyResponse))
+}
+
+func TestWithNullData(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":null}`)
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	var resp struct{ Value string }
+	is.NoErr(NewClient(srv.URL).Run(ctx, NewRequest("query {}"), &resp))
+
+	client := NewClient(srv.URL, WithNullData(NullDataError))
+	err := client.Run(ctx, NewRequest("query {}"), &resp)
+	is.True(errors.Is(err, ErrNullData))
+	err = client.Run(ctx, NewMutation("mutation {}"), &resp)
+	is.True(errors.Is(err, ErrNullData))
+}
+
+func TestRunNotJSON(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(w, "\n<html><body>"+strings.Repeat("x", 300)+"</body></html>")
+	}))
+	defer srv.Close()
+
+	err := NewClient(srv.URL).Run(context.Background(), NewRequest("query {}"), nil)
+	is.True(errors.Is(err, ErrNotJSON))
+	is.Equal(err.Error(), `200 OK with "text/html": "<html><body>`+strings.Repeat("x", 188)+`"...: response is not JSON`)
+}
+
+func TestWithStrictContentType(t *testing.T) {
+	is := is.New(t)
+
+	contentType := "application/json; charset=utf-8"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	client := NewClient(srv.URL, WithStrictContentType())
+	ctx := context.Background()
+
+	is.NoErr(client.Run(ctx, NewRequest("query {}"), nil))
+	contentType = MediaTypeGraphQLResponse
+	is.NoErr(client.Run(ctx, NewRequest("query {}"), nil))
+
+	contentType = "text/plain"
+	err := client.Run(ctx, NewRequest("query {}"), nil)
+	is.True(errors.Is(err, ErrUnexpectedContentType))
+	is.Equal(err.Error(), `200 OK with "text/plain": unexpected content type`)
+	is.NoErr(NewClient(srv.URL).Run(ctx, NewRequest("query {}"), nil))
+}
+
+func TestRequestGetBody(t *testing.T) {
+	is := is.New(t)
+
+	for _, opts := range [][]ClientOption{nil, {UseMultipartForm()}} {
+		client := NewClient("https://example.com", append(opts, WithHTTPClient(&http.Client{
+			Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				body, err := io.ReadAll(r.Body)
+				is.NoErr(err)
+				is.Equal(int64(len(body)), r.ContentLength)
+				for i := 0; i < 2; i++ {
+					replay, err := r.GetBody()
+					is.NoErr(err)
+					b, err := io.ReadAll(replay)
+					is.NoErr(err)
+					is.Equal(b, body)
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Status:     "200 OK",
+					Header:     make(http.Header),
+					Body:       io.NopCloser(strings.NewReader(`{"data":{}}`)),
+				}, nil
+			}),
+		}))...)
+		req := NewRequest("query ($id: ID!) { node(id: $id) { id } }")
+		req.Var("id", "1")
+		is.NoErr(client.Run(context.Background(), req, nil))
+	}
+}
+
+func TestRunUnencodableVariable(t *testing.T) {
+	is := is.New(t)
+
+	httpClient := &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			t.Fatal("request must not be sent")
+			return nil, nil
+		}),
+	}
+	for _, opts := range [][]ClientOption{nil, {UseMultipartForm()}} {
+		client := NewClient("https://example.com", append(opts, WithHTTPClient(httpClient))...)
+		req := NewRequest("query ($a: Int, $b: Float) { a }")
+		req.Var("a", 1)
+		req.Var("b", math.NaN())
+		err := client.Run(context.Background(), req, nil)
+		var verr *VariableError
+		is.True(errors.As(err, &verr))
+		is.Equal(verr.Name, "b")
+		is.Equal(err.Error(), "variable $b cannot be encoded: json: unsupported value: NaN")
+	}
+}
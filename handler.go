@@ -0,0 +1,89 @@
+package graphql
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+type (
+	// handlerClient is a CustomHttpClient that serves requests with an
+	// http.Handler in the same process instead of going over the network.
+	handlerClient struct {
+		handler http.Handler
+	}
+
+	// responseRecorder buffers what a handler writes so it can be
+	// returned as an http.Response.
+	responseRecorder struct {
+		header      http.Header
+		status      int
+		wroteHeader bool
+		body        bytes.Buffer
+	}
+)
+
+// WithHandler executes every request directly against handler, e.g. a
+// gqlgen server, without opening a network connection. This is mostly
+// useful for integration tests and embedded gateways.
+//
+//	NewClient("/graphql", WithHandler(srv))
+func WithHandler(handler http.Handler) ClientOption {
+	return func(client *Client) {
+		client.httpClient = &handlerClient{handler: handler}
+	}
+}
+
+func (h *handlerClient) Do(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.RequestURI = r.URL.RequestURI()
+	if r.RemoteAddr == "" {
+		r.RemoteAddr = "127.0.0.1:0"
+	}
+	if r.Body == nil {
+		r.Body = http.NoBody
+	}
+
+	rec := &responseRecorder{header: make(http.Header)}
+	h.handler.ServeHTTP(rec, r)
+	if err := r.Context().Err(); err != nil {
+		return nil, err
+	}
+	return rec.result(r), nil
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.status = status
+	rec.wroteHeader = true
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(b)
+}
+
+func (rec *responseRecorder) result(r *http.Request) *http.Response {
+	status := rec.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.header,
+		Body:          ioutil.NopCloser(&rec.body),
+		ContentLength: int64(rec.body.Len()),
+		Request:       r,
+	}
+}
@@ -0,0 +1,45 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithHandler(t *testing.T) {
+	is := is.New(t)
+	var calls int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		is.Equal(r.Method, http.MethodPost)
+		is.Equal(r.RequestURI, "/graphql")
+		b, err := ioutil.ReadAll(r.Body)
+		is.NoErr(err)
+		is.Equal(string(b), `{"query":"query {}","variables":null}`+"\n")
+		_, _ = io.WriteString(w, `{"data":{"something":"yes"}}`)
+	})
+
+	client := NewClient("/graphql", WithHandler(handler))
+
+	var responseData map[string]interface{}
+	err := client.Run(context.Background(), NewRequest("query {}"), &responseData)
+	is.NoErr(err)
+	is.Equal(calls, 1)
+	is.Equal(responseData["something"], "yes")
+}
+
+func TestWithHandlerStatus(t *testing.T) {
+	is := is.New(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	client := NewClient("/graphql", WithHandler(handler))
+
+	err := client.Run(context.Background(), NewRequest("query {}"), nil)
+	is.Equal(err.Error(), "request failed with status: 502 Bad Gateway")
+}
@@ -0,0 +1,32 @@
+package graphql
+
+// Error codes Hasura reports in the extensions of an error.
+const (
+	HasuraConstraintViolation = "constraint-violation"
+	HasuraConstraintError     = "constraint-error"
+	HasuraPermissionDenied    = "permission-denied"
+	HasuraPermissionError     = "permission-error"
+	HasuraAccessDenied        = "access-denied"
+	HasuraValidationFailed    = "validation-failed"
+	HasuraDataException       = "data-exception"
+	HasuraInvalidJWT          = "invalid-jwt"
+	HasuraUnexpected          = "unexpected"
+)
+
+// IsConstraintViolation reports whether err is a Hasura error caused by a
+// database constraint, e.g. a unique or foreign key violation.
+func IsConstraintViolation(err error) bool {
+	return hasErrorCode(err, HasuraConstraintViolation, HasuraConstraintError)
+}
+
+// IsPermissionDenied reports whether err is a Hasura error caused by the
+// permissions of the role the request was made with.
+func IsPermissionDenied(err error) bool {
+	return hasErrorCode(err, HasuraPermissionDenied, HasuraPermissionError, HasuraAccessDenied)
+}
+
+// IsValidationFailed reports whether err is a Hasura error caused by a
+// query that does not match the schema.
+func IsValidationFailed(err error) bool {
+	return hasErrorCode(err, HasuraValidationFailed)
+}
@@ -0,0 +1,58 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+)
+
+func TestHasuraErrorDetails(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{
+			"errors": [
+				{
+					"message": "Uniqueness violation. duplicate key value violates unique constraint \"users_email_key\"",
+					"extensions": {
+						"path": "$.selectionSet.insert_users.args.objects",
+						"code": "constraint-violation",
+						"internal": {"error": {"status_code": "23505"}}
+					}
+				}
+			]
+		}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	err := client.Run(context.Background(), NewRequest("mutation {}"), nil)
+	is.True(err != nil)
+	is.Equal(err.Code(), HasuraConstraintViolation)
+	is.Equal(err.Details(), []ErrorDetail{
+		{
+			Code:     HasuraConstraintViolation,
+			Message:  `Uniqueness violation. duplicate key value violates unique constraint "users_email_key"`,
+			Domain:   "$.selectionSet.insert_users.args.objects",
+			Internal: `{"error": {"status_code": "23505"}}`,
+		},
+	})
+	is.True(IsConstraintViolation(err))
+	is.True(IsConstraintViolation(errors.Wrap(err, "create user")))
+	is.True(!IsPermissionDenied(err))
+}
+
+func TestHasuraPredicates(t *testing.T) {
+	is := is.New(t)
+
+	err := NewGraphQLError([]GraphErr{{Extentions: GraphExt{Code: "permission-error"}}}, nil)
+	is.True(IsPermissionDenied(err))
+	is.True(!IsConstraintViolation(err))
+	is.True(!IsValidationFailed(err))
+	is.True(!IsPermissionDenied(errors.New("permission-error")))
+	is.True(!IsPermissionDenied(nil))
+}
@@ -0,0 +1,36 @@
+package graphql
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// mutationDecodeHook converts the generic values of mutation payloads,
+// which are decoded with mapstructure rather than encoding/json, into
+// scalar types.
+var mutationDecodeHook = mapstructure.ComposeDecodeHookFunc(
+	decodeUnmarshalerHook,
+	decodeUUIDHook,
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// decodeUnmarshalerHook re-encodes values for types that decode
+// themselves from JSON, such as ID, Decimal, Time, Bytes or
+// json.RawMessage, whose object keys come out sorted.
+func decodeUnmarshalerHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if data == nil || from == to || !reflect.PtrTo(to).Implements(jsonUnmarshalerType) {
+		return data, nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	out := reflect.New(to)
+	if err := out.Interface().(json.Unmarshaler).UnmarshalJSON(b); err != nil {
+		return nil, err
+	}
+	return out.Elem().Interface(), nil
+}
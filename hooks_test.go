@@ -0,0 +1,42 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestJSONScalarPassthrough(t *testing.T) {
+	is := is.New(t)
+
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		_, _ = io.WriteString(w, `{"data":{"save":{"successful":true,"result":{"settings":{"theme":"dark","limits":[1,2]},"extra":{"a":null}}}}}`)
+	}))
+	defer srv.Close()
+
+	req := NewMutation("mutation ($settings: JSON!, $extra: JSONB) { save { result { settings extra } } }")
+	req.Var("settings", json.RawMessage(`{"theme":"dark","limits":[1,2]}`))
+	req.Var("extra", map[string]interface{}{"a": nil})
+
+	var resp struct {
+		Save struct {
+			Result struct {
+				Settings json.RawMessage
+				Extra    map[string]interface{}
+			}
+		}
+	}
+	client := NewClient(srv.URL, WithTimeFormat(TimeUnix))
+	is.NoErr(client.Run(context.Background(), req, &resp))
+	is.Equal(body, `{"query":"mutation ($settings: JSON!, $extra: JSONB) { save { result { settings extra } } }","variables":{"extra":{"a":null},"settings":{"theme":"dark","limits":[1,2]}}}`+"\n")
+	is.Equal(string(resp.Save.Result.Settings), `{"limits":[1,2],"theme":"dark"}`)
+	is.Equal(resp.Save.Result.Extra, map[string]interface{}{"a": nil})
+}
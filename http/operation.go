@@ -1,17 +1,22 @@
 package http
 
 import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
-	"regexp"
+
+	graphql "github.com/sumup/graphql"
+	"github.com/sumup/graphql/parser"
 )
 
 type addOperation struct {
 	inner http.RoundTripper
 }
 
-const operationRegex = `((?:query\s)|(?:mutation\s))(\w+)`
-
 func SetGraphqlOperation(inner http.RoundTripper) http.RoundTripper {
 	return &addOperation{
 		inner: inner,
@@ -31,28 +36,93 @@ func (ug *addOperation) RoundTrip(r *http.Request) (*http.Response, error) {
 	return ug.inner.RoundTrip(r)
 }
 
+// getOperationName gets the name of the operation executed by r, parsing
+// the document so comments, strings, shorthand queries and documents with
+// several operations are handled.
 func getOperationName(r *http.Request) string {
-	regex := regexp.MustCompile(operationRegex)
+	if r.Method == http.MethodGet {
+		params := r.URL.Query()
+		return operationName(params.Get("query"), params.Get("operationName"))
+	}
+	if r.GetBody == nil {
+		return ""
+	}
+	body, err := r.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
 
-	if r.GetBody != nil {
-		getBody := r.GetBody
+	decoded, err := decodeBody(r.Header.Get("Content-Encoding"), body)
+	if err != nil {
+		return ""
+	}
+	query, name := readOperation(r.Header.Get("Content-Type"), decoded)
+	if name == "" {
+		name = r.URL.Query().Get("operationName")
+	}
+	return operationName(query, name)
+}
 
-		copyBody, err := getBody()
-		if err != nil {
-			return ""
-		}
+// operationName gets the name of the operation of query selected by
+// name, or name when there is no query.
+func operationName(query, name string) string {
+	if query == "" {
+		return name
+	}
+	name, err := parser.OperationName(query, name)
+	if err != nil {
+		return ""
+	}
+	return name
+}
 
-		b, err := io.ReadAll(copyBody)
-		if err != nil {
-			return ""
-		}
-		copyBody.Close()
+// decodeBody undoes the gzip or deflate compression of a request body,
+// see graphql.WithRequestCompression.
+func decodeBody(encoding string, body io.Reader) (io.Reader, error) {
+	switch encoding {
+	case "gzip":
+		return gzip.NewReader(body)
+	case "deflate":
+		return zlib.NewReader(body)
+	}
+	return body, nil
+}
 
-		operation := regex.FindAllSubmatch(b, -1)
-		if operation != nil {
-			return string(operation[0][2])
+// readOperation reads the query and operation name of a JSON,
+// multipart/form-data or application/graphql request body. The latter is
+// the bare document, whose operation name is in the URL.
+func readOperation(contentType string, body io.Reader) (query, operationName string) {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if mediaType == graphql.MediaTypeGraphQL {
+		b, _ := io.ReadAll(body)
+		return string(b), ""
+	}
+	if mediaType != "multipart/form-data" {
+		var payload struct {
+			Query         string `json:"query"`
+			OperationName string `json:"operationName"`
+		}
+		if err := json.NewDecoder(body).Decode(&payload); err != nil {
+			return "", ""
 		}
+		return payload.Query, payload.OperationName
 	}
 
-	return ""
+	form := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := form.NextPart()
+		// Fields precede files, which are not worth reading.
+		if err != nil || part.FileName() != "" {
+			return query, operationName
+		}
+		switch part.FormName() {
+		case "query":
+			b, _ := io.ReadAll(part)
+			query = string(b)
+		case "operationName":
+			b, _ := io.ReadAll(part)
+			operationName = string(b)
+		}
+	}
 }
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -99,3 +100,58 @@ func runTest(t *testing.T, handlerFunc http.HandlerFunc, runner func(*testing.T,
 
 	runner(t, client)
 }
+
+func Test_getOperationName(t *testing.T) {
+	tests := map[string]func() graphql.Operation{
+		"comments and strings": func() graphql.Operation {
+			return graphql.NewRequest("# query Commented { a }\nquery FooBar { a(s: \"query Other \") }")
+		},
+		"compressed": func() graphql.Operation {
+			req := graphql.NewRequest("query FooBar { a }")
+			req.Var("blob", strings.Repeat("x", 64))
+			return req
+		},
+		"get": func() graphql.Operation {
+			return graphql.NewRequest("query FooBar { a }")
+		},
+		"graphql body": func() graphql.Operation {
+			return graphql.NewRequest("# query Other { a }\nquery FooBar { a }")
+		},
+		"multipart": func() graphql.Operation {
+			req := graphql.NewRequest("query FooBar { a }")
+			req.File("file", "a.txt", strings.NewReader("query Other { a }"))
+			return req
+		},
+	}
+	for name, op := range tests {
+		t.Run(name, func(t *testing.T) {
+			handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, []string{"FooBar"}, r.URL.Query()["operation"])
+
+				_, _ = io.WriteString(w, `{"data":{}}`)
+			})
+			srv := httptest.NewServer(handlerFunc)
+			defer srv.Close()
+
+			httpClient := &http.Client{Transport: SetGraphqlOperation(http.DefaultTransport)}
+			opts := []graphql.ClientOption{graphql.WithHTTPClient(httpClient)}
+			req := op()
+			if len(req.Files()) > 0 {
+				opts = append(opts, graphql.UseMultipartForm())
+			}
+			if name == "graphql body" {
+				opts = append(opts, graphql.UseGraphQLBody())
+			}
+			if name == "get" {
+				opts = append(opts, graphql.UseGET())
+			}
+			if name == "compressed" {
+				opts = append(opts, graphql.WithRequestCompression(graphql.CompressionGzip, 0))
+			}
+			client := graphql.NewClient(srv.URL, opts...)
+
+			err := client.Run(context.Background(), req, nil)
+			assert.NoError(t, err)
+		})
+	}
+}
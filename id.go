@@ -0,0 +1,70 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ID is the GraphQL ID scalar. It is sent as a string but accepts both
+// strings and numbers in responses, since servers serialize IDs
+// inconsistently.
+type ID string
+
+// IDFromInt converts a numeric identifier to an ID.
+func IDFromInt(i int64) ID {
+	return ID(strconv.FormatInt(i, 10))
+}
+
+// String gets the ID as a string.
+func (id ID) String() string {
+	return string(id)
+}
+
+// Int64 converts a numeric ID, failing for other IDs.
+func (id ID) Int64() (int64, error) {
+	i, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("ID %q is not numeric", string(id))
+	}
+	return i, nil
+}
+
+// Equal reports whether id identifies the same object as other, which
+// may be an ID, a string or an integer.
+func (id ID) Equal(other interface{}) bool {
+	switch other := other.(type) {
+	case ID:
+		return id == other
+	case string:
+		return string(id) == other
+	case int:
+		return string(id) == strconv.Itoa(other)
+	case int64:
+		return id == IDFromInt(other)
+	}
+	return false
+}
+
+// UnmarshalJSON accepts a string or an integer.
+func (id *ID) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*id = ID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return errors.Errorf("ID must be a string or a number, got %s", b)
+	}
+	*id = ID(n.String())
+	return nil
+}
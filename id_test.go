@@ -0,0 +1,53 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestID(t *testing.T) {
+	is := is.New(t)
+
+	var ids []ID
+	is.NoErr(json.Unmarshal([]byte(`["a1", 42, null]`), &ids))
+	is.Equal(ids, []ID{"a1", "42", ""})
+	b, err := json.Marshal(IDFromInt(7))
+	is.NoErr(err)
+	is.Equal(string(b), `"7"`)
+	is.True(json.Unmarshal([]byte(`true`), &ids[0]) != nil)
+
+	i, err := ids[1].Int64()
+	is.NoErr(err)
+	is.Equal(i, int64(42))
+	_, err = ids[0].Int64()
+	is.Equal(err.Error(), `ID "a1" is not numeric`)
+
+	is.True(ids[1].Equal(42))
+	is.True(ids[1].Equal(int64(42)))
+	is.True(ids[1].Equal("42"))
+	is.True(ids[0].Equal(ID("a1")))
+	is.True(!ids[0].Equal(1.5))
+}
+
+func TestIDInMutationPayload(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"createMerchant":{"successful":true,"result":{"id":42}}}}`)
+	}))
+	defer srv.Close()
+
+	var resp struct {
+		CreateMerchant struct {
+			Result struct{ ID ID }
+		}
+	}
+	is.NoErr(NewClient(srv.URL).Run(context.Background(), NewMutation("mutation { createMerchant { result { id } } }"), &resp))
+	is.Equal(resp.CreateMerchant.Result.ID, ID("42"))
+}
@@ -0,0 +1,206 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MediaTypeMultipartMixed is the media type of incremental responses.
+const MediaTypeMultipartMixed = "multipart/mixed"
+
+// GraphResponse is a response, or a part of an incremental response to
+// an operation using @defer or @stream.
+type GraphResponse struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []GraphErr      `json:"errors,omitempty"`
+	// Path locates the deferred fragment or the streamed list within
+	// the data of the first part.
+	Path []interface{} `json:"path,omitempty"`
+	// Label is the label argument of the directive, if any.
+	Label string `json:"label,omitempty"`
+	// Items holds the items added to a streamed list.
+	Items []json.RawMessage `json:"items,omitempty"`
+	// HasNext is false for the last part.
+	HasNext bool `json:"hasNext"`
+}
+
+// Decode decodes the data of the response into v.
+func (r *GraphResponse) Decode(v interface{}) error {
+	if len(r.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Data, v)
+}
+
+// IncrementalReader iterates over the parts of the response to an
+// operation, as they arrive. Close it when done.
+type IncrementalReader struct {
+	client  *Client
+	res     *http.Response
+	parts   *multipart.Reader
+	pending []*GraphResponse
+	done    bool
+	err     Error
+	stop    chan struct{}
+	ctx     context.Context
+}
+
+// RunIncremental executes op, usually a query with @defer or @stream
+// directives, accepting a multipart/mixed incremental response whose
+// parts are delivered by Next as they arrive. Servers answering with a
+// single JSON response deliver one part. Errors of the request itself
+// are returned at once; errors of the operation are in the parts. Errors
+// returned by RunIncremental and Err are counted in Stats and passed to
+// WithErrorTranslator as the ones of Run.
+//
+//	parts, err := client.RunIncremental(ctx, req)
+//	if err != nil {
+//		return err
+//	}
+//	defer parts.Close()
+//	for part, ok := parts.Next(); ok; part, ok = parts.Next() {
+//		...
+//	}
+//	return parts.Err()
+func (c *Client) RunIncremental(ctx context.Context, op Operation) (*IncrementalReader, Error) {
+	r, err := c.runIncremental(ctx, op)
+	c.countError(err)
+	return r, c.translate(err)
+}
+
+func (c *Client) runIncremental(ctx context.Context, op Operation) (*IncrementalReader, Error) {
+	accept := MediaTypeMultipartMixed + "; deferSpec=20220824, " + MediaTypeJSON
+	res, err := c.send(context.WithValue(ctx, acceptKey{}, accept), op)
+	if err != nil {
+		return nil, err
+	}
+	if !successful(res) {
+		return nil, c.decodeErrorResponse(ctx, op, res, nil)
+	}
+	r := &IncrementalReader{client: c, res: res, ctx: ctx, stop: make(chan struct{})}
+	mediaType, params, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType != MediaTypeMultipartMixed {
+		body, err := c.readBody(ctx, res)
+		if err != nil {
+			return nil, err
+		}
+		r.done = true
+		r.setErr(r.queue(body))
+		return r, nil
+	}
+	if params["boundary"] == "" {
+		res.Body.Close()
+		return nil, NewExecutionError(errors.New("multipart response has no boundary"))
+	}
+	r.parts = multipart.NewReader(res.Body, params["boundary"])
+	go func() {
+		select {
+		case <-ctx.Done():
+			res.Body.Close()
+		case <-r.stop:
+		}
+	}()
+	return r, nil
+}
+
+// Next gets the next part, waiting for it to arrive. It returns false
+// after the last part or an error, see Err.
+func (r *IncrementalReader) Next() (*GraphResponse, bool) {
+	for len(r.pending) == 0 {
+		if r.done || r.err != nil {
+			return nil, false
+		}
+		part, err := r.parts.NextPart()
+		if err == io.EOF {
+			r.done = true
+			return nil, false
+		}
+		if err != nil {
+			r.fail(err)
+			return nil, false
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			r.fail(err)
+			return nil, false
+		}
+		if strings.TrimSpace(string(body)) == "" {
+			continue
+		}
+		if err := r.queue(body); err != nil {
+			r.setErr(err)
+			return nil, false
+		}
+	}
+	next := r.pending[0]
+	r.pending = r.pending[1:]
+	if len(r.pending) == 0 && !next.HasNext {
+		r.done = true
+	}
+	return next, true
+}
+
+// queue decodes a part, splitting the incremental results it holds.
+func (r *IncrementalReader) queue(body []byte) Error {
+	var part struct {
+		GraphResponse
+		Incremental []*GraphResponse `json:"incremental"`
+	}
+	if err := json.Unmarshal(body, &part); err != nil {
+		return NewExecutionError(errors.Wrap(err, "decoding response"))
+	}
+	if len(part.Incremental) == 0 || len(part.Data) > 0 || len(part.Errors) > 0 {
+		first := part.GraphResponse
+		r.pending = append(r.pending, &first)
+	}
+	r.pending = append(r.pending, part.Incremental...)
+	// Only the last result of the part tells whether more follow.
+	for _, pending := range r.pending {
+		pending.HasNext = true
+	}
+	r.pending[len(r.pending)-1].HasNext = part.HasNext
+	return nil
+}
+
+func (r *IncrementalReader) fail(err error) {
+	if r.ctx.Err() != nil {
+		r.setErr(NewExecutionError(r.ctx.Err()))
+		return
+	}
+	r.setErr(NewExecutionError(errors.Wrap(err, "reading multipart response")))
+}
+
+// setErr sets the error stopping Next, counted and translated.
+func (r *IncrementalReader) setErr(err Error) {
+	if err == nil {
+		return
+	}
+	r.client.countError(err)
+	r.err = r.client.translate(err)
+}
+
+// Err gets the error that stopped Next, if any.
+func (r *IncrementalReader) Err() Error {
+	return r.err
+}
+
+// Close releases the response, abandoning the parts yet to arrive.
+func (r *IncrementalReader) Close() error {
+	if r.parts == nil {
+		return nil
+	}
+	select {
+	case <-r.stop:
+		return nil
+	default:
+		close(r.stop)
+	}
+	return r.res.Body.Close()
+}
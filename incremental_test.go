@@ -0,0 +1,108 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRunIncremental(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("Accept"), "multipart/mixed; deferSpec=20220824, application/json")
+		w.Header().Set("Content-Type", `multipart/mixed; boundary="-"`)
+		fmt.Fprint(w, "\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n")
+		fmt.Fprint(w, `{"data":{"merchant":{"name":"Cafe"}},"hasNext":true}`)
+		fmt.Fprint(w, "\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n")
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, `{"incremental":[{"data":{"address":{"city":"Berlin"}},"path":["merchant"],"label":"address"},{"items":[{"id":"r1"}],"path":["merchant","readers",0]}],"hasNext":true}`)
+		fmt.Fprint(w, "\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n")
+		fmt.Fprint(w, `{"hasNext":false}`)
+		fmt.Fprint(w, "\r\n-----\r\n")
+	}))
+	defer srv.Close()
+
+	parts, err := NewClient(srv.URL).RunIncremental(context.Background(), NewRequest(`query {
+		merchant { name ... @defer(label: "address") { address { city } } readers @stream { id } }
+	}`))
+	is.NoErr(err)
+	defer parts.Close()
+
+	var got []*GraphResponse
+	for part, ok := parts.Next(); ok; part, ok = parts.Next() {
+		got = append(got, part)
+	}
+	is.NoErr(parts.Err())
+	is.Equal(len(got), 4)
+
+	var merchant struct{ Merchant struct{ Name string } }
+	is.NoErr(got[0].Decode(&merchant))
+	is.Equal(merchant.Merchant.Name, "Cafe")
+	is.True(got[0].HasNext)
+	is.Equal(got[1].Label, "address")
+	is.Equal(got[1].Path, []interface{}{"merchant"})
+	is.Equal(string(got[2].Items[0]), `{"id":"r1"}`)
+	is.True(got[2].HasNext)
+	is.True(!got[3].HasNext)
+}
+
+func TestRunIncrementalSingleResponse(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"data":{"merchant":null},"errors":[{"message":"not found"}]}`)
+	}))
+	defer srv.Close()
+
+	parts, err := NewClient(srv.URL).RunIncremental(context.Background(), NewRequest("query { merchant { name } }"))
+	is.NoErr(err)
+	part, ok := parts.Next()
+	is.True(ok)
+	is.Equal(part.Errors[0].Message, "not found")
+	_, ok = parts.Next()
+	is.True(!ok)
+	is.NoErr(parts.Err())
+	is.NoErr(parts.Close())
+}
+
+func TestRunIncrementalErrorPath(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Fail") != "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"data":`)
+	}))
+	defer srv.Close()
+
+	var translated int
+	client := NewClient(srv.URL, WithErrorTranslator(func(err Error) Error {
+		translated++
+		return nil
+	}))
+	req := NewRequest("query { merchant { name } }")
+	req.Header("X-Fail", "1")
+	_, err := client.RunIncremental(context.Background(), req)
+	is.True(err != nil)
+
+	parts, err := client.RunIncremental(context.Background(), NewRequest("query { merchant { name } }"))
+	is.NoErr(err)
+	_, ok := parts.Next()
+	is.True(!ok)
+	is.True(parts.Err() != nil)
+	is.True(parts.Err() != nil)
+	// The errors of the request and of the parts are translated and
+	// counted once, as the ones of Run.
+	is.Equal(translated, 2)
+	is.Equal(client.Stats().Errors, map[string]int64{"request": 1, "execution": 1})
+}
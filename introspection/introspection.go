@@ -0,0 +1,218 @@
+// Package introspection fetches the schema of a GraphQL endpoint with the
+// standard introspection query and prints it as SDL.
+package introspection
+
+import (
+	"context"
+
+	"github.com/sumup/graphql"
+)
+
+// Query is the standard introspection query.
+const Query = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      locations
+      isRepeatable
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+              ofType {
+                kind
+                name
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// Type kinds reported by introspection.
+const (
+	KindScalar      = "SCALAR"
+	KindObject      = "OBJECT"
+	KindInterface   = "INTERFACE"
+	KindUnion       = "UNION"
+	KindEnum        = "ENUM"
+	KindInputObject = "INPUT_OBJECT"
+	KindList        = "LIST"
+	KindNonNull     = "NON_NULL"
+)
+
+type (
+	// Response is the data returned by Query.
+	Response struct {
+		Schema Schema `json:"__schema"`
+	}
+
+	// Schema describes the types and directives of a schema.
+	Schema struct {
+		QueryType        *TypeName   `json:"queryType"`
+		MutationType     *TypeName   `json:"mutationType"`
+		SubscriptionType *TypeName   `json:"subscriptionType"`
+		Types            []Type      `json:"types"`
+		Directives       []Directive `json:"directives"`
+	}
+
+	// TypeName references a named type.
+	TypeName struct {
+		Name string `json:"name"`
+	}
+
+	// Type is a named type of the schema.
+	Type struct {
+		Kind          string       `json:"kind"`
+		Name          string       `json:"name"`
+		Description   string       `json:"description"`
+		Fields        []Field      `json:"fields"`
+		InputFields   []InputValue `json:"inputFields"`
+		Interfaces    []TypeRef    `json:"interfaces"`
+		EnumValues    []EnumValue  `json:"enumValues"`
+		PossibleTypes []TypeRef    `json:"possibleTypes"`
+	}
+
+	// TypeRef references a type, possibly wrapped in lists and non-null.
+	TypeRef struct {
+		Kind   string   `json:"kind"`
+		Name   string   `json:"name"`
+		OfType *TypeRef `json:"ofType"`
+	}
+
+	// Field is a field of an object or interface.
+	Field struct {
+		Name              string       `json:"name"`
+		Description       string       `json:"description"`
+		Args              []InputValue `json:"args"`
+		Type              TypeRef      `json:"type"`
+		IsDeprecated      bool         `json:"isDeprecated"`
+		DeprecationReason string       `json:"deprecationReason"`
+	}
+
+	// InputValue is an argument or a field of an input object.
+	InputValue struct {
+		Name         string  `json:"name"`
+		Description  string  `json:"description"`
+		Type         TypeRef `json:"type"`
+		DefaultValue *string `json:"defaultValue"`
+	}
+
+	// EnumValue is a member of an enum.
+	EnumValue struct {
+		Name              string `json:"name"`
+		Description       string `json:"description"`
+		IsDeprecated      bool   `json:"isDeprecated"`
+		DeprecationReason string `json:"deprecationReason"`
+	}
+
+	// Directive is a directive declared by the schema.
+	Directive struct {
+		Name         string       `json:"name"`
+		Description  string       `json:"description"`
+		Locations    []string     `json:"locations"`
+		IsRepeatable bool         `json:"isRepeatable"`
+		Args         []InputValue `json:"args"`
+	}
+)
+
+// Fetch runs the introspection query against the client's endpoint.
+func Fetch(ctx context.Context, client graphql.GraphClient) (*Response, graphql.Error) {
+	var resp Response
+	if err := client.Run(ctx, graphql.NewRequest(Query), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Type gets the named type, or nil when the schema has no such type.
+func (s *Schema) Type(name string) *Type {
+	for i := range s.Types {
+		if s.Types[i].Name == name {
+			return &s.Types[i]
+		}
+	}
+	return nil
+}
+
+// Named unwraps the list and non-null wrappers of t.
+func (t TypeRef) Named() TypeRef {
+	for t.OfType != nil && (t.Kind == KindList || t.Kind == KindNonNull) {
+		t = *t.OfType
+	}
+	return t
+}
+
+// String formats the type reference as in SDL, e.g. "[String!]!".
+func (t TypeRef) String() string {
+	switch t.Kind {
+	case KindNonNull:
+		if t.OfType != nil {
+			return t.OfType.String() + "!"
+		}
+	case KindList:
+		if t.OfType != nil {
+			return "[" + t.OfType.String() + "]"
+		}
+	}
+	return t.Name
+}
@@ -0,0 +1,108 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/sumup/graphql"
+)
+
+const testSchema = `{"data":{"__schema":{
+	"queryType":{"name":"Query"},
+	"mutationType":null,
+	"subscriptionType":null,
+	"directives":[
+		{"name":"deprecated","locations":["FIELD_DEFINITION"],"args":[]},
+		{"name":"cost","description":"Query cost.","locations":["FIELD_DEFINITION","OBJECT"],"args":[{"name":"weight","type":{"kind":"NON_NULL","ofType":{"kind":"SCALAR","name":"Int"}}}]}
+	],
+	"types":[
+		{"kind":"SCALAR","name":"String"},
+		{"kind":"SCALAR","name":"DateTime","description":"RFC 3339 timestamp."},
+		{"kind":"OBJECT","name":"__Type","fields":[]},
+		{"kind":"OBJECT","name":"Query","fields":[
+			{"name":"merchant","args":[{"name":"code","type":{"kind":"NON_NULL","ofType":{"kind":"SCALAR","name":"String"}}}],"type":{"kind":"OBJECT","name":"Merchant"}},
+			{"name":"merchants","args":[{"name":"first","type":{"kind":"SCALAR","name":"Int"},"defaultValue":"10"}],"type":{"kind":"NON_NULL","ofType":{"kind":"LIST","ofType":{"kind":"NON_NULL","ofType":{"kind":"OBJECT","name":"Merchant"}}}}}
+		]},
+		{"kind":"OBJECT","name":"Merchant","description":"A merchant\naccount.","interfaces":[{"kind":"INTERFACE","name":"Node"}],"fields":[
+			{"name":"id","args":[],"type":{"kind":"NON_NULL","ofType":{"kind":"SCALAR","name":"ID"}}},
+			{"name":"status","args":[],"type":{"kind":"ENUM","name":"Status"},"isDeprecated":true,"deprecationReason":"Use state."}
+		]},
+		{"kind":"INTERFACE","name":"Node","fields":[{"name":"id","args":[],"type":{"kind":"NON_NULL","ofType":{"kind":"SCALAR","name":"ID"}}}]},
+		{"kind":"ENUM","name":"Status","enumValues":[{"name":"ACTIVE"},{"name":"CLOSED","isDeprecated":true}]},
+		{"kind":"UNION","name":"Result","possibleTypes":[{"kind":"OBJECT","name":"Merchant"}]},
+		{"kind":"INPUT_OBJECT","name":"Filter","inputFields":[{"name":"status","type":{"kind":"ENUM","name":"Status"},"defaultValue":"ACTIVE"}]}
+	]
+}}}`
+
+const testSDL = `"Query cost."
+directive @cost(weight: Int!) on FIELD_DEFINITION | OBJECT
+
+"RFC 3339 timestamp."
+scalar DateTime
+
+input Filter {
+  status: Status = ACTIVE
+}
+
+"""
+A merchant
+account.
+"""
+type Merchant implements Node {
+  id: ID!
+  status: Status @deprecated(reason: "Use state.")
+}
+
+interface Node {
+  id: ID!
+}
+
+type Query {
+  merchant(code: String!): Merchant
+  merchants(first: Int = 10): [Merchant!]!
+}
+
+union Result = Merchant
+
+enum Status {
+  ACTIVE
+  CLOSED @deprecated
+}
+`
+
+func TestFetch(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		is.Equal(body.Query, Query)
+		_, _ = io.WriteString(w, testSchema)
+	}))
+	defer srv.Close()
+
+	resp, err := Fetch(context.Background(), graphql.NewClient(srv.URL))
+	is.NoErr(err)
+	is.Equal(resp.Schema.QueryType.Name, "Query")
+	is.Equal(resp.Schema.Type("Query").Fields[1].Type.String(), "[Merchant!]!")
+	is.Equal(resp.Schema.Type("Query").Fields[1].Type.Named().Name, "Merchant")
+	is.True(resp.Schema.Type("Missing") == nil)
+}
+
+func TestSDL(t *testing.T) {
+	is := is.New(t)
+
+	var resp struct {
+		Data Response
+	}
+	is.NoErr(json.Unmarshal([]byte(testSchema), &resp))
+
+	is.Equal(resp.Data.Schema.SDL(), testSDL)
+}
@@ -0,0 +1,178 @@
+package introspection
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var builtinScalars = map[string]bool{
+	"String":  true,
+	"Int":     true,
+	"Float":   true,
+	"Boolean": true,
+	"ID":      true,
+}
+
+var builtinDirectives = map[string]bool{
+	"skip":        true,
+	"include":     true,
+	"deprecated":  true,
+	"specifiedBy": true,
+}
+
+// SDL prints the schema in the GraphQL schema definition language,
+// omitting built-in scalars, directives and introspection types.
+func (s *Schema) SDL() string {
+	var b strings.Builder
+
+	if s.needsSchemaDefinition() {
+		b.WriteString("schema {\n")
+		if s.QueryType != nil {
+			b.WriteString("  query: " + s.QueryType.Name + "\n")
+		}
+		if s.MutationType != nil {
+			b.WriteString("  mutation: " + s.MutationType.Name + "\n")
+		}
+		if s.SubscriptionType != nil {
+			b.WriteString("  subscription: " + s.SubscriptionType.Name + "\n")
+		}
+		b.WriteString("}\n\n")
+	}
+
+	directives := append([]Directive(nil), s.Directives...)
+	sort.Slice(directives, func(i, j int) bool { return directives[i].Name < directives[j].Name })
+	for _, d := range directives {
+		if builtinDirectives[d.Name] {
+			continue
+		}
+		writeDescription(&b, d.Description, "")
+		b.WriteString("directive @" + d.Name)
+		writeArgs(&b, d.Args)
+		if d.IsRepeatable {
+			b.WriteString(" repeatable")
+		}
+		b.WriteString(" on " + strings.Join(d.Locations, " | ") + "\n\n")
+	}
+
+	types := append([]Type(nil), s.Types...)
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+	for _, t := range types {
+		if strings.HasPrefix(t.Name, "__") || (t.Kind == KindScalar && builtinScalars[t.Name]) {
+			continue
+		}
+		writeType(&b, t)
+		b.WriteString("\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// needsSchemaDefinition reports whether the root types differ from the
+// default Query, Mutation and Subscription names.
+func (s *Schema) needsSchemaDefinition() bool {
+	return (s.QueryType != nil && s.QueryType.Name != "Query") ||
+		(s.MutationType != nil && s.MutationType.Name != "Mutation") ||
+		(s.SubscriptionType != nil && s.SubscriptionType.Name != "Subscription")
+}
+
+func writeType(b *strings.Builder, t Type) {
+	writeDescription(b, t.Description, "")
+	switch t.Kind {
+	case KindScalar:
+		b.WriteString("scalar " + t.Name + "\n")
+	case KindObject, KindInterface:
+		if t.Kind == KindObject {
+			b.WriteString("type " + t.Name)
+		} else {
+			b.WriteString("interface " + t.Name)
+		}
+		if len(t.Interfaces) > 0 {
+			names := make([]string, len(t.Interfaces))
+			for i, iface := range t.Interfaces {
+				names[i] = iface.Name
+			}
+			b.WriteString(" implements " + strings.Join(names, " & "))
+		}
+		b.WriteString(" {\n")
+		for _, f := range t.Fields {
+			writeDescription(b, f.Description, "  ")
+			b.WriteString("  " + f.Name)
+			writeArgs(b, f.Args)
+			b.WriteString(": " + f.Type.String())
+			writeDeprecated(b, f.IsDeprecated, f.DeprecationReason)
+			b.WriteString("\n")
+		}
+		b.WriteString("}\n")
+	case KindUnion:
+		names := make([]string, len(t.PossibleTypes))
+		for i, possible := range t.PossibleTypes {
+			names[i] = possible.Name
+		}
+		b.WriteString("union " + t.Name + " = " + strings.Join(names, " | ") + "\n")
+	case KindEnum:
+		b.WriteString("enum " + t.Name + " {\n")
+		for _, v := range t.EnumValues {
+			writeDescription(b, v.Description, "  ")
+			b.WriteString("  " + v.Name)
+			writeDeprecated(b, v.IsDeprecated, v.DeprecationReason)
+			b.WriteString("\n")
+		}
+		b.WriteString("}\n")
+	case KindInputObject:
+		b.WriteString("input " + t.Name + " {\n")
+		for _, f := range t.InputFields {
+			writeDescription(b, f.Description, "  ")
+			b.WriteString("  ")
+			writeInputValue(b, f)
+			b.WriteString("\n")
+		}
+		b.WriteString("}\n")
+	}
+}
+
+func writeArgs(b *strings.Builder, args []InputValue) {
+	if len(args) == 0 {
+		return
+	}
+	b.WriteString("(")
+	for i, arg := range args {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		writeInputValue(b, arg)
+	}
+	b.WriteString(")")
+}
+
+func writeInputValue(b *strings.Builder, v InputValue) {
+	b.WriteString(v.Name + ": " + v.Type.String())
+	if v.DefaultValue != nil {
+		b.WriteString(" = " + *v.DefaultValue)
+	}
+}
+
+func writeDeprecated(b *strings.Builder, deprecated bool, reason string) {
+	if !deprecated {
+		return
+	}
+	b.WriteString(" @deprecated")
+	if reason != "" && reason != "No longer supported" {
+		b.WriteString("(reason: " + strconv.Quote(reason) + ")")
+	}
+}
+
+func writeDescription(b *strings.Builder, description, indent string) {
+	if description == "" {
+		return
+	}
+	if !strings.Contains(description, "\n") {
+		b.WriteString(indent + strconv.Quote(description) + "\n")
+		return
+	}
+	b.WriteString(indent + `"""` + "\n")
+	for _, line := range strings.Split(description, "\n") {
+		b.WriteString(indent + line + "\n")
+	}
+	b.WriteString(indent + `"""` + "\n")
+}
@@ -0,0 +1,154 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrConcurrencyLimit is the cause of the *ExecutionError returned for
+// calls exceeding WithMaxConcurrency when the client uses LimitFailFast.
+var ErrConcurrencyLimit = errors.New("too many concurrent calls")
+
+// LimitPolicy decides what happens to calls exceeding WithMaxConcurrency.
+type LimitPolicy int
+
+const (
+	// LimitWait blocks calls until another call completes or their
+	// context is done. It is the default.
+	LimitWait LimitPolicy = iota
+	// LimitFailFast fails calls at once with ErrConcurrencyLimit.
+	LimitFailFast
+)
+
+// Priority ranks the operations sharing a client, see Req.SetPriority.
+type Priority int
+
+const (
+	// PriorityLow suits background work such as reporting.
+	PriorityLow Priority = iota - 1
+	// PriorityNormal is the priority of operations by default.
+	PriorityNormal
+	// PriorityHigh suits calls on the critical path, such as checkout.
+	PriorityHigh
+)
+
+// WithMaxConcurrency limits the calls in flight to n, so a burst of
+// goroutines cannot open an unbounded number of connections to the
+// server. Calls past the limit wait, or fail with LimitFailFast.
+//
+// Waiting calls proceed by priority, then in order of arrival, and low
+// priority calls leave a slot free for the others when n is above 1, so
+// background work cannot starve critical calls.
+func WithMaxConcurrency(n int) ClientOption {
+	return func(client *Client) {
+		client.limiter = nil
+		if n > 0 {
+			client.limiter = &limiter{max: n}
+		}
+	}
+}
+
+// WithLimitPolicy sets what happens to calls exceeding WithMaxConcurrency.
+func WithLimitPolicy(policy LimitPolicy) ClientOption {
+	return func(client *Client) {
+		client.limitPolicy = policy
+	}
+}
+
+// limiter is a semaphore handing out its slots by priority.
+type limiter struct {
+	mu       sync.Mutex
+	max      int
+	inFlight int
+	// waiters holds the calls waiting for a slot by priority, highest
+	// first.
+	waiters [3][]chan struct{}
+}
+
+func waitersIndex(p Priority) int {
+	switch {
+	case p > PriorityNormal:
+		return 0
+	case p < PriorityNormal:
+		return 2
+	}
+	return 1
+}
+
+// slots gets the number of slots calls of priority index i may use.
+func (l *limiter) slots(i int) int {
+	if i == 2 && l.max > 1 {
+		return l.max - 1
+	}
+	return l.max
+}
+
+// tryAcquire takes a slot if one is free and no call of the same or a
+// higher priority waits for it.
+func (l *limiter) tryAcquire(i int) bool {
+	for j := 0; j <= i; j++ {
+		if len(l.waiters[j]) > 0 {
+			return false
+		}
+	}
+	if l.inFlight >= l.slots(i) {
+		return false
+	}
+	l.inFlight++
+	return true
+}
+
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	for i := range l.waiters {
+		for len(l.waiters[i]) > 0 && l.inFlight < l.slots(i) {
+			close(l.waiters[i][0])
+			l.waiters[i] = l.waiters[i][1:]
+			l.inFlight++
+		}
+	}
+}
+
+// acquire takes a slot for a call of op, returning the function releasing
+// it.
+func (c *Client) acquire(ctx context.Context, op Operation) (func(), Error) {
+	l := c.limiter
+	if l == nil {
+		return func() {}, nil
+	}
+	i := waitersIndex(op.Request().priority)
+	l.mu.Lock()
+	if l.tryAcquire(i) {
+		l.mu.Unlock()
+		return l.release, nil
+	}
+	if c.limitPolicy == LimitFailFast {
+		l.mu.Unlock()
+		return nil, NewExecutionError(errors.Wrapf(ErrConcurrencyLimit, "limit of %d reached", l.max))
+	}
+	granted := make(chan struct{})
+	l.waiters[i] = append(l.waiters[i], granted)
+	l.mu.Unlock()
+
+	select {
+	case <-granted:
+		return l.release, nil
+	case <-ctx.Done():
+	}
+	l.mu.Lock()
+	for j, w := range l.waiters[i] {
+		if w == granted {
+			l.waiters[i] = append(l.waiters[i][:j], l.waiters[i][j+1:]...)
+			l.mu.Unlock()
+			return nil, NewExecutionError(ctx.Err())
+		}
+	}
+	l.mu.Unlock()
+	// The slot was granted as the context ended, pass it on.
+	l.release()
+	return nil, NewExecutionError(ctx.Err())
+}
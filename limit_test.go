@@ -0,0 +1,131 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+)
+
+func TestWithMaxConcurrency(t *testing.T) {
+	is := is.New(t)
+
+	var (
+		mu                sync.Mutex
+		inFlight, maxSeen int
+		unblock           = make(chan struct{})
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxSeen {
+			maxSeen = inFlight
+		}
+		mu.Unlock()
+		<-unblock
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithMaxConcurrency(2))
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			is.NoErr(client.Run(context.Background(), NewRequest("query {}"), nil))
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(unblock)
+	wg.Wait()
+	is.Equal(maxSeen, 2)
+}
+
+func TestWithLimitPolicy(t *testing.T) {
+	is := is.New(t)
+
+	unblock := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithMaxConcurrency(1), WithLimitPolicy(LimitFailFast))
+	done := make(chan Error)
+	go func() {
+		done <- client.Run(context.Background(), NewRequest("query {}"), nil)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	err := client.Run(context.Background(), NewRequest("query {}"), nil)
+	is.True(errors.Is(err, ErrConcurrencyLimit))
+	close(unblock)
+	is.NoErr(<-done)
+
+	// Waiting calls give up with their context.
+	blocking := NewClient(srv.URL, WithMaxConcurrency(1))
+	blocking.limiter.inFlight = 1
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = blocking.Run(ctx, NewRequest("query {}"), nil)
+	is.True(errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestLimiterPriority(t *testing.T) {
+	is := is.New(t)
+
+	client := NewClient("", WithMaxConcurrency(2))
+	acquired := make(chan Priority, 2)
+	wait := func(p Priority) {
+		req := NewRequest("query {}")
+		req.Request().SetPriority(p)
+		release, err := client.acquire(context.Background(), req)
+		is.NoErr(err)
+		acquired <- p
+		release()
+	}
+
+	// Low priority calls leave the last slot to the others.
+	client.limiter.inFlight = 1
+	client.limiter.mu.Lock()
+	is.True(!client.limiter.tryAcquire(waitersIndex(PriorityLow)))
+	is.True(client.limiter.tryAcquire(waitersIndex(PriorityNormal)))
+	client.limiter.mu.Unlock()
+
+	go wait(PriorityLow)
+	for {
+		client.limiter.mu.Lock()
+		n := len(client.limiter.waiters[2])
+		client.limiter.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	go wait(PriorityHigh)
+	for {
+		client.limiter.mu.Lock()
+		n := len(client.limiter.waiters[0])
+		client.limiter.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	client.limiter.release()
+	is.Equal(<-acquired, PriorityHigh)
+	client.limiter.release()
+	is.Equal(<-acquired, PriorityLow)
+}
@@ -0,0 +1,205 @@
+// Package lint checks the GraphQL documents passed as string literals to
+// graphql.NewRequest and graphql.NewMutation, reporting syntax errors,
+// undeclared or unused variables and unknown fragments before the
+// operation is ever sent.
+//
+// Only constant string literals, and concatenations of them, are checked;
+// documents built at run time are skipped.
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	gqlparser "github.com/sumup/graphql/parser"
+)
+
+// ImportPath is the import path of the client package whose calls are
+// checked.
+const ImportPath = "github.com/sumup/graphql"
+
+// constructors are the functions of the client package taking a document.
+var constructors = map[string]bool{
+	"NewRequest":  true,
+	"NewMutation": true,
+}
+
+// Diagnostic is a problem found in a document.
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Pos, d.Message)
+}
+
+// File checks the documents in a parsed Go file. The file must have been
+// parsed with fset.
+func File(fset *token.FileSet, f *ast.File) []Diagnostic {
+	names := importNames(f)
+	if len(names) == 0 {
+		return nil
+	}
+
+	var diags []Diagnostic
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !constructors[sel.Sel.Name] {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); !ok || !names[pkg.Name] {
+			return true
+		}
+		if lit, src, ok := document(call.Args[0]); ok {
+			diags = append(diags, check(fset, lit, src)...)
+		}
+		return true
+	})
+	return diags
+}
+
+// Dir checks the Go files below root, skipping vendor, testdata and
+// hidden directories. Diagnostics are sorted by position.
+func Dir(root string) ([]Diagnostic, error) {
+	fset := token.NewFileSet()
+	var diags []Diagnostic
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		diags = append(diags, File(fset, f)...)
+		return nil
+	})
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i].Pos, diags[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	return diags, err
+}
+
+// importNames gets the names the client package is imported as.
+func importNames(f *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || path != ImportPath {
+			continue
+		}
+		name := "graphql"
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name != "_" && name != "." {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// document gets the value of a constant string expression and the literal
+// to report positions against. Concatenations are reported against a
+// placeholder at the start of the expression.
+func document(expr ast.Expr) (lit *ast.BasicLit, src string, ok bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return nil, "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		if err != nil {
+			return nil, "", false
+		}
+		return e, s, true
+	case *ast.ParenExpr:
+		return document(e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return nil, "", false
+		}
+		x, xs, ok := document(e.X)
+		if !ok {
+			return nil, "", false
+		}
+		_, ys, ok := document(e.Y)
+		if !ok {
+			return nil, "", false
+		}
+		return &ast.BasicLit{ValuePos: x.ValuePos, Kind: token.STRING, Value: `""`}, xs + ys, true
+	}
+	return nil, "", false
+}
+
+func check(fset *token.FileSet, lit *ast.BasicLit, src string) []Diagnostic {
+	doc, err := gqlparser.Parse(src)
+	if err != nil {
+		if serr, ok := err.(*gqlparser.SyntaxError); ok {
+			return []Diagnostic{{Pos: position(fset, lit, src, serr.Position), Message: "graphql: " + serr.Message}}
+		}
+		return []Diagnostic{{Pos: fset.Position(lit.Pos()), Message: "graphql: " + err.Error()}}
+	}
+	var diags []Diagnostic
+	for _, verr := range gqlparser.Validate(doc) {
+		diags = append(diags, Diagnostic{Pos: position(fset, lit, src, verr.Position), Message: "graphql: " + verr.Message})
+	}
+	return diags
+}
+
+// position maps a position in the document to the Go source. Only raw
+// string literals map exactly, other documents are reported at the
+// start of the expression.
+func position(fset *token.FileSet, lit *ast.BasicLit, src string, pos gqlparser.Position) token.Position {
+	start := fset.Position(lit.Pos())
+	if !strings.HasPrefix(lit.Value, "`") || strings.Contains(lit.Value, "\r") {
+		return start
+	}
+	p := start
+	p.Column++
+	p.Offset++
+	line, col := 1, 1
+	for _, r := range src {
+		if line == pos.Line && col == pos.Column {
+			break
+		}
+		n := len(string(r))
+		if r == '\n' {
+			line++
+			col = 1
+			p.Line++
+			p.Column = 1
+		} else {
+			col++
+			p.Column += n
+		}
+		p.Offset += n
+	}
+	return p
+}
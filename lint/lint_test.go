@@ -0,0 +1,66 @@
+package lint
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const source = "package example\n" +
+	"\n" +
+	"import gql \"github.com/sumup/graphql\"\n" +
+	"\n" +
+	"var (\n" +
+	"\tok      = gql.NewRequest(`query ($id: ID!) { merchant(id: $id) { name } }`)\n" +
+	"\tsyntax  = gql.NewRequest(`{ merchant(id: 1) { name }`)\n" +
+	"\tundecl  = gql.NewMutation(`mutation {\n" +
+	"  refund(id: $id) { successful }\n" +
+	"}`)\n" +
+	"\tjoined  = gql.NewRequest(\"query ($id: ID!) \" + \"{ a }\")\n" +
+	"\truntime = gql.NewRequest(query)\n" +
+	")\n"
+
+func TestFile(t *testing.T) {
+	is := is.New(t)
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "example.go", source, 0)
+	is.NoErr(err)
+
+	var got []string
+	for _, d := range File(fset, f) {
+		got = append(got, d.String())
+	}
+	is.Equal(got, []string{
+		`example.go:7:54: graphql: expected name, found end of document`,
+		`example.go:9:3: graphql: variable $id is not declared`,
+		`example.go:11:27: graphql: variable $id is declared but not used`,
+	})
+}
+
+func TestFileWithoutImport(t *testing.T) {
+	is := is.New(t)
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "example.go", "package example\n\nvar q = graphql.NewRequest(`{`)\n", 0)
+	is.NoErr(err)
+	is.Equal(len(File(fset, f)), 0)
+}
+
+func TestDir(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+	is.NoErr(os.MkdirAll(filepath.Join(dir, "vendor"), 0o755))
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "example.go"), []byte(source), 0o644))
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "vendor", "example.go"), []byte(source), 0o644))
+
+	diags, err := Dir(dir)
+	is.NoErr(err)
+	is.Equal(len(diags), 3)
+}
@@ -0,0 +1,171 @@
+// Package loadtest fires an operation at a target rate with bounded
+// concurrency and reports latency percentiles and an error breakdown
+// based on the error model of the graphql package.
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sumup/graphql"
+)
+
+// Config configures a load test.
+type Config struct {
+	// Rate is the number of operations started per second.
+	Rate float64
+	// Concurrency limits the operations in flight, 1 by default. Ticks
+	// arriving while every worker is busy are counted as dropped.
+	Concurrency int
+	// Duration stops the test after the given time.
+	Duration time.Duration
+	// Requests stops the test after the given number of operations.
+	Requests int
+}
+
+// Report summarizes a load test.
+type Report struct {
+	Requests int
+	Errors   int
+	Dropped  int
+	Duration time.Duration
+
+	// Latency percentiles of all executed operations.
+	P50, P90, P99, Max time.Duration
+
+	// ErrorsByClass counts errors by class, e.g. "http 502",
+	// "graphql internal" or "execution".
+	ErrorsByClass map[string]int
+}
+
+// Run executes the operations returned by newOp against client until the
+// duration or request count of cfg is reached or ctx is done, then waits
+// for the operations in flight. A new
+// operation is requested per call since operations are not safe for
+// concurrent use.
+func Run(ctx context.Context, client graphql.GraphClient, newOp func() graphql.Operation, cfg Config) (*Report, error) {
+	if cfg.Rate <= 0 {
+		return nil, fmt.Errorf("loadtest: rate must be positive")
+	}
+	if cfg.Duration <= 0 && cfg.Requests <= 0 {
+		return nil, fmt.Errorf("loadtest: a duration or request count is required")
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	// The duration only stops scheduling: the operations in flight run
+	// with ctx and are waited for, so they are not reported as failed.
+	schedule := ctx
+	if cfg.Duration > 0 {
+		var cancel context.CancelFunc
+		schedule, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		report    = &Report{ErrorsByClass: make(map[string]int)}
+		wg        sync.WaitGroup
+		tickets   = make(chan struct{})
+	)
+	for i := 0; i < cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range tickets {
+				start := time.Now()
+				err := client.Run(ctx, newOp(), nil)
+				latency := time.Since(start)
+
+				mu.Lock()
+				latencies = append(latencies, latency)
+				if err != nil {
+					report.Errors++
+					report.ErrorsByClass[Classify(err)]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	start := time.Now()
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.Rate))
+	defer ticker.Stop()
+	started := 0
+loop:
+	for cfg.Requests <= 0 || started < cfg.Requests {
+		select {
+		case tickets <- struct{}{}:
+			started++
+		default:
+			report.Dropped++
+		}
+		select {
+		case <-schedule.Done():
+			break loop
+		case <-ticker.C:
+		}
+	}
+	close(tickets)
+	wg.Wait()
+
+	report.Duration = time.Since(start)
+	report.Requests = len(latencies)
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		report.P50 = percentile(latencies, 0.50)
+		report.P90 = percentile(latencies, 0.90)
+		report.P99 = percentile(latencies, 0.99)
+		report.Max = latencies[len(latencies)-1]
+	}
+	return report, nil
+}
+
+// Classify groups an error for reporting.
+func Classify(err graphql.Error) string {
+	switch err := err.(type) {
+	case *graphql.RequestError:
+		return fmt.Sprintf("http %d", err.Response().StatusCode)
+	case *graphql.GraphQLError:
+		if code := err.Code(); code != "" {
+			return "graphql " + code
+		}
+		return "graphql"
+	}
+	return "execution"
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(float64(len(sorted))*p+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+// Print writes the report in a human readable form.
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "requests: %d in %s (%.1f/s)\n", r.Requests, r.Duration.Round(time.Millisecond), float64(r.Requests)/r.Duration.Seconds())
+	fmt.Fprintf(w, "errors:   %d\n", r.Errors)
+	if r.Dropped > 0 {
+		fmt.Fprintf(w, "dropped:  %d (concurrency too low for the rate)\n", r.Dropped)
+	}
+	fmt.Fprintf(w, "latency:  p50 %s, p90 %s, p99 %s, max %s\n", r.P50, r.P90, r.P99, r.Max)
+
+	classes := make([]string, 0, len(r.ErrorsByClass))
+	for class := range r.ErrorsByClass {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		fmt.Fprintf(w, "  %-24s %d\n", class, r.ErrorsByClass[class])
+	}
+}
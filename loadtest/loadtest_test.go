@@ -0,0 +1,79 @@
+package loadtest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+
+	"github.com/sumup/graphql"
+)
+
+func TestRun(t *testing.T) {
+	is := is.New(t)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) % 3 {
+		case 0:
+			w.WriteHeader(http.StatusBadGateway)
+		case 1:
+			_, _ = io.WriteString(w, `{"data":{}}`)
+		case 2:
+			_, _ = io.WriteString(w, `{"errors":[{"message":"boom","extensions":{"code":"INTERNAL"}}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	report, err := Run(context.Background(), graphql.NewClient(srv.URL), func() graphql.Operation {
+		return graphql.NewRequest("{ ping }")
+	}, Config{Rate: 1000, Concurrency: 2, Requests: 9})
+	is.NoErr(err)
+	is.Equal(report.Requests+report.Dropped >= 9, true)
+	is.Equal(report.Requests, int(atomic.LoadInt32(&calls)))
+	is.True(report.P50 <= report.P99)
+	is.True(report.P99 <= report.Max)
+	is.Equal(report.Errors, report.ErrorsByClass["http 502"]+report.ErrorsByClass["graphql internal"])
+}
+
+func TestRunDrainsAfterDuration(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	report, err := Run(context.Background(), graphql.NewClient(srv.URL), func() graphql.Operation {
+		return graphql.NewRequest("{ ping }")
+	}, Config{Rate: 100, Concurrency: 2, Duration: 20 * time.Millisecond})
+	is.NoErr(err)
+	// The operations in flight when the duration ends complete.
+	is.True(report.Requests > 0)
+	is.Equal(report.Errors, 0)
+}
+
+func TestRunValidation(t *testing.T) {
+	is := is.New(t)
+	client := graphql.NewClient("http://localhost")
+	newOp := func() graphql.Operation { return graphql.NewRequest("{ ping }") }
+
+	_, err := Run(context.Background(), client, newOp, Config{Duration: time.Second})
+	is.Equal(err.Error(), "loadtest: rate must be positive")
+
+	_, err = Run(context.Background(), client, newOp, Config{Rate: 1})
+	is.Equal(err.Error(), "loadtest: a duration or request count is required")
+}
+
+func TestClassify(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(Classify(graphql.NewRequestError(&http.Response{StatusCode: 503})), "http 503")
+	is.Equal(Classify(graphql.NewGraphQLError([]graphql.GraphErr{{Code: "NOT_FOUND"}}, nil)), "graphql not_found")
+	is.Equal(Classify(graphql.NewGraphQLError([]graphql.GraphErr{{Message: "boom"}}, nil)), "graphql")
+	is.Equal(Classify(graphql.NewExecutionError(context.Canceled)), "execution")
+}
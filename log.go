@@ -0,0 +1,196 @@
+package graphql
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogCategory selects the debug information passed to Client.Log.
+// Categories are combined with |.
+type LogCategory uint
+
+// Log categories.
+const (
+	// LogRequest logs the endpoint, headers and size of requests.
+	LogRequest LogCategory = 1 << iota
+	// LogResponse logs the status, headers and size of responses.
+	LogResponse
+	// LogBody logs the query, variables and response bodies, which may
+	// hold personal data.
+	LogBody
+	// LogTiming logs how long the server took to respond, broken down
+	// into DNS, connect, TLS and server time.
+	LogTiming
+
+	// LogAll logs everything and is the default once Client.Log is set.
+	LogAll = LogRequest | LogResponse | LogBody | LogTiming
+)
+
+// WithLogCategories limits the information passed to Client.Log, e.g.
+// LogTiming|LogResponse in production to log timings without dumping
+// bodies.
+func WithLogCategories(categories LogCategory) ClientOption {
+	return func(client *Client) {
+		client.logCategories = categories
+	}
+}
+
+// logs reports whether messages of the category are logged, so callers
+// can skip preparing costly arguments.
+func (c *Client) logs(category LogCategory) bool {
+	return c.Log != nil && c.logCategories&category != 0
+}
+
+// logf logs a message in the category, with fields for structured
+// formatters.
+func (c *Client) logf(category LogCategory, fields []LogField, format string, args ...interface{}) {
+	if !c.logs(category) {
+		return
+	}
+	c.Log(c.logFormatter.Format(LogEntry{
+		Category: category,
+		Message:  fmt.Sprintf(format, args...),
+		Fields:   fields,
+	}))
+}
+
+// milliseconds converts d for structured log fields.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// WithRedactedHeaders masks the values of the given headers in logs, in
+// addition to Authorization, Proxy-Authorization, Cookie and Set-Cookie
+// which are masked by default.
+func WithRedactedHeaders(keys ...string) ClientOption {
+	return func(client *Client) {
+		if client.redactedHeaders == nil {
+			client.redactedHeaders = make(map[string]struct{})
+		}
+		for _, key := range keys {
+			client.redactedHeaders[http.CanonicalHeaderKey(key)] = struct{}{}
+		}
+	}
+}
+
+// WithoutHeaderRedaction logs every header as it is sent or received,
+// for local debugging only.
+func WithoutHeaderRedaction() ClientOption {
+	return func(client *Client) {
+		client.redactedHeaders = nil
+	}
+}
+
+// redact returns h with the values of redacted headers masked.
+func (c *Client) redact(h http.Header) http.Header {
+	if len(c.redactedHeaders) == 0 {
+		return h
+	}
+	var masked http.Header
+	for key := range c.redactedHeaders {
+		if _, ok := h[key]; !ok {
+			continue
+		}
+		if masked == nil {
+			masked = h.Clone()
+		}
+		masked[key] = []string{redactedValue}
+	}
+	if masked == nil {
+		return h
+	}
+	return masked
+}
+
+// timings records the phases of a request with httptrace. Callbacks may
+// run on other goroutines.
+type timings struct {
+	mu                               sync.Mutex
+	dnsStart, connectStart, tlsStart time.Time
+	dns, connect, tls                time.Duration
+	wroteRequest, firstByte          time.Time
+}
+
+func (t *timings) trace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.set(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.since(&t.dns, &t.dnsStart) },
+		ConnectStart:         func(string, string) { t.set(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { t.since(&t.connect, &t.connectStart) },
+		TLSHandshakeStart:    func() { t.set(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.since(&t.tls, &t.tlsStart) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.set(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.set(&t.firstByte) },
+	})
+}
+
+func (t *timings) set(at *time.Time) {
+	t.mu.Lock()
+	*at = time.Now()
+	t.mu.Unlock()
+}
+
+func (t *timings) since(d *time.Duration, start *time.Time) {
+	t.mu.Lock()
+	if !start.IsZero() {
+		*d = time.Since(*start)
+	}
+	t.mu.Unlock()
+}
+
+// fields returns the phases that happened in milliseconds.
+func (t *timings) fields() []LogField {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var fields []LogField
+	for _, p := range []struct {
+		key string
+		d   time.Duration
+	}{
+		{"dns_ms", t.dns},
+		{"connect_ms", t.connect},
+		{"tls_ms", t.tls},
+	} {
+		if p.d > 0 {
+			fields = append(fields, LogField{p.key, milliseconds(p.d)})
+		}
+	}
+	if !t.wroteRequest.IsZero() && !t.firstByte.IsZero() {
+		fields = append(fields, LogField{"server_ms", milliseconds(t.firstByte.Sub(t.wroteRequest))})
+	}
+	return fields
+}
+
+// String lists the phases that happened in parentheses, e.g. " (dns 2ms,
+// connect 10ms, tls 25ms, server 40ms)", or returns an empty string when
+// none was traced. Reused connections have no dns, connect or tls phase.
+func (t *timings) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var phases []string
+	for _, p := range []struct {
+		name string
+		d    time.Duration
+	}{
+		{"dns", t.dns},
+		{"connect", t.connect},
+		{"tls", t.tls},
+	} {
+		if p.d > 0 {
+			phases = append(phases, p.name+" "+p.d.String())
+		}
+	}
+	if !t.wroteRequest.IsZero() && !t.firstByte.IsZero() {
+		phases = append(phases, "server "+t.firstByte.Sub(t.wroteRequest).String())
+	}
+	if len(phases) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(phases, ", ") + ")"
+}
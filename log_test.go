@@ -0,0 +1,136 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithLogCategories(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"secret":"value"}}`)
+	}))
+	defer srv.Close()
+
+	var lines []string
+	client := NewClient(srv.URL,
+		WithLogger(func(s string) { lines = append(lines, s) }),
+		WithLogCategories(LogTiming|LogResponse),
+	)
+	is.NoErr(client.Run(context.Background(), NewRequest("query {}"), nil))
+
+	is.Equal(len(lines), 4)
+	is.True(strings.HasPrefix(lines[0], "<< response after "))
+	is.True(strings.Contains(lines[0], "connect "))
+	is.True(strings.Contains(lines[0], "server "))
+	is.Equal(lines[1], "<< 200 OK")
+	is.True(strings.HasPrefix(lines[2], "<< headers: "))
+	is.Equal(lines[3], "<< 27 bytes")
+}
+
+func TestWithoutLogger(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	traced := func(client *Client) bool {
+		var trace *httptrace.ClientTrace
+		client.httpClient = doFunc(func(r *http.Request) (*http.Response, error) {
+			trace = httptrace.ContextClientTrace(r.Context())
+			return http.DefaultClient.Do(r)
+		})
+		is.NoErr(client.Run(context.Background(), NewRequest("query {}"), nil))
+		return trace != nil
+	}
+	// Requests are only traced for a logger.
+	is.True(!traced(NewClient(srv.URL)))
+	is.True(traced(NewClient(srv.URL, WithLogger(func(string) {}))))
+}
+
+type doFunc func(*http.Request) (*http.Response, error)
+
+func (f doFunc) Do(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestLogRedactsHeaders(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("Authorization"), "Bearer token")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	var lines []string
+	client := NewClient(srv.URL,
+		WithLogger(func(s string) { lines = append(lines, s) }),
+		WithLogCategories(LogRequest|LogResponse),
+		WithHeader("Authorization", "Bearer token"),
+		WithRedactedHeaders("x-api-key"),
+	)
+	req := NewRequest("query {}")
+	req.Header("X-Api-Key", "key")
+	is.NoErr(client.Run(context.Background(), req, nil))
+
+	logged := strings.Join(lines, "\n")
+	is.True(!strings.Contains(logged, "Bearer token"))
+	is.True(!strings.Contains(logged, "secret"))
+	is.True(!strings.Contains(logged, "key]"))
+	is.True(strings.Contains(logged, "Authorization:[[REDACTED]]"))
+	is.True(strings.Contains(logged, "X-Api-Key:[[REDACTED]]"))
+	is.True(strings.Contains(logged, "Set-Cookie:[[REDACTED]]"))
+
+	lines = nil
+	client = NewClient(srv.URL,
+		WithLogger(func(s string) { lines = append(lines, s) }),
+		WithHeader("Authorization", "Bearer token"),
+		WithoutHeaderRedaction(),
+	)
+	is.NoErr(client.Run(context.Background(), NewRequest("query {}"), nil))
+	is.True(strings.Contains(strings.Join(lines, "\n"), "Bearer token"))
+}
+
+func TestLogFormatters(t *testing.T) {
+	is := is.New(t)
+
+	entry := LogEntry{
+		Category: LogResponse,
+		Message:  "<< 200 OK",
+		Fields:   []LogField{{"status", 200}, {"note", `say "hi"`}},
+	}
+	is.Equal(TextFormatter{}.Format(entry), "<< 200 OK")
+	is.Equal(JSONFormatter{}.Format(entry), `{"category":"response","msg":"<< 200 OK","status":200,"note":"say \"hi\""}`)
+	is.Equal(LogfmtFormatter{}.Format(entry), `category=response msg="<< 200 OK" status=200 note="say \"hi\""`)
+}
+
+func TestWithLogFormatter(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	var lines []string
+	client := NewClient(srv.URL,
+		WithLogger(func(s string) { lines = append(lines, s) }),
+		WithLogCategories(LogResponse),
+		WithLogFormatter(LogfmtFormatter{}),
+	)
+	is.NoErr(client.Run(context.Background(), NewRequest("query {}"), nil))
+	is.Equal(lines[0], `category=response msg="<< 200 OK" status=200`)
+	is.Equal(lines[2], `category=response msg="<< 11 bytes" bytes=11`)
+}
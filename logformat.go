@@ -0,0 +1,124 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type (
+	// LogEntry is a line of debug information. Message is the human
+	// readable line, Fields holds the same information for structured
+	// formats.
+	LogEntry struct {
+		Category LogCategory
+		Message  string
+		Fields   []LogField
+	}
+
+	// LogField is a named value of a LogEntry.
+	LogField struct {
+		Key   string
+		Value interface{}
+	}
+
+	// Formatter turns log entries into the lines passed to Client.Log.
+	Formatter interface {
+		Format(LogEntry) string
+	}
+
+	// TextFormatter writes the message of entries, the default.
+	TextFormatter struct{}
+
+	// JSONFormatter writes entries as JSON objects with the category,
+	// message and fields as keys.
+	JSONFormatter struct{}
+
+	// LogfmtFormatter writes entries as logfmt key=value pairs.
+	LogfmtFormatter struct{}
+)
+
+// WithLogFormatter sets how debug information is formatted, e.g.
+// JSONFormatter{} to feed log pipelines directly.
+func WithLogFormatter(f Formatter) ClientOption {
+	return func(client *Client) {
+		if f != nil {
+			client.logFormatter = f
+		}
+	}
+}
+
+// String names the category, e.g. "timing".
+func (c LogCategory) String() string {
+	var names []string
+	for _, n := range []struct {
+		category LogCategory
+		name     string
+	}{
+		{LogRequest, "request"},
+		{LogResponse, "response"},
+		{LogBody, "body"},
+		{LogTiming, "timing"},
+	} {
+		if c&n.category != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+func (TextFormatter) Format(e LogEntry) string {
+	return e.Message
+}
+
+func (JSONFormatter) Format(e LogEntry) string {
+	var buf bytes.Buffer
+	buf.WriteString(`{"category":`)
+	writeJSONValue(&buf, e.Category.String())
+	buf.WriteString(`,"msg":`)
+	writeJSONValue(&buf, e.Message)
+	for _, f := range e.Fields {
+		buf.WriteByte(',')
+		writeJSONValue(&buf, f.Key)
+		buf.WriteByte(':')
+		writeJSONValue(&buf, f.Value)
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+// writeJSONValue writes v without escaping HTML characters, which are
+// common in log messages such as "<< 200 OK".
+func writeJSONValue(buf *bytes.Buffer, v interface{}) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		b.Reset()
+		_ = enc.Encode(fmt.Sprint(v))
+	}
+	buf.Write(bytes.TrimSuffix(b.Bytes(), []byte("\n")))
+}
+
+func (LogfmtFormatter) Format(e LogEntry) string {
+	var b strings.Builder
+	b.WriteString("category=" + logfmtValue(e.Category.String()))
+	b.WriteString(" msg=" + logfmtValue(e.Message))
+	for _, f := range e.Fields {
+		b.WriteString(" " + f.Key + "=" + logfmtValue(fmt.Sprint(f.Value)))
+	}
+	return b.String()
+}
+
+// logfmtValue quotes s when it is empty or holds spaces, quotes, equal
+// signs or control characters.
+func logfmtValue(s string) string {
+	if s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '"' || r == '=' || r == '\\' || r == 0x7f
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
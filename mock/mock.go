@@ -0,0 +1,397 @@
+// Package mock generates fake responses for operations from a schema, so
+// tests can serve plausible data without hand-written JSON.
+//
+// Generation is deterministic for a given seed, operation and variables,
+// which makes the output suitable for snapshots:
+//
+//	cfg := mock.DefaultConfig()
+//	cfg.Schema = &schema
+//	client := gqlgentest.New(mock.Handler(cfg))
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/sumup/graphql/introspection"
+	"github.com/sumup/graphql/parser"
+)
+
+// ScalarFunc generates the value of a leaf field.
+type ScalarFunc func(r *rand.Rand) interface{}
+
+// Config configures the generation.
+type Config struct {
+	// Schema is used to resolve the type of every selected field and is
+	// required.
+	Schema *introspection.Schema
+
+	// Scalars overrides the values generated for leaf fields, keyed by
+	// "Type.field" or by the name of a scalar or enum type.
+	Scalars map[string]ScalarFunc
+
+	// ListSize is the number of items generated for lists.
+	ListSize int
+
+	// Seed seeds the random source of every generated response.
+	Seed int64
+}
+
+// DefaultConfig returns the configuration used when none is given: lists
+// hold two items and the seed is 1.
+func DefaultConfig() Config {
+	return Config{
+		ListSize: 2,
+		Seed:     1,
+	}
+}
+
+type generator struct {
+	cfg       Config
+	doc       *parser.Document
+	variables map[string]interface{}
+	rand      *rand.Rand
+}
+
+// Generate returns the data of a response to the operation named
+// operationName in document, or to its only operation when the name is
+// empty.
+func Generate(document, operationName string, variables map[string]interface{}, cfg Config) (map[string]interface{}, error) {
+	if cfg.Schema == nil {
+		return nil, errors.New("mock: a schema is required")
+	}
+	doc, err := parser.Parse(document)
+	if err != nil {
+		return nil, err
+	}
+	op, err := doc.Operation(operationName)
+	if err != nil {
+		return nil, err
+	}
+	root, err := rootType(cfg.Schema, op.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		cfg:       cfg,
+		doc:       doc,
+		variables: variables,
+		rand:      rand.New(rand.NewSource(cfg.Seed)),
+	}
+	return g.object(op.SelectionSet, root)
+}
+
+// Handler serves generated responses to the operations posted to it as
+// JSON.
+func Handler(cfg Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query         string                 `json:"query"`
+			OperationName string                 `json:"operationName"`
+			Variables     map[string]interface{} `json:"variables"`
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, map[string]interface{}{"errors": []interface{}{map[string]string{"message": err.Error()}}})
+			return
+		}
+		data, err := Generate(body.Query, body.OperationName, body.Variables, cfg)
+		if err != nil {
+			writeJSON(w, map[string]interface{}{"errors": []interface{}{map[string]string{"message": err.Error()}}})
+			return
+		}
+		writeJSON(w, map[string]interface{}{"data": data})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func rootType(s *introspection.Schema, opType string) (*introspection.Type, error) {
+	var name *introspection.TypeName
+	switch opType {
+	case parser.Query:
+		name = s.QueryType
+	case parser.Mutation:
+		name = s.MutationType
+	case parser.Subscription:
+		name = s.SubscriptionType
+	}
+	if name == nil {
+		return nil, errors.Errorf("mock: schema does not support %s operations", opType)
+	}
+	t := s.Type(name.Name)
+	if t == nil {
+		return nil, errors.Errorf("mock: schema has no type %q", name.Name)
+	}
+	return t, nil
+}
+
+// fieldGroup holds the fields selected under the same response key, whose
+// selections are merged.
+type fieldGroup struct {
+	key    string
+	fields []*parser.Field
+}
+
+func (g *generator) object(set parser.SelectionSet, t *introspection.Type) (map[string]interface{}, error) {
+	groups, err := g.collect(nil, set, t, make(map[string]bool))
+	if err != nil {
+		return nil, err
+	}
+	obj := make(map[string]interface{}, len(groups))
+	for _, group := range groups {
+		f := group.fields[0]
+		if f.Name == "__typename" {
+			obj[group.key] = t.Name
+			continue
+		}
+		def := fieldDef(t, f.Name)
+		if def == nil {
+			return nil, errors.Errorf("mock: type %q has no field %q", t.Name, f.Name)
+		}
+		var merged parser.SelectionSet
+		for _, f := range group.fields {
+			merged = append(merged, f.SelectionSet...)
+		}
+		v, err := g.value(def.Type, merged, t.Name+"."+f.Name, f.Name)
+		if err != nil {
+			return nil, err
+		}
+		obj[group.key] = v
+	}
+	return obj, nil
+}
+
+// collect groups the fields of set that apply to t by response key.
+func (g *generator) collect(groups []*fieldGroup, set parser.SelectionSet, t *introspection.Type, visited map[string]bool) ([]*fieldGroup, error) {
+	var err error
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *parser.Field:
+			if g.skipped(sel.Directives) {
+				continue
+			}
+			key := sel.Name
+			if sel.Alias != "" {
+				key = sel.Alias
+			}
+			groups = addField(groups, key, sel)
+		case *parser.InlineFragment:
+			if g.skipped(sel.Directives) || (sel.TypeCondition != "" && !g.applies(sel.TypeCondition, t)) {
+				continue
+			}
+			if groups, err = g.collect(groups, sel.SelectionSet, t, visited); err != nil {
+				return nil, err
+			}
+		case *parser.FragmentSpread:
+			if g.skipped(sel.Directives) || visited[sel.Name] {
+				continue
+			}
+			visited[sel.Name] = true
+			f := g.doc.Fragment(sel.Name)
+			if f == nil {
+				return nil, errors.Errorf("mock: unknown fragment %q", sel.Name)
+			}
+			if !g.applies(f.TypeCondition, t) {
+				continue
+			}
+			if groups, err = g.collect(groups, f.SelectionSet, t, visited); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return groups, nil
+}
+
+func addField(groups []*fieldGroup, key string, f *parser.Field) []*fieldGroup {
+	for _, group := range groups {
+		if group.key == key {
+			group.fields = append(group.fields, f)
+			return groups
+		}
+	}
+	return append(groups, &fieldGroup{key: key, fields: []*parser.Field{f}})
+}
+
+// applies reports whether a fragment on condition applies to the object
+// type t.
+func (g *generator) applies(condition string, t *introspection.Type) bool {
+	if condition == t.Name {
+		return true
+	}
+	for _, i := range t.Interfaces {
+		if i.Name == condition {
+			return true
+		}
+	}
+	if c := g.cfg.Schema.Type(condition); c != nil && c.Kind == introspection.KindUnion {
+		for _, p := range c.PossibleTypes {
+			if p.Name == t.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *generator) value(ref introspection.TypeRef, set parser.SelectionSet, path, field string) (interface{}, error) {
+	switch ref.Kind {
+	case introspection.KindNonNull:
+		if ref.OfType == nil {
+			break
+		}
+		return g.value(*ref.OfType, set, path, field)
+	case introspection.KindList:
+		if ref.OfType == nil {
+			break
+		}
+		list := make([]interface{}, g.cfg.ListSize)
+		for i := range list {
+			v, err := g.value(*ref.OfType, set, path, field)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return list, nil
+	}
+
+	t := g.cfg.Schema.Type(ref.Name)
+	if t == nil {
+		return nil, errors.Errorf("mock: schema has no type %q", ref.Name)
+	}
+	switch t.Kind {
+	case introspection.KindObject:
+		return g.object(set, t)
+	case introspection.KindInterface, introspection.KindUnion:
+		if len(t.PossibleTypes) == 0 {
+			return nil, errors.Errorf("mock: type %q has no possible types", t.Name)
+		}
+		name := t.PossibleTypes[g.rand.Intn(len(t.PossibleTypes))].Name
+		concrete := g.cfg.Schema.Type(name)
+		if concrete == nil {
+			return nil, errors.Errorf("mock: schema has no type %q", name)
+		}
+		return g.object(set, concrete)
+	}
+
+	if fn, ok := g.cfg.Scalars[path]; ok {
+		return fn(g.rand), nil
+	}
+	if fn, ok := g.cfg.Scalars[t.Name]; ok {
+		return fn(g.rand), nil
+	}
+	if t.Kind == introspection.KindEnum {
+		if len(t.EnumValues) == 0 {
+			return nil, errors.Errorf("mock: enum %q has no values", t.Name)
+		}
+		return t.EnumValues[g.rand.Intn(len(t.EnumValues))].Name, nil
+	}
+	return g.scalar(t.Name, field), nil
+}
+
+// scalar generates a value for the built-in scalars, guessing a plausible
+// string from the field name.
+func (g *generator) scalar(typ, field string) interface{} {
+	r := g.rand
+	switch typ {
+	case "Int":
+		return r.Intn(1000)
+	case "Float":
+		return float64(r.Intn(100000)) / 100
+	case "Boolean":
+		return r.Intn(2) == 1
+	case "ID":
+		return fmt.Sprintf("%016x", r.Uint64())
+	}
+
+	name := strings.ToLower(field)
+	lowerType := strings.ToLower(typ)
+	switch {
+	case strings.Contains(lowerType, "date") || strings.Contains(lowerType, "time") ||
+		strings.HasSuffix(field, "At") || strings.Contains(name, "date"):
+		t := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(r.Intn(3*365*24*60)) * time.Minute)
+		if lowerType == "date" {
+			return t.Format("2006-01-02")
+		}
+		return t.Format(time.RFC3339)
+	case strings.Contains(name, "email"):
+		return pick(r, firstNames) + "." + pick(r, lastNames) + "@example.com"
+	case strings.Contains(name, "url") || strings.Contains(lowerType, "url"):
+		return "https://example.com/" + pick(r, words)
+	case strings.Contains(name, "phone"):
+		return fmt.Sprintf("+49 30 %07d", r.Intn(10000000))
+	case strings.Contains(name, "currency"):
+		return pick(r, currencies)
+	case strings.Contains(name, "country"):
+		return pick(r, countries)
+	case strings.Contains(name, "city"):
+		return pick(r, cities)
+	case name == "firstname":
+		return pick(r, firstNames)
+	case name == "lastname" || name == "surname":
+		return pick(r, lastNames)
+	case strings.Contains(name, "name"):
+		return pick(r, firstNames) + " " + pick(r, lastNames)
+	}
+	n := 2 + r.Intn(3)
+	text := make([]string, n)
+	for i := range text {
+		text[i] = pick(r, words)
+	}
+	return strings.Join(text, " ")
+}
+
+// skipped evaluates @skip and @include.
+func (g *generator) skipped(directives []*parser.Directive) bool {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			continue
+		}
+		for _, arg := range d.Arguments {
+			if arg.Name != "if" {
+				continue
+			}
+			cond, ok := arg.Value.Resolve(g.variables).(bool)
+			if !ok {
+				continue
+			}
+			if (d.Name == "skip" && cond) || (d.Name == "include" && !cond) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func fieldDef(t *introspection.Type, name string) *introspection.Field {
+	for i := range t.Fields {
+		if t.Fields[i].Name == name {
+			return &t.Fields[i]
+		}
+	}
+	return nil
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.Intn(len(values))]
+}
+
+var (
+	firstNames = []string{"Anna", "Ben", "Clara", "David", "Elena", "Felix", "Greta", "Hugo", "Ines", "Jonas"}
+	lastNames  = []string{"Fischer", "Garcia", "Jensen", "Martin", "Novak", "Rossi", "Schmidt", "Silva", "Smith", "Weber"}
+	cities     = []string{"Amsterdam", "Berlin", "Dublin", "Lisbon", "London", "Madrid", "Paris", "Sofia", "Vienna", "Warsaw"}
+	countries  = []string{"AT", "BG", "DE", "ES", "FR", "GB", "IE", "NL", "PL", "PT"}
+	currencies = []string{"BGN", "CHF", "EUR", "GBP", "PLN", "USD"}
+	words      = []string{"amber", "bright", "coffee", "delta", "ember", "forest", "garden", "harbor", "island", "juniper", "kettle", "lemon", "meadow", "north", "orchard", "pepper"}
+)
@@ -0,0 +1,111 @@
+package mock
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/sumup/graphql"
+	"github.com/sumup/graphql/gqlgentest"
+	"github.com/sumup/graphql/introspection"
+)
+
+const testSchema = `{
+	"queryType": {"name": "Query"},
+	"types": [
+		{"kind": "OBJECT", "name": "Query", "fields": [
+			{"name": "merchants", "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "Merchant"}}}},
+			{"name": "node", "type": {"kind": "INTERFACE", "name": "Node"}}
+		]},
+		{"kind": "INTERFACE", "name": "Node", "fields": [
+			{"name": "id", "type": {"kind": "SCALAR", "name": "ID"}}
+		], "possibleTypes": [{"kind": "OBJECT", "name": "Merchant"}]},
+		{"kind": "OBJECT", "name": "Merchant", "interfaces": [{"kind": "INTERFACE", "name": "Node"}], "fields": [
+			{"name": "id", "type": {"kind": "SCALAR", "name": "ID"}},
+			{"name": "name", "type": {"kind": "SCALAR", "name": "String"}},
+			{"name": "email", "type": {"kind": "SCALAR", "name": "String"}},
+			{"name": "balance", "type": {"kind": "SCALAR", "name": "Money"}},
+			{"name": "status", "type": {"kind": "ENUM", "name": "Status"}},
+			{"name": "tags", "type": {"kind": "LIST", "ofType": {"kind": "SCALAR", "name": "String"}}}
+		]},
+		{"kind": "ENUM", "name": "Status", "enumValues": [{"name": "ACTIVE"}, {"name": "CLOSED"}]},
+		{"kind": "SCALAR", "name": "Money"},
+		{"kind": "SCALAR", "name": "ID"},
+		{"kind": "SCALAR", "name": "String"}
+	]
+}`
+
+func testConfig(t *testing.T) Config {
+	var schema introspection.Schema
+	if err := json.Unmarshal([]byte(testSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.Schema = &schema
+	cfg.Scalars = map[string]ScalarFunc{
+		"Money": func(r *rand.Rand) interface{} { return r.Intn(100) * 100 },
+	}
+	return cfg
+}
+
+func TestGenerate(t *testing.T) {
+	is := is.New(t)
+	cfg := testConfig(t)
+
+	query := `query ($withTags: Boolean!) {
+		merchants {
+			id
+			name
+			...Details
+			... on Merchant { status }
+			tags @include(if: $withTags)
+		}
+		node { __typename id }
+	}
+	fragment Details on Merchant { email balance }`
+	data, err := Generate(query, "", map[string]interface{}{"withTags": false}, cfg)
+	is.NoErr(err)
+
+	merchants := data["merchants"].([]interface{})
+	is.Equal(len(merchants), 2)
+	m := merchants[0].(map[string]interface{})
+	is.Equal(len(m), 5)
+	is.True(m["status"] == "ACTIVE" || m["status"] == "CLOSED")
+	is.Equal(m["balance"].(int)%100, 0)
+	_, ok := m["tags"]
+	is.True(!ok)
+	is.Equal(data["node"].(map[string]interface{})["__typename"], "Merchant")
+
+	again, err := Generate(query, "", map[string]interface{}{"withTags": false}, cfg)
+	is.NoErr(err)
+	is.Equal(again, data)
+}
+
+func TestGenerateUnknownField(t *testing.T) {
+	is := is.New(t)
+
+	_, err := Generate(`{ merchants { iban } }`, "", nil, testConfig(t))
+	is.Equal(err.Error(), `mock: type "Merchant" has no field "iban"`)
+}
+
+func TestHandler(t *testing.T) {
+	is := is.New(t)
+	c := gqlgentest.New(Handler(testConfig(t)))
+
+	var resp struct {
+		Merchants []struct {
+			ID    string
+			Email string
+		}
+	}
+	is.NoErr(c.Post(`{ merchants { id email } }`, &resp))
+	is.Equal(len(resp.Merchants), 2)
+	is.True(resp.Merchants[0].ID != "")
+	is.True(resp.Merchants[0].Email != "")
+
+	err := c.Post(`{ merchants { iban } }`, &resp)
+	_, ok := err.(*graphql.GraphQLError)
+	is.True(ok)
+}
@@ -0,0 +1,167 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Money is an amount in the minor unit of its currency, e.g. cents, so
+// no precision is lost.
+type Money struct {
+	// Amount is in minor units, e.g. 1050 for 10.50 EUR.
+	Amount int64
+	// Currency is the ISO 4217 code, e.g. "EUR".
+	Currency string
+}
+
+// MoneyFormat selects how Money variables are sent, since payment
+// schemas differ.
+type MoneyFormat int
+
+const (
+	// MoneyObject sends {"amount": 10.50, "currency": "EUR"}, the amount
+	// in major units. It is the default.
+	MoneyObject MoneyFormat = iota + 1
+	// MoneyDecimalString sends the amount in major units as a string,
+	// e.g. "10.50", without the currency.
+	MoneyDecimalString
+	// MoneyMinorUnits sends the amount in minor units as an integer,
+	// e.g. 1050, without the currency.
+	MoneyMinorUnits
+)
+
+// WithMoneyFormat sets how Money variables are sent. Money decodes from
+// any of the formats.
+func WithMoneyFormat(format MoneyFormat) ClientOption {
+	return func(client *Client) {
+		client.moneyFormat = format
+	}
+}
+
+// currencyExponents lists the currencies whose minor unit is not a
+// hundredth of the major unit.
+var currencyExponents = map[string]int{
+	"BHD": 3, "BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "IQD": 3, "ISK": 0,
+	"JOD": 3, "JPY": 0, "KMF": 0, "KRW": 0, "KWD": 3, "LYD": 3, "OMR": 3,
+	"PYG": 0, "RWF": 0, "TND": 3, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+// exponent gets the number of decimals of the currency, 2 by default.
+func (m Money) exponent() int {
+	if e, ok := currencyExponents[strings.ToUpper(m.Currency)]; ok {
+		return e
+	}
+	return 2
+}
+
+// String formats the money, e.g. "10.50 EUR".
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Decimal()
+	}
+	return m.Decimal() + " " + m.Currency
+}
+
+// Decimal formats the amount in major units, e.g. "10.50".
+func (m Money) Decimal() string {
+	return big.NewRat(m.Amount, pow10(m.exponent())).FloatString(m.exponent())
+}
+
+// MarshalJSON encodes the money as a MoneyObject.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.wire(MoneyObject))
+}
+
+// wire gets the value sent for the money in format.
+func (m Money) wire(format MoneyFormat) interface{} {
+	switch format {
+	case MoneyDecimalString:
+		return m.Decimal()
+	case MoneyMinorUnits:
+		return m.Amount
+	}
+	return struct {
+		Amount   json.Number `json:"amount"`
+		Currency string      `json:"currency"`
+	}{json.Number(m.Decimal()), m.Currency}
+}
+
+// UnmarshalJSON decodes any of the formats: an object with an amount in
+// major units and a currency, a decimal string in major units, or an
+// integer in minor units. The last two leave the currency empty.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	switch {
+	case bytes.Equal(b, []byte("null")):
+		return nil
+	case len(b) > 0 && b[0] == '{':
+		var obj struct {
+			Amount   json.RawMessage `json:"amount"`
+			Currency string          `json:"currency"`
+		}
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return err
+		}
+		m.Currency = obj.Currency
+		var amount string
+		if err := json.Unmarshal(obj.Amount, &amount); err != nil {
+			amount = string(obj.Amount)
+		}
+		return m.parseDecimal(amount)
+	case len(b) > 0 && b[0] == '"':
+		var amount string
+		if err := json.Unmarshal(b, &amount); err != nil {
+			return err
+		}
+		m.Currency = ""
+		return m.parseDecimal(amount)
+	}
+	amount, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return errors.Errorf("money must be an object, a decimal string or an integer, got %s", b)
+	}
+	*m = Money{Amount: amount}
+	return nil
+}
+
+// parseDecimal sets the amount from major units, failing when it has
+// more decimals than the currency.
+func (m *Money) parseDecimal(s string) error {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || strings.ContainsAny(s, "/xXbBoO_") {
+		return errors.Errorf("invalid money amount %q", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(pow10(m.exponent())))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return errors.Errorf("money amount %q does not fit in minor units of %q", s, m.Currency)
+	}
+	m.Amount = r.Num().Int64()
+	return nil
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+var moneyType = reflect.TypeOf(Money{})
+
+// moneyConverter sends Money values in format.
+func moneyConverter(format MoneyFormat) varConverter {
+	return func(v reflect.Value) (interface{}, bool) {
+		if v.Type() != moneyType {
+			return nil, false
+		}
+		return v.Interface().(Money).wire(format), true
+	}
+}
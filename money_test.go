@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	is := is.New(t)
+
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{`{"amount": 10.5, "currency": "EUR"}`, Money{1050, "EUR"}},
+		{`{"amount": "1000", "currency": "JPY"}`, Money{1000, "JPY"}},
+		{`{"amount": 1.234, "currency": "KWD"}`, Money{1234, "KWD"}},
+		{`"10.50"`, Money{Amount: 1050}},
+		{`1050`, Money{Amount: 1050}},
+	}
+	for _, test := range tests {
+		var m Money
+		is.NoErr(json.Unmarshal([]byte(test.in), &m))
+		is.Equal(m, test.want)
+	}
+
+	var m Money
+	err := json.Unmarshal([]byte(`{"amount": 10.555, "currency": "EUR"}`), &m)
+	is.Equal(err.Error(), `money amount "10.555" does not fit in minor units of "EUR"`)
+	is.True(json.Unmarshal([]byte(`true`), &m) != nil)
+
+	is.Equal(Money{1050, "EUR"}.String(), "10.50 EUR")
+	is.Equal(Money{-5, "JPY"}.Decimal(), "-5")
+}
+
+func TestWithMoneyFormat(t *testing.T) {
+	is := is.New(t)
+
+	var vars map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct{ Variables map[string]json.RawMessage }
+		is.NoErr(json.NewDecoder(r.Body).Decode(&payload))
+		vars = payload.Variables
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	req := NewMutation("mutation ($total: Money!) { a }")
+	req.Var("total", Money{1050, "EUR"})
+
+	for format, want := range map[MoneyFormat]string{
+		0:                  `{"amount":10.50,"currency":"EUR"}`,
+		MoneyDecimalString: `"10.50"`,
+		MoneyMinorUnits:    `1050`,
+	} {
+		is.NoErr(NewClient(srv.URL, WithMoneyFormat(format)).Run(context.Background(), req, nil))
+		is.Equal(string(vars["total"]), want)
+	}
+}
@@ -0,0 +1,130 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Decimal is an arbitrary-precision number kept as its decimal text, so
+// amounts of money and big integers decode losslessly whether the server
+// sends them as JSON numbers or strings. It is sent as a string, the
+// usual representation of Decimal and BigInt scalars.
+type Decimal string
+
+// Numeric is implemented by arbitrary-precision number types to be sent
+// as strings by WithNumericStrings. Types of other packages are adapted
+// with a wrapper, e.g. for shopspring/decimal:
+//
+//	type Amount struct{ decimal.Decimal }
+//
+//	func (a Amount) NumericString() string { return a.Decimal.String() }
+type Numeric interface {
+	NumericString() string
+}
+
+// WithNumericStrings sends big.Int, big.Float, Decimal and Numeric
+// variables as JSON strings instead of numbers, as schemas with Decimal
+// or BigInt scalars expect, so no precision is lost in transit.
+func WithNumericStrings() ClientOption {
+	return func(client *Client) {
+		client.numericStrings = true
+	}
+}
+
+// ParseDecimal checks that s is a decimal number, e.g. "-12.50" or
+// "1e-3".
+func ParseDecimal(s string) (Decimal, error) {
+	// The grammar of JSON numbers, unlike big.Rat, excludes fractions
+	// and base prefixes.
+	if s == "" || (s[0] != '-' && (s[0] < '0' || s[0] > '9')) || !json.Valid([]byte(s)) {
+		return "", errors.Errorf("%q is not a decimal number", s)
+	}
+	return Decimal(s), nil
+}
+
+// String gets the decimal text.
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// Rat converts the number exactly.
+func (d Decimal) Rat() (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(string(d))
+	if !ok {
+		return nil, errors.Errorf("%q is not a decimal number", string(d))
+	}
+	return r, nil
+}
+
+// Int converts the number, failing when it is not an integer.
+func (d Decimal) Int() (*big.Int, error) {
+	r, err := d.Rat()
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInt() {
+		return nil, errors.Errorf("%q is not an integer", string(d))
+	}
+	return r.Num(), nil
+}
+
+// MarshalJSON encodes the number as a string.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(d))
+}
+
+// UnmarshalJSON accepts a number or a string holding one.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	numericType  = reflect.TypeOf((*Numeric)(nil)).Elem()
+)
+
+// numericConverter sends arbitrary-precision numbers as strings.
+func numericConverter(v reflect.Value) (interface{}, bool) {
+	if v.Type().Implements(numericType) {
+		return v.Interface().(Numeric).NumericString(), true
+	}
+	if v.CanAddr() {
+		if n, ok := v.Addr().Interface().(Numeric); ok {
+			return n.NumericString(), true
+		}
+	}
+	if v.Type() != bigIntType && v.Type() != bigFloatType {
+		return nil, false
+	}
+	if !v.CanAddr() {
+		copied := reflect.New(v.Type())
+		copied.Elem().Set(v)
+		v = copied.Elem()
+	}
+	switch n := v.Addr().Interface().(type) {
+	case *big.Int:
+		return n.String(), true
+	case *big.Float:
+		return n.Text('g', -1), true
+	}
+	return nil, false
+}
@@ -0,0 +1,76 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type amount struct{ cents int64 }
+
+func (a amount) NumericString() string {
+	return big.NewRat(a.cents, 100).FloatString(2)
+}
+
+func TestDecimal(t *testing.T) {
+	is := is.New(t)
+
+	var resp struct {
+		Total   Decimal
+		Balance Decimal
+		Missing Decimal
+	}
+	is.NoErr(json.Unmarshal([]byte(`{"total": 12345678901234567890.12, "balance": "-0.10", "missing": null}`), &resp))
+	is.Equal(resp.Total, Decimal("12345678901234567890.12"))
+	is.Equal(resp.Balance, Decimal("-0.10"))
+	is.Equal(resp.Missing, Decimal(""))
+	is.True(json.Unmarshal([]byte(`{"total": "1/3"}`), &resp) != nil)
+
+	r, err := resp.Balance.Rat()
+	is.NoErr(err)
+	is.Equal(r.Cmp(big.NewRat(-1, 10)), 0)
+	i, err := Decimal("1e20").Int()
+	is.NoErr(err)
+	is.Equal(i.String(), "100000000000000000000")
+	_, err = resp.Balance.Int()
+	is.Equal(err.Error(), `"-0.10" is not an integer`)
+
+	b, err := json.Marshal(resp.Total)
+	is.NoErr(err)
+	is.Equal(string(b), `"12345678901234567890.12"`)
+
+	_, err = ParseDecimal("0x10")
+	is.Equal(err.Error(), `"0x10" is not a decimal number`)
+}
+
+func TestWithNumericStrings(t *testing.T) {
+	is := is.New(t)
+
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = strings.TrimSpace(string(b))
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	req := NewRequest("query ($n: BigInt, $f: Decimal, $a: Decimal, $list: [BigInt]) { a }")
+	req.Var("n", n)
+	req.Var("f", big.NewFloat(0.5))
+	req.Var("a", amount{cents: 1999})
+	req.Var("list", []big.Int{*big.NewInt(1)})
+
+	is.NoErr(NewClient(srv.URL).Run(context.Background(), req, nil))
+	is.Equal(body, `{"query":"query ($n: BigInt, $f: Decimal, $a: Decimal, $list: [BigInt]) { a }","variables":{"a":{},"f":"0.5","list":[1],"n":123456789012345678901234567890}}`)
+
+	is.NoErr(NewClient(srv.URL, WithNumericStrings()).Run(context.Background(), req, nil))
+	is.Equal(body, `{"query":"query ($n: BigInt, $f: Decimal, $a: Decimal, $list: [BigInt]) { a }","variables":{"a":"19.99","f":"0.5","list":["1"],"n":"123456789012345678901234567890"}}`)
+}
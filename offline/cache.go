@@ -0,0 +1,64 @@
+package offline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FileCache keeps each query result in a file of a directory, so results
+// survive restarts: a CLI or a batch job started while the server cannot
+// be reached still gets the results of its previous runs.
+type FileCache struct {
+	dir string
+}
+
+var _ Cache = (*FileCache)(nil)
+
+// NewFileCache opens the cache kept in dir, creating the directory when
+// it does not exist.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+// Get gets the result stored for key. A file that cannot be read is a
+// miss.
+func (c *FileCache) Get(key string) ([]byte, bool) {
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Set stores the result for key, replacing the file atomically so
+// concurrent readers and a crash never see a partially written result.
+// The cache is best effort: a result that cannot be written is not
+// cached.
+func (c *FileCache) Set(key string, data []byte) {
+	tmp, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), c.path(key))
+}
+
+// path gets the file of key, named by its hash since keys may hold any
+// character.
+func (c *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
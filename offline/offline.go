@@ -0,0 +1,222 @@
+// Package offline keeps a client usable on a flaky network, as point of
+// sale devices have. Query results are cached and served when the server
+// cannot be reached, and mutations that cannot be sent are queued in a
+// queue.Store and replayed in order once the network is back.
+//
+//	store, err := queue.NewFileStore("/var/lib/app/outbox")
+//	client := offline.New(graphql.NewClient(endpoint), store, offline.Config{
+//		Queue: queue.DefaultConfig(),
+//		OnConflict: func(item queue.Item, err graphql.Error) {
+//			log.Printf("mutation %d rejected: %v", item.ID, err)
+//		},
+//	})
+//	go client.Replay(ctx)
+//
+//	err := client.Run(ctx, mutation, &resp)
+//	if errors.Is(err, offline.ErrQueued) {
+//		// the mutation will be sent later, resp is empty
+//	}
+package offline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/sumup/graphql"
+	"github.com/sumup/graphql/parser"
+	"github.com/sumup/graphql/queue"
+)
+
+// ErrQueued is the cause of the *graphql.ExecutionError returned for
+// mutations queued rather than sent. Their response is not decoded.
+var ErrQueued = errors.New("offline: mutation queued")
+
+type (
+	// Config configures the offline behaviour.
+	Config struct {
+		// Queue configures the replay of queued mutations. Its Retryable
+		// also decides which errors mean the server cannot be reached,
+		// queue.Retryable by default.
+		Queue queue.Config
+
+		// OnConflict is called with the queued mutations the server
+		// rejected on replay, such as those conflicting with changes
+		// made in the meantime. It replaces Queue.OnFailure when set.
+		OnConflict func(queue.Item, graphql.Error)
+
+		// Cache keeps the last result of queries, a MemoryCache by
+		// default. A FileCache keeps them across restarts.
+		Cache Cache
+	}
+
+	// Cache keeps encoded query results by key. Implementations must be
+	// safe for concurrent use.
+	Cache interface {
+		Get(key string) ([]byte, bool)
+		Set(key string, data []byte)
+	}
+
+	// MemoryCache keeps query results in memory.
+	MemoryCache struct {
+		mu      sync.Mutex
+		results map[string][]byte
+	}
+
+	// Client runs operations with a graphql.GraphClient, falling back to
+	// the cache for queries and to the queue for mutations when the
+	// server cannot be reached.
+	Client struct {
+		client    graphql.GraphClient
+		store     queue.Store
+		queue     *queue.Queue
+		cache     Cache
+		retryable func(graphql.Error) bool
+	}
+)
+
+var _ graphql.GraphClient = (*Client)(nil)
+
+// NewMemoryCache creates an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{results: make(map[string][]byte)}
+}
+
+// Get gets the result stored for key.
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.results[key]
+	return data, ok
+}
+
+// Set stores the result for key.
+func (c *MemoryCache) Set(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[key] = data
+}
+
+// New creates a Client running operations with client and queueing
+// mutations in store. Mutations left in store by a previous process are
+// replayed too.
+func New(client graphql.GraphClient, store queue.Store, cfg Config) *Client {
+	if cfg.Queue.Retryable == nil {
+		cfg.Queue.Retryable = queue.Retryable
+	}
+	if cfg.OnConflict != nil {
+		cfg.Queue.OnFailure = cfg.OnConflict
+	}
+	if cfg.Cache == nil {
+		cfg.Cache = NewMemoryCache()
+	}
+	return &Client{
+		client:    client,
+		store:     store,
+		queue:     queue.New(client, store, cfg.Queue),
+		cache:     cfg.Cache,
+		retryable: cfg.Queue.Retryable,
+	}
+}
+
+// Replay sends the queued mutations in order, waiting for the server to
+// be reachable again, until ctx is done.
+func (c *Client) Replay(ctx context.Context) error {
+	return c.queue.Run(ctx)
+}
+
+// Run runs op. Queries answered by the server are cached and the cached
+// result is decoded into resp when the server cannot be reached.
+// Mutations, either a graphql.Mutation or a document with a mutation
+// operation, are queued when the server cannot be reached, or behind
+// mutations already queued so they keep their order, and ErrQueued is
+// returned. Other operations are only sent.
+func (c *Client) Run(ctx context.Context, op graphql.Operation, resp interface{}) graphql.Error {
+	kind := operationType(op)
+	if kind == parser.Mutation {
+		return c.mutate(ctx, op, resp)
+	}
+	if kind != parser.Query {
+		return c.client.Run(ctx, op, resp)
+	}
+	err := c.client.Run(ctx, op, resp)
+	key := cacheKey(op)
+	if err == nil {
+		if resp != nil {
+			if data, merr := json.Marshal(resp); merr == nil {
+				c.cache.Set(key, data)
+			}
+		}
+		return nil
+	}
+	if !c.retryable(err) || ctx.Err() != nil {
+		return err
+	}
+	data, ok := c.cache.Get(key)
+	if !ok {
+		return err
+	}
+	if resp != nil {
+		if uerr := json.Unmarshal(data, resp); uerr != nil {
+			return graphql.NewExecutionError(errors.Wrap(uerr, "offline: decoding cached result"))
+		}
+	}
+	return nil
+}
+
+func (c *Client) mutate(ctx context.Context, op graphql.Operation, resp interface{}) graphql.Error {
+	pending, err := c.store.Peek()
+	if err != nil {
+		return graphql.NewExecutionError(errors.Wrap(err, "offline: reading queue"))
+	}
+	if pending == nil {
+		gerr := c.client.Run(ctx, op, resp)
+		if gerr == nil || !c.retryable(gerr) || ctx.Err() != nil {
+			return gerr
+		}
+	}
+	if err := c.queue.Enqueue(op); err != nil {
+		return graphql.NewExecutionError(err)
+	}
+	return graphql.NewExecutionError(ErrQueued)
+}
+
+// operationType gets the type of the operation op runs: parser.Mutation
+// when it is a graphql.Mutation or its document has a mutation, the type
+// of its single operation otherwise, or "" when the document cannot be
+// parsed or has several operations.
+func operationType(op graphql.Operation) string {
+	if _, mutation := op.(*graphql.Mutation); mutation {
+		return parser.Mutation
+	}
+	doc, err := parser.Parse(op.Request().Query())
+	if err != nil {
+		return ""
+	}
+	for _, def := range doc.Operations() {
+		if def.Type == parser.Mutation {
+			return parser.Mutation
+		}
+	}
+	def, err := doc.Operation("")
+	if err != nil {
+		return ""
+	}
+	return def.Type
+}
+
+// cacheKey identifies the result of op by its document and variables.
+func cacheKey(op graphql.Operation) string {
+	h := sha256.New()
+	h.Write([]byte(op.Request().Query()))
+	h.Write([]byte{0})
+	// Maps are encoded with sorted keys, so equal variables give equal
+	// keys.
+	vars, _ := json.Marshal(op.Vars())
+	h.Write(vars)
+	return hex.EncodeToString(h.Sum(nil))
+}
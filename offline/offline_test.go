@@ -0,0 +1,189 @@
+package offline
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/sumup/graphql"
+	"github.com/sumup/graphql/queue"
+)
+
+func TestClient(t *testing.T) {
+	is := is.New(t)
+
+	var (
+		mu       sync.Mutex
+		online   = true
+		received []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !online {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var body struct {
+			Variables struct{ N string }
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		if body.Variables.N == "" {
+			_, _ = io.WriteString(w, `{"data":{"balance":10}}`)
+			return
+		}
+		received = append(received, body.Variables.N)
+		if body.Variables.N == "conflict" {
+			_, _ = io.WriteString(w, `{"data":{"refund":{"successful":false,"messages":[{"message":"already refunded"}]}}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"data":{"refund":{"successful":true}}}`)
+	}))
+	defer srv.Close()
+	setOnline := func(b bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		online = b
+	}
+
+	var conflicts []queue.Item
+	cfg := Config{
+		Queue: queue.DefaultConfig(),
+		OnConflict: func(item queue.Item, err graphql.Error) {
+			conflicts = append(conflicts, item)
+		},
+	}
+	cfg.Queue.MinBackoff = time.Millisecond
+	client := New(graphql.NewClient(srv.URL), queue.NewMemoryStore(), cfg)
+	ctx := context.Background()
+
+	var resp struct{ Balance int }
+	is.NoErr(client.Run(ctx, graphql.NewRequest("query { balance }"), &resp))
+	is.Equal(resp.Balance, 10)
+
+	setOnline(false)
+	resp.Balance = 0
+	is.NoErr(client.Run(ctx, graphql.NewRequest("query { balance }"), &resp))
+	is.Equal(resp.Balance, 10) // from the cache
+	err := client.Run(ctx, graphql.NewRequest("query { other }"), nil)
+	_, isRequestErr := err.(*graphql.RequestError)
+	is.True(isRequestErr) // nothing cached
+
+	for _, n := range []string{"1", "conflict", "2"} {
+		m := graphql.NewMutation(`mutation ($n: ID!) { refund(id: $n) { successful } }`)
+		m.Var("n", n)
+		if n == "2" {
+			setOnline(true) // queued behind the others anyway
+		}
+		is.True(errors.Is(client.Run(ctx, m, nil), ErrQueued))
+	}
+
+	replayCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() { done <- client.Replay(replayCtx) }()
+	for {
+		if item, err := client.store.Peek(); err == nil && item == nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	is.Equal(<-done, context.Canceled)
+
+	is.Equal(received, []string{"1", "conflict", "2"})
+	is.Equal(len(conflicts), 1)
+	is.Equal(conflicts[0].Variables["n"], "conflict")
+
+	// With nothing queued, mutations are sent at once.
+	m := graphql.NewMutation(`mutation ($n: ID!) { refund(id: $n) { successful } }`)
+	m.Var("n", "3")
+	is.NoErr(client.Run(ctx, m, nil))
+	is.Equal(received[len(received)-1], "3")
+}
+
+func TestClientRequestMutation(t *testing.T) {
+	is := is.New(t)
+
+	var (
+		mu     sync.Mutex
+		online = true
+		sent   int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !online {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		sent++
+		_, _ = io.WriteString(w, `{"data":{"pay":{"id":"p1"}}}`)
+	}))
+	defer srv.Close()
+
+	store := queue.NewMemoryStore()
+	client := New(graphql.NewClient(srv.URL), store, Config{Queue: queue.DefaultConfig()})
+	ctx := context.Background()
+	newPay := func() *graphql.Request {
+		req := graphql.NewRequest(`mutation Pay($amount: Int!) { pay(amount: $amount) { id } }`)
+		req.Var("amount", 5)
+		return req
+	}
+
+	var resp struct{ Pay struct{ ID string } }
+	is.NoErr(client.Run(ctx, newPay(), &resp))
+	is.Equal(resp.Pay.ID, "p1")
+
+	mu.Lock()
+	online = false
+	mu.Unlock()
+	// The result of the first payment is not served from the cache: the
+	// mutation is queued.
+	resp.Pay.ID = ""
+	is.True(errors.Is(client.Run(ctx, newPay(), &resp), ErrQueued))
+	is.Equal(resp.Pay.ID, "")
+	item, err := store.Peek()
+	is.NoErr(err)
+	is.True(item != nil)
+	is.Equal(sent, 1)
+}
+
+func TestFileCache(t *testing.T) {
+	is := is.New(t)
+
+	var online int32 = 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&online) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, `{"data":{"currencies":["EUR","GBP"]}}`)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	open := func() *Client {
+		cache, err := NewFileCache(dir)
+		is.NoErr(err)
+		return New(graphql.NewClient(srv.URL), queue.NewMemoryStore(), Config{Cache: cache})
+	}
+	ctx := context.Background()
+
+	var resp struct{ Currencies []string }
+	is.NoErr(open().Run(ctx, graphql.NewRequest("query { currencies }"), &resp))
+
+	// A client started later gets the result cached by the first one.
+	atomic.StoreInt32(&online, 0)
+	resp.Currencies = nil
+	is.NoErr(open().Run(ctx, graphql.NewRequest("query { currencies }"), &resp))
+	is.Equal(resp.Currencies, []string{"EUR", "GBP"})
+}
@@ -0,0 +1,245 @@
+package parser
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Operation types.
+const (
+	Query        = "query"
+	Mutation     = "mutation"
+	Subscription = "subscription"
+)
+
+type (
+	// Document is a parsed executable GraphQL document.
+	Document struct {
+		// Definitions holds the operations and fragments in the order they
+		// appear in the document.
+		Definitions []Definition
+	}
+
+	// Definition is an *OperationDefinition or a *FragmentDefinition.
+	Definition interface {
+		definition()
+	}
+
+	// OperationDefinition is a query, mutation or subscription. Name is
+	// empty for anonymous operations.
+	OperationDefinition struct {
+		Type         string
+		Name         string
+		Variables    []*VariableDefinition
+		Directives   []*Directive
+		SelectionSet SelectionSet
+		Position     Position
+	}
+
+	// FragmentDefinition is a named fragment.
+	FragmentDefinition struct {
+		Name          string
+		TypeCondition string
+		Directives    []*Directive
+		SelectionSet  SelectionSet
+		Position      Position
+	}
+
+	// VariableDefinition declares a variable of an operation.
+	VariableDefinition struct {
+		Name         string
+		Type         *Type
+		DefaultValue *Value
+		Directives   []*Directive
+		Position     Position
+	}
+
+	// Type is a variable type. Elem is set for list types.
+	Type struct {
+		Name    string
+		Elem    *Type
+		NonNull bool
+	}
+
+	// SelectionSet is the list of selections between braces.
+	SelectionSet []Selection
+
+	// Selection is a *Field, *FragmentSpread or *InlineFragment.
+	Selection interface {
+		selection()
+	}
+
+	// Field selects a field, Alias is empty when none was given.
+	Field struct {
+		Alias        string
+		Name         string
+		Arguments    []*Argument
+		Directives   []*Directive
+		SelectionSet SelectionSet
+		Position     Position
+	}
+
+	// FragmentSpread includes a named fragment.
+	FragmentSpread struct {
+		Name       string
+		Directives []*Directive
+		Position   Position
+	}
+
+	// InlineFragment selects fields conditionally on a type. TypeCondition
+	// is empty when none was given.
+	InlineFragment struct {
+		TypeCondition string
+		Directives    []*Directive
+		SelectionSet  SelectionSet
+		Position      Position
+	}
+
+	// Argument is a named value passed to a field or directive.
+	Argument struct {
+		Name  string
+		Value *Value
+	}
+
+	// Directive annotates a definition or selection.
+	Directive struct {
+		Name      string
+		Arguments []*Argument
+		Position  Position
+	}
+
+	// Value is an input value. Raw holds the variable name, the literal
+	// text of numbers, booleans and enums, or the decoded string.
+	Value struct {
+		Kind   ValueKind
+		Raw    string
+		List   []*Value
+		Fields []*ObjectField
+	}
+
+	// ObjectField is a field of an input object value.
+	ObjectField struct {
+		Name  string
+		Value *Value
+	}
+
+	// ValueKind is the kind of a Value.
+	ValueKind int
+)
+
+// Value kinds.
+const (
+	VariableValue ValueKind = iota
+	IntValue
+	FloatValue
+	StringValue
+	BlockStringValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+func (*OperationDefinition) definition() {}
+func (*FragmentDefinition) definition()  {}
+
+func (*Field) selection()          {}
+func (*FragmentSpread) selection() {}
+func (*InlineFragment) selection() {}
+
+// Operations gets the operations of the document.
+func (d *Document) Operations() []*OperationDefinition {
+	var ops []*OperationDefinition
+	for _, def := range d.Definitions {
+		if op, ok := def.(*OperationDefinition); ok {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// Fragment gets the fragment with the given name, or nil.
+func (d *Document) Fragment(name string) *FragmentDefinition {
+	for _, def := range d.Definitions {
+		if f, ok := def.(*FragmentDefinition); ok && f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Operation selects the operation that would be executed for name. An
+// empty name selects the only operation of the document.
+func (d *Document) Operation(name string) (*OperationDefinition, error) {
+	ops := d.Operations()
+	if name == "" {
+		switch len(ops) {
+		case 0:
+			return nil, errors.New("document has no operation")
+		case 1:
+			return ops[0], nil
+		default:
+			return nil, errors.New("document has several operations, an operation name is required")
+		}
+	}
+	for _, op := range ops {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, errors.Errorf("document has no operation named %q", name)
+}
+
+// String formats the type as in a variable definition, e.g. "[ID!]!".
+func (t *Type) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Resolve converts the value to its Go representation, replacing
+// variables with their value in vars. Objects become
+// map[string]interface{}, lists []interface{}, integers int64 and floats
+// float64; enums are returned as their name.
+func (v *Value) Resolve(vars map[string]interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	switch v.Kind {
+	case VariableValue:
+		return vars[v.Raw]
+	case IntValue:
+		if i, err := strconv.ParseInt(v.Raw, 10, 64); err == nil {
+			return i
+		}
+		f, _ := strconv.ParseFloat(v.Raw, 64)
+		return f
+	case FloatValue:
+		f, _ := strconv.ParseFloat(v.Raw, 64)
+		return f
+	case BooleanValue:
+		return v.Raw == "true"
+	case NullValue:
+		return nil
+	case ListValue:
+		list := make([]interface{}, len(v.List))
+		for i, item := range v.List {
+			list[i] = item.Resolve(vars)
+		}
+		return list
+	case ObjectValue:
+		object := make(map[string]interface{}, len(v.Fields))
+		for _, f := range v.Fields {
+			object[f.Name] = f.Value.Resolve(vars)
+		}
+		return object
+	}
+	return v.Raw
+}
@@ -0,0 +1,325 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenBlockString
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of document"
+	case tokenPunct:
+		return "punctuator"
+	case tokenName:
+		return "name"
+	case tokenInt:
+		return "int"
+	case tokenFloat:
+		return "float"
+	case tokenString, tokenBlockString:
+		return "string"
+	}
+	return "token"
+}
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   Position
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return t.kind.String()
+	}
+	return strconv.Quote(t.value)
+}
+
+// SyntaxError is returned for documents that are not valid GraphQL.
+type SyntaxError struct {
+	Message string
+	Position
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at %d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// Position is a location in the document, both values start at 1.
+type Position struct {
+	Line   int
+	Column int
+}
+
+type lexer struct {
+	src  string
+	off  int
+	line int
+	col  int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{
+		src:  strings.TrimPrefix(src, "\ufeff"),
+		line: 1,
+		col:  1,
+	}
+}
+
+func (l *lexer) errorf(pos Position, format string, args ...interface{}) error {
+	return &SyntaxError{Message: fmt.Sprintf(format, args...), Position: pos}
+}
+
+func (l *lexer) pos() Position {
+	return Position{Line: l.line, Column: l.col}
+}
+
+func (l *lexer) advance(n int) {
+	for i := 0; i < n && l.off < len(l.src); i++ {
+		if l.src[l.off] == '\n' {
+			l.line++
+			l.col = 1
+		} else if utf8.RuneStart(l.src[l.off]) {
+			l.col++
+		}
+		l.off++
+	}
+}
+
+// skipIgnored skips white space, line terminators, commas and comments.
+func (l *lexer) skipIgnored() {
+	for l.off < len(l.src) {
+		switch c := l.src[l.off]; c {
+		case ' ', '\t', '\n', '\r', ',':
+			l.advance(1)
+		case '#':
+			for l.off < len(l.src) && l.src[l.off] != '\n' && l.src[l.off] != '\r' {
+				l.advance(1)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	pos := l.pos()
+	if l.off >= len(l.src) {
+		return token{kind: tokenEOF, pos: pos}, nil
+	}
+
+	c := l.src[l.off]
+	switch {
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		l.advance(1)
+		return token{kind: tokenPunct, value: string(c), pos: pos}, nil
+	case c == '.':
+		if strings.HasPrefix(l.src[l.off:], "...") {
+			l.advance(3)
+			return token{kind: tokenPunct, value: "...", pos: pos}, nil
+		}
+		return token{}, l.errorf(pos, "unexpected %q", ".")
+	case isNameStart(c):
+		start := l.off
+		for l.off < len(l.src) && isNameContinue(l.src[l.off]) {
+			l.advance(1)
+		}
+		return token{kind: tokenName, value: l.src[start:l.off], pos: pos}, nil
+	case c == '-' || isDigit(c):
+		return l.number(pos)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.off:], `"""`) {
+			return l.blockString(pos)
+		}
+		return l.string(pos)
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.src[l.off:])
+	return token{}, l.errorf(pos, "unexpected character %q", r)
+}
+
+func (l *lexer) number(pos Position) (token, error) {
+	start := l.off
+	kind := tokenInt
+	if l.src[l.off] == '-' {
+		l.advance(1)
+	}
+	if l.off >= len(l.src) || !isDigit(l.src[l.off]) {
+		return token{}, l.errorf(pos, "invalid number")
+	}
+	if l.src[l.off] == '0' && l.off+1 < len(l.src) && isDigit(l.src[l.off+1]) {
+		return token{}, l.errorf(pos, "invalid number, unexpected digit after 0")
+	}
+	l.digits()
+	if l.off < len(l.src) && l.src[l.off] == '.' {
+		kind = tokenFloat
+		l.advance(1)
+		if l.off >= len(l.src) || !isDigit(l.src[l.off]) {
+			return token{}, l.errorf(pos, "invalid number, expected digit after .")
+		}
+		l.digits()
+	}
+	if l.off < len(l.src) && (l.src[l.off] == 'e' || l.src[l.off] == 'E') {
+		kind = tokenFloat
+		l.advance(1)
+		if l.off < len(l.src) && (l.src[l.off] == '+' || l.src[l.off] == '-') {
+			l.advance(1)
+		}
+		if l.off >= len(l.src) || !isDigit(l.src[l.off]) {
+			return token{}, l.errorf(pos, "invalid number, expected digit in exponent")
+		}
+		l.digits()
+	}
+	if l.off < len(l.src) && (isNameStart(l.src[l.off]) || l.src[l.off] == '.') {
+		return token{}, l.errorf(pos, "invalid number, unexpected %q", l.src[l.off])
+	}
+	return token{kind: kind, value: l.src[start:l.off], pos: pos}, nil
+}
+
+func (l *lexer) digits() {
+	for l.off < len(l.src) && isDigit(l.src[l.off]) {
+		l.advance(1)
+	}
+}
+
+func (l *lexer) string(pos Position) (token, error) {
+	l.advance(1)
+	var b strings.Builder
+	for {
+		if l.off >= len(l.src) {
+			return token{}, l.errorf(pos, "unterminated string")
+		}
+		c := l.src[l.off]
+		switch c {
+		case '"':
+			l.advance(1)
+			return token{kind: tokenString, value: b.String(), pos: pos}, nil
+		case '\n', '\r':
+			return token{}, l.errorf(pos, "unterminated string")
+		case '\\':
+			if l.off+1 >= len(l.src) {
+				return token{}, l.errorf(pos, "unterminated string")
+			}
+			esc := l.src[l.off+1]
+			switch esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.off+6 > len(l.src) {
+					return token{}, l.errorf(l.pos(), "invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(l.src[l.off+2:l.off+6], 16, 32)
+				if err != nil {
+					return token{}, l.errorf(l.pos(), "invalid unicode escape")
+				}
+				b.WriteRune(rune(code))
+				l.advance(4)
+			default:
+				return token{}, l.errorf(l.pos(), "invalid escape sequence \\%c", esc)
+			}
+			l.advance(2)
+		default:
+			b.WriteByte(c)
+			l.advance(1)
+		}
+	}
+}
+
+func (l *lexer) blockString(pos Position) (token, error) {
+	l.advance(3)
+	start := l.off
+	var raw strings.Builder
+	for {
+		if l.off >= len(l.src) {
+			return token{}, l.errorf(pos, "unterminated block string")
+		}
+		if strings.HasPrefix(l.src[l.off:], `"""`) {
+			raw.WriteString(l.src[start:l.off])
+			l.advance(3)
+			return token{kind: tokenBlockString, value: blockStringValue(raw.String()), pos: pos}, nil
+		}
+		if strings.HasPrefix(l.src[l.off:], `\"""`) {
+			raw.WriteString(l.src[start:l.off])
+			raw.WriteString(`"""`)
+			l.advance(4)
+			start = l.off
+			continue
+		}
+		l.advance(1)
+	}
+}
+
+// blockStringValue removes the common indentation and the leading and
+// trailing blank lines of a block string.
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(raw, "\r\n", "\n"), "\r", "\n"), "\n")
+
+	indent := -1
+	for i, line := range lines {
+		if i == 0 {
+			continue
+		}
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
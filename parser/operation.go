@@ -0,0 +1,93 @@
+package parser
+
+import "github.com/pkg/errors"
+
+// OperationName gets the name of the operation of src that is executed
+// for operationName, as Document.Operation selects it. It is empty for
+// anonymous operations. Documents that are not valid GraphQL, e.g.
+// with empty selection sets, are resolved from the headers of their
+// operations so the name is still known when the server will reject
+// them.
+func OperationName(src, operationName string) (string, error) {
+	doc, err := Parse(src)
+	if err == nil {
+		op, err := doc.Operation(operationName)
+		if err != nil {
+			return "", err
+		}
+		return op.Name, nil
+	}
+	names, serr := scanOperationNames(src)
+	if serr != nil {
+		return "", err
+	}
+	if operationName != "" {
+		for _, name := range names {
+			if name == operationName {
+				return name, nil
+			}
+		}
+		return "", errors.Errorf("document has no operation named %q", operationName)
+	}
+	if len(names) != 1 {
+		return "", err
+	}
+	return names[0], nil
+}
+
+// scanOperationNames lists the names of the operations of src from their
+// headers, without validating the rest of the document.
+func scanOperationNames(src string) ([]string, error) {
+	var (
+		lex    = newLexer(src)
+		names  []string
+		braces int
+		parens int
+		// inDefinition is set from the first token of a definition up to
+		// its closing brace.
+		inDefinition bool
+		// header is set after an operation type, whose name may follow.
+		header bool
+	)
+	for {
+		tok, err := lex.next()
+		if err != nil {
+			return nil, err
+		}
+		if header {
+			header = false
+			if tok.kind == tokenName {
+				names = append(names, tok.value)
+				continue
+			}
+			names = append(names, "")
+		}
+		switch {
+		case tok.kind == tokenEOF:
+			return names, nil
+		case tok.kind == tokenPunct && tok.value == "(":
+			parens++
+		case tok.kind == tokenPunct && tok.value == ")":
+			parens--
+		case parens > 0:
+			// Object values of arguments and defaults contain braces.
+		case tok.kind == tokenPunct && tok.value == "{":
+			if braces == 0 && !inDefinition {
+				inDefinition = true
+				names = append(names, "")
+			}
+			braces++
+		case tok.kind == tokenPunct && tok.value == "}":
+			braces--
+			if braces == 0 {
+				inDefinition = false
+			}
+		case braces == 0 && !inDefinition && tok.kind == tokenName:
+			inDefinition = true
+			switch tok.value {
+			case Query, Mutation, Subscription:
+				header = true
+			}
+		}
+	}
+}
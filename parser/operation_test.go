@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestOperationName(t *testing.T) {
+	is := is.New(t)
+
+	tests := []struct {
+		src, operationName, want string
+	}{
+		{`query FooBar { a }`, "", "FooBar"},
+		{`{ a }`, "", ""},
+		{"# query Commented { a }\nquery Real { a }", "", "Real"},
+		{`query A { a(s: "query B ") }`, "", "A"},
+		{`query A { a } mutation B { b }`, "B", "B"},
+		{`query A($v: In = {x: {y: 1}}) { a } fragment F on T { f }`, "", "A"},
+		{`query FooBar {}`, "", "FooBar"},
+		{`mutation FooBar {} query Other {}`, "Other", "Other"},
+	}
+	for _, test := range tests {
+		name, err := OperationName(test.src, test.operationName)
+		is.NoErr(err)
+		is.Equal(name, test.want)
+	}
+
+	_, err := OperationName(`query A { a } query B { b }`, "")
+	is.Equal(err.Error(), "document has several operations, an operation name is required")
+	_, err = OperationName(`query A {}`, "B")
+	is.Equal(err.Error(), `document has no operation named "B"`)
+}
@@ -0,0 +1,436 @@
+// Package parser parses executable GraphQL documents, the operations and
+// fragments sent by clients, into an AST.
+package parser
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+// Parse parses an executable document. Type system definitions are not
+// supported and result in a *SyntaxError.
+func Parse(src string) (*Document, error) {
+	p := &parser{lex: newLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	for p.tok.kind != tokenEOF {
+		def, err := p.definition()
+		if err != nil {
+			return nil, err
+		}
+		doc.Definitions = append(doc.Definitions, def)
+	}
+	if len(doc.Definitions) == 0 {
+		return nil, &SyntaxError{Message: "empty document", Position: p.tok.pos}
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	return p.lex.errorf(p.tok.pos, "unexpected %s", p.tok)
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+func (p *parser) peekName(name string) bool {
+	return p.tok.kind == tokenName && p.tok.value == name
+}
+
+// skip consumes punct when it is the current token.
+func (p *parser) skip(punct string) (bool, error) {
+	if !p.peek(punct) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.peek(punct) {
+		return p.lex.errorf(p.tok.pos, "expected %q, found %s", punct, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.lex.errorf(p.tok.pos, "expected name, found %s", p.tok)
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) definition() (Definition, error) {
+	if p.peek("{") {
+		pos := p.tok.pos
+		set, err := p.selectionSet()
+		if err != nil {
+			return nil, err
+		}
+		return &OperationDefinition{Type: Query, SelectionSet: set, Position: pos}, nil
+	}
+	if p.tok.kind == tokenName {
+		switch p.tok.value {
+		case Query, Mutation, Subscription:
+			return p.operation()
+		case "fragment":
+			return p.fragment()
+		}
+	}
+	return nil, p.unexpected()
+}
+
+func (p *parser) operation() (*OperationDefinition, error) {
+	op := &OperationDefinition{Type: p.tok.value, Position: p.tok.pos}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if p.tok.kind == tokenName {
+		if op.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek("(") {
+		if op.Variables, err = p.variableDefinitions(); err != nil {
+			return nil, err
+		}
+	}
+	if op.Directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if op.SelectionSet, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+func (p *parser) fragment() (*FragmentDefinition, error) {
+	f := &FragmentDefinition{Position: p.tok.pos}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if p.peekName("on") {
+		return nil, p.lex.errorf(p.tok.pos, "expected fragment name, found \"on\"")
+	}
+	if f.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if !p.peekName("on") {
+		return nil, p.lex.errorf(p.tok.pos, "expected \"on\", found %s", p.tok)
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if f.TypeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if f.Directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if f.SelectionSet, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (p *parser) variableDefinitions() ([]*VariableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var defs []*VariableDefinition
+	for {
+		if ok, err := p.skip(")"); err != nil || ok {
+			if len(defs) == 0 && err == nil {
+				return nil, p.lex.errorf(p.tok.pos, "expected variable definition")
+			}
+			return defs, err
+		}
+
+		def := &VariableDefinition{Position: p.tok.pos}
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		var err error
+		if def.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if def.Type, err = p.typ(); err != nil {
+			return nil, err
+		}
+		if ok, err := p.skip("="); err != nil {
+			return nil, err
+		} else if ok {
+			if def.DefaultValue, err = p.value(true); err != nil {
+				return nil, err
+			}
+		}
+		if def.Directives, err = p.directives(true); err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+}
+
+func (p *parser) typ() (*Type, error) {
+	t := &Type{}
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		if t.Elem, err = p.typ(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	} else if t.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	nonNull, err := p.skip("!")
+	t.NonNull = nonNull
+	return t, err
+}
+
+func (p *parser) directives(constant bool) ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek("@") {
+		d := &Directive{Position: p.tok.pos}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		if d.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if d.Arguments, err = p.arguments(constant); err != nil {
+			return nil, err
+		}
+		directives = append(directives, d)
+	}
+	return directives, nil
+}
+
+func (p *parser) arguments(constant bool) ([]*Argument, error) {
+	if !p.peek("(") {
+		return nil, nil
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	var args []*Argument
+	for {
+		if ok, err := p.skip(")"); err != nil || ok {
+			if len(args) == 0 && err == nil {
+				return nil, p.lex.errorf(p.tok.pos, "expected argument")
+			}
+			return args, err
+		}
+
+		arg := &Argument{}
+		var err error
+		if arg.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if arg.Value, err = p.value(constant); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+}
+
+func (p *parser) selectionSet() (SelectionSet, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var set SelectionSet
+	for {
+		if ok, err := p.skip("}"); err != nil || ok {
+			if len(set) == 0 && err == nil {
+				return nil, p.lex.errorf(p.tok.pos, "expected selection")
+			}
+			return set, err
+		}
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, sel)
+	}
+}
+
+func (p *parser) selection() (Selection, error) {
+	if p.peek("...") {
+		return p.fragmentSelection()
+	}
+
+	f := &Field{Position: p.tok.pos}
+	var err error
+	if f.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.Alias = f.Name
+		if f.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if f.Arguments, err = p.arguments(false); err != nil {
+		return nil, err
+	}
+	if f.Directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if f.SelectionSet, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) fragmentSelection() (Selection, error) {
+	pos := p.tok.pos
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokenName && !p.peekName("on") {
+		spread := &FragmentSpread{Position: pos}
+		var err error
+		if spread.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if spread.Directives, err = p.directives(false); err != nil {
+			return nil, err
+		}
+		return spread, nil
+	}
+
+	f := &InlineFragment{Position: pos}
+	var err error
+	if p.peekName("on") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if f.TypeCondition, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if f.Directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if f.SelectionSet, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// value parses an input value. Variables are not allowed in constant
+// values such as defaults.
+func (p *parser) value(constant bool) (*Value, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenPunct:
+		switch tok.value {
+		case "$":
+			if constant {
+				return nil, p.lex.errorf(tok.pos, "unexpected variable in constant value")
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			return &Value{Kind: VariableValue, Raw: name}, nil
+		case "[":
+			return p.listValue(constant)
+		case "{":
+			return p.objectValue(constant)
+		}
+	case tokenInt:
+		return &Value{Kind: IntValue, Raw: tok.value}, p.advance()
+	case tokenFloat:
+		return &Value{Kind: FloatValue, Raw: tok.value}, p.advance()
+	case tokenString:
+		return &Value{Kind: StringValue, Raw: tok.value}, p.advance()
+	case tokenBlockString:
+		return &Value{Kind: BlockStringValue, Raw: tok.value}, p.advance()
+	case tokenName:
+		switch tok.value {
+		case "true", "false":
+			return &Value{Kind: BooleanValue, Raw: tok.value}, p.advance()
+		case "null":
+			return &Value{Kind: NullValue, Raw: tok.value}, p.advance()
+		}
+		return &Value{Kind: EnumValue, Raw: tok.value}, p.advance()
+	}
+	return nil, p.unexpected()
+}
+
+func (p *parser) listValue(constant bool) (*Value, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	v := &Value{Kind: ListValue}
+	for {
+		if ok, err := p.skip("]"); err != nil || ok {
+			return v, err
+		}
+		item, err := p.value(constant)
+		if err != nil {
+			return nil, err
+		}
+		v.List = append(v.List, item)
+	}
+}
+
+func (p *parser) objectValue(constant bool) (*Value, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	v := &Value{Kind: ObjectValue}
+	for {
+		if ok, err := p.skip("}"); err != nil || ok {
+			return v, err
+		}
+		f := &ObjectField{}
+		var err error
+		if f.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if f.Value, err = p.value(constant); err != nil {
+			return nil, err
+		}
+		v.Fields = append(v.Fields, f)
+	}
+}
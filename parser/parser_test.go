@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestParse(t *testing.T) {
+	is := is.New(t)
+
+	doc, err := Parse(`
+		# fetch a merchant
+		query Merchant($code: String!, $first: Int = 10, $ids: [ID!]) @cached(ttl: 60) {
+			m: merchant(code: $code, filter: {status: ACTIVE, tags: ["a", "b"]}) {
+				id
+				... on Node { id }
+				...MerchantFields @include(if: true)
+				description(format: """
+					Markdown
+				""")
+			}
+		}
+
+		fragment MerchantFields on Merchant {
+			name
+			rating(scale: -1.5e2)
+		}
+	`)
+	is.NoErr(err)
+	is.Equal(len(doc.Definitions), 2)
+
+	op, err := doc.Operation("")
+	is.NoErr(err)
+	is.Equal(op.Type, Query)
+	is.Equal(op.Name, "Merchant")
+	is.Equal(op.Position, Position{Line: 3, Column: 3})
+	is.Equal(len(op.Variables), 3)
+	is.Equal(op.Variables[0].Type.String(), "String!")
+	is.Equal(op.Variables[1].DefaultValue.Resolve(nil), int64(10))
+	is.Equal(op.Variables[2].Type.String(), "[ID!]")
+	is.Equal(op.Directives[0].Name, "cached")
+
+	field := op.SelectionSet[0].(*Field)
+	is.Equal(field.Alias, "m")
+	is.Equal(field.Name, "merchant")
+	is.Equal(field.Arguments[0].Value.Resolve(map[string]interface{}{"code": "abc"}), "abc")
+	is.Equal(field.Arguments[1].Value.Resolve(nil), map[string]interface{}{
+		"status": "ACTIVE",
+		"tags":   []interface{}{"a", "b"},
+	})
+	is.Equal(len(field.SelectionSet), 4)
+	is.Equal(field.SelectionSet[1].(*InlineFragment).TypeCondition, "Node")
+	is.Equal(field.SelectionSet[2].(*FragmentSpread).Name, "MerchantFields")
+	is.Equal(field.SelectionSet[3].(*Field).Arguments[0].Value.Raw, "Markdown")
+
+	fragment := doc.Fragment("MerchantFields")
+	is.Equal(fragment.TypeCondition, "Merchant")
+	is.Equal(fragment.SelectionSet[1].(*Field).Arguments[0].Value.Resolve(nil), -150.0)
+}
+
+func TestParseShorthand(t *testing.T) {
+	is := is.New(t)
+
+	doc, err := Parse(`{ viewer { login } }`)
+	is.NoErr(err)
+
+	op, err := doc.Operation("")
+	is.NoErr(err)
+	is.Equal(op.Type, Query)
+	is.Equal(op.Name, "")
+}
+
+func TestDocumentOperation(t *testing.T) {
+	is := is.New(t)
+
+	doc, err := Parse(`query A { a } mutation B { b } fragment F on T { f }`)
+	is.NoErr(err)
+
+	_, err = doc.Operation("")
+	is.Equal(err.Error(), "document has several operations, an operation name is required")
+
+	op, err := doc.Operation("B")
+	is.NoErr(err)
+	is.Equal(op.Type, Mutation)
+
+	_, err = doc.Operation("C")
+	is.Equal(err.Error(), `document has no operation named "C"`)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{src: ``, err: "syntax error at 1:1: empty document"},
+		{src: `query {`, err: `syntax error at 1:8: expected name, found end of document`},
+		{src: `query { a(b: ) }`, err: `syntax error at 1:14: unexpected ")"`},
+		{src: `query ($a: Int = $b) { a }`, err: `syntax error at 1:18: unexpected variable in constant value`},
+		{src: "{ a(b: \"unterminated) }", err: `syntax error at 1:8: unterminated string`},
+		{src: `{ a(b: 01) }`, err: `syntax error at 1:8: invalid number, unexpected digit after 0`},
+		{src: `type Query { a: Int }`, err: `syntax error at 1:1: unexpected "type"`},
+		{src: `{ a } }`, err: `syntax error at 1:7: unexpected "}"`},
+		{src: "{\n  a ? }", err: `syntax error at 2:5: unexpected character '?'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			is := is.New(t)
+			_, err := Parse(tt.src)
+			is.True(err != nil)
+			is.Equal(err.Error(), tt.err)
+		})
+	}
+}
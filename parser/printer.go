@@ -0,0 +1,270 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Printer prints documents. The zero value prints compactly on a single
+// line, which suits logging.
+type Printer struct {
+	// Indent is the indentation of each nesting level. Documents are
+	// printed on multiple lines when it is set.
+	Indent string
+	// SortArguments orders arguments and input object fields by name.
+	SortArguments bool
+}
+
+// Format parses src and prints it with two space indentation and sorted
+// arguments, for consistent snapshots and generated code.
+func Format(src string) (string, error) {
+	doc, err := Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return Printer{Indent: "  ", SortArguments: true}.Print(doc), nil
+}
+
+// Compact parses src and prints it on a single line.
+func Compact(src string) (string, error) {
+	doc, err := Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return Printer{}.Print(doc), nil
+}
+
+// Print prints doc.
+func (p Printer) Print(doc *Document) string {
+	w := &writer{Printer: p}
+	for i, def := range doc.Definitions {
+		if i > 0 {
+			if p.Indent != "" {
+				w.WriteString("\n\n")
+			} else {
+				w.WriteString(" ")
+			}
+		}
+		switch def := def.(type) {
+		case *OperationDefinition:
+			w.operation(def)
+		case *FragmentDefinition:
+			w.fragment(def)
+		}
+	}
+	if p.Indent != "" {
+		w.WriteString("\n")
+	}
+	return w.String()
+}
+
+type writer struct {
+	Printer
+	strings.Builder
+	depth int
+}
+
+func (w *writer) operation(op *OperationDefinition) {
+	if op.Name == "" && len(op.Variables) == 0 && len(op.Directives) == 0 && op.Type == Query {
+		w.selectionSet(op.SelectionSet)
+		return
+	}
+	w.WriteString(op.Type)
+	if op.Name != "" {
+		w.WriteString(" " + op.Name)
+	}
+	if len(op.Variables) > 0 {
+		w.WriteString("(")
+		for i, v := range op.Variables {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			w.WriteString("$" + v.Name + ": " + v.Type.String())
+			if v.DefaultValue != nil {
+				w.WriteString(" = ")
+				w.value(v.DefaultValue)
+			}
+			w.directives(v.Directives)
+		}
+		w.WriteString(")")
+	}
+	w.directives(op.Directives)
+	w.WriteString(" ")
+	w.selectionSet(op.SelectionSet)
+}
+
+func (w *writer) fragment(f *FragmentDefinition) {
+	w.WriteString("fragment " + f.Name + " on " + f.TypeCondition)
+	w.directives(f.Directives)
+	w.WriteString(" ")
+	w.selectionSet(f.SelectionSet)
+}
+
+func (w *writer) selectionSet(set SelectionSet) {
+	w.WriteString("{")
+	w.depth++
+	for _, sel := range set {
+		if w.Indent != "" {
+			w.newline()
+		} else {
+			w.WriteString(" ")
+		}
+		w.selection(sel)
+	}
+	w.depth--
+	if w.Indent != "" {
+		w.newline()
+	} else {
+		w.WriteString(" ")
+	}
+	w.WriteString("}")
+}
+
+func (w *writer) selection(sel Selection) {
+	switch sel := sel.(type) {
+	case *Field:
+		if sel.Alias != "" {
+			w.WriteString(sel.Alias + ": ")
+		}
+		w.WriteString(sel.Name)
+		w.arguments(sel.Arguments)
+		w.directives(sel.Directives)
+		if len(sel.SelectionSet) > 0 {
+			w.WriteString(" ")
+			w.selectionSet(sel.SelectionSet)
+		}
+	case *FragmentSpread:
+		w.WriteString("..." + sel.Name)
+		w.directives(sel.Directives)
+	case *InlineFragment:
+		w.WriteString("...")
+		if sel.TypeCondition != "" {
+			w.WriteString(" on " + sel.TypeCondition)
+		}
+		w.directives(sel.Directives)
+		w.WriteString(" ")
+		w.selectionSet(sel.SelectionSet)
+	}
+}
+
+func (w *writer) directives(directives []*Directive) {
+	for _, d := range directives {
+		w.WriteString(" @" + d.Name)
+		w.arguments(d.Arguments)
+	}
+}
+
+func (w *writer) arguments(args []*Argument) {
+	if len(args) == 0 {
+		return
+	}
+	if w.SortArguments {
+		args = append([]*Argument(nil), args...)
+		sort.SliceStable(args, func(i, j int) bool { return args[i].Name < args[j].Name })
+	}
+	w.WriteString("(")
+	for i, arg := range args {
+		if i > 0 {
+			w.WriteString(", ")
+		}
+		w.WriteString(arg.Name + ": ")
+		w.value(arg.Value)
+	}
+	w.WriteString(")")
+}
+
+func (w *writer) value(v *Value) {
+	switch v.Kind {
+	case VariableValue:
+		w.WriteString("$" + v.Raw)
+	case StringValue:
+		w.WriteString(quote(v.Raw))
+	case BlockStringValue:
+		w.blockString(v.Raw)
+	case ListValue:
+		w.WriteString("[")
+		for i, item := range v.List {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			w.value(item)
+		}
+		w.WriteString("]")
+	case ObjectValue:
+		fields := v.Fields
+		if w.SortArguments {
+			fields = append([]*ObjectField(nil), fields...)
+			sort.SliceStable(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+		}
+		w.WriteString("{")
+		for i, f := range fields {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			w.WriteString(f.Name + ": ")
+			w.value(f.Value)
+		}
+		w.WriteString("}")
+	default:
+		w.WriteString(v.Raw)
+	}
+}
+
+// blockString prints a block string, falling back to a quoted string
+// when printing on a single line.
+func (w *writer) blockString(s string) {
+	if w.Indent == "" {
+		w.WriteString(quote(s))
+		return
+	}
+	w.WriteString(`"""`)
+	w.depth++
+	for _, line := range strings.Split(strings.ReplaceAll(s, `"""`, `\"""`), "\n") {
+		if line == "" {
+			w.WriteString("\n")
+			continue
+		}
+		w.newline()
+		w.WriteString(line)
+	}
+	w.depth--
+	w.newline()
+	w.WriteString(`"""`)
+}
+
+func (w *writer) newline() {
+	w.WriteString("\n" + strings.Repeat(w.Indent, w.depth))
+}
+
+// quote quotes s as a GraphQL string.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const unformatted = `query Merchant($code:String!,$first:Int=10)@cached(ttl:60){
+m:merchant(code:$code,filter:{tags:["a","b"],status:ACTIVE}){id,...on Node{id} ...Fields @include(if:true)
+note(text:"line\nbreak \"quoted\"")
+description(format:"""
+    Markdown
+
+    text
+""")}}
+fragment Fields on Merchant{name}
+{ ping }`
+
+func TestFormat(t *testing.T) {
+	is := is.New(t)
+
+	formatted, err := Format(unformatted)
+	is.NoErr(err)
+	is.Equal(formatted, `query Merchant($code: String!, $first: Int = 10) @cached(ttl: 60) {
+  m: merchant(code: $code, filter: {status: ACTIVE, tags: ["a", "b"]}) {
+    id
+    ... on Node {
+      id
+    }
+    ...Fields @include(if: true)
+    note(text: "line\nbreak \"quoted\"")
+    description(format: """
+      Markdown
+
+      text
+    """)
+  }
+}
+
+fragment Fields on Merchant {
+  name
+}
+
+{
+  ping
+}
+`)
+
+	again, err := Format(formatted)
+	is.NoErr(err)
+	is.Equal(again, formatted)
+}
+
+func TestCompact(t *testing.T) {
+	is := is.New(t)
+
+	compact, err := Compact(unformatted)
+	is.NoErr(err)
+	is.Equal(compact, `query Merchant($code: String!, $first: Int = 10) @cached(ttl: 60) { m: merchant(code: $code, filter: {tags: ["a", "b"], status: ACTIVE}) { id ... on Node { id } ...Fields @include(if: true) note(text: "line\nbreak \"quoted\"") description(format: "Markdown\n\ntext") } } fragment Fields on Merchant { name } { ping }`)
+}
+
+func TestFormatError(t *testing.T) {
+	is := is.New(t)
+
+	_, err := Format(`{`)
+	is.Equal(err.Error(), "syntax error at 1:2: expected name, found end of document")
+}
@@ -0,0 +1,206 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ValidationError reports a problem found by Validate.
+type ValidationError struct {
+	Message string
+	Position
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// Validate checks the rules of a document that do not need a schema:
+// operation names are unique, anonymous operations are alone, spread
+// fragments exist and do not form cycles, and every variable used by an
+// operation, directly or through fragments, is declared and used.
+func Validate(doc *Document) []*ValidationError {
+	v := &validator{doc: doc}
+	v.operations()
+	v.fragments()
+	for _, op := range doc.Operations() {
+		v.variables(op)
+	}
+	sort.SliceStable(v.errs, func(i, j int) bool {
+		a, b := v.errs[i].Position, v.errs[j].Position
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+	return v.errs
+}
+
+type validator struct {
+	doc  *Document
+	errs []*ValidationError
+}
+
+func (v *validator) errorf(pos Position, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{Message: fmt.Sprintf(format, args...), Position: pos})
+}
+
+func (v *validator) operations() {
+	ops := v.doc.Operations()
+	seen := make(map[string]bool)
+	for _, op := range ops {
+		if op.Name == "" {
+			if len(ops) > 1 {
+				v.errorf(op.Position, "anonymous operation must be the only operation")
+			}
+			continue
+		}
+		if seen[op.Name] {
+			v.errorf(op.Position, "duplicate operation %q", op.Name)
+		}
+		seen[op.Name] = true
+	}
+}
+
+func (v *validator) fragments() {
+	seen := make(map[string]bool)
+	for _, def := range v.doc.Definitions {
+		f, ok := def.(*FragmentDefinition)
+		if !ok {
+			continue
+		}
+		if seen[f.Name] {
+			v.errorf(f.Position, "duplicate fragment %q", f.Name)
+		}
+		seen[f.Name] = true
+		if v.spreads(f.SelectionSet, f.Name, map[string]bool{}) {
+			v.errorf(f.Position, "fragment %q spreads itself", f.Name)
+		}
+	}
+}
+
+// spreads reports whether set spreads the fragment target, following
+// other fragments. Unknown fragments are reported by variables.
+func (v *validator) spreads(set SelectionSet, target string, visited map[string]bool) bool {
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *Field:
+			if v.spreads(sel.SelectionSet, target, visited) {
+				return true
+			}
+		case *InlineFragment:
+			if v.spreads(sel.SelectionSet, target, visited) {
+				return true
+			}
+		case *FragmentSpread:
+			if sel.Name == target {
+				return true
+			}
+			if visited[sel.Name] {
+				continue
+			}
+			visited[sel.Name] = true
+			if f := v.doc.Fragment(sel.Name); f != nil && v.spreads(f.SelectionSet, target, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type variableUse struct {
+	name string
+	pos  Position
+}
+
+func (v *validator) variables(op *OperationDefinition) {
+	var uses []variableUse
+	visited := make(map[string]bool)
+	for _, d := range op.Directives {
+		uses = appendArgumentUses(uses, d.Arguments, d.Position)
+	}
+	uses = v.collectUses(uses, op.SelectionSet, visited)
+
+	declared := make(map[string]bool)
+	for _, def := range op.Variables {
+		if declared[def.Name] {
+			v.errorf(def.Position, "variable $%s is declared twice", def.Name)
+		}
+		declared[def.Name] = true
+	}
+
+	used := make(map[string]bool)
+	reported := make(map[string]bool)
+	for _, use := range uses {
+		used[use.name] = true
+		if !declared[use.name] && !reported[use.name] {
+			reported[use.name] = true
+			if op.Name != "" {
+				v.errorf(use.pos, "variable $%s is not declared by operation %q", use.name, op.Name)
+			} else {
+				v.errorf(use.pos, "variable $%s is not declared", use.name)
+			}
+		}
+	}
+	for _, def := range op.Variables {
+		if !used[def.Name] {
+			v.errorf(def.Position, "variable $%s is declared but not used", def.Name)
+		}
+	}
+}
+
+func (v *validator) collectUses(uses []variableUse, set SelectionSet, visited map[string]bool) []variableUse {
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *Field:
+			uses = appendArgumentUses(uses, sel.Arguments, sel.Position)
+			for _, d := range sel.Directives {
+				uses = appendArgumentUses(uses, d.Arguments, d.Position)
+			}
+			uses = v.collectUses(uses, sel.SelectionSet, visited)
+		case *InlineFragment:
+			for _, d := range sel.Directives {
+				uses = appendArgumentUses(uses, d.Arguments, d.Position)
+			}
+			uses = v.collectUses(uses, sel.SelectionSet, visited)
+		case *FragmentSpread:
+			for _, d := range sel.Directives {
+				uses = appendArgumentUses(uses, d.Arguments, d.Position)
+			}
+			if visited[sel.Name] {
+				continue
+			}
+			visited[sel.Name] = true
+			f := v.doc.Fragment(sel.Name)
+			if f == nil {
+				v.errorf(sel.Position, "unknown fragment %q", sel.Name)
+				continue
+			}
+			for _, d := range f.Directives {
+				uses = appendArgumentUses(uses, d.Arguments, d.Position)
+			}
+			uses = v.collectUses(uses, f.SelectionSet, visited)
+		}
+	}
+	return uses
+}
+
+func appendArgumentUses(uses []variableUse, args []*Argument, pos Position) []variableUse {
+	for _, arg := range args {
+		uses = appendValueUses(uses, arg.Value, pos)
+	}
+	return uses
+}
+
+func appendValueUses(uses []variableUse, value *Value, pos Position) []variableUse {
+	switch value.Kind {
+	case VariableValue:
+		uses = append(uses, variableUse{name: value.Raw, pos: pos})
+	case ListValue:
+		for _, item := range value.List {
+			uses = appendValueUses(uses, item, pos)
+		}
+	case ObjectValue:
+		for _, f := range value.Fields {
+			uses = appendValueUses(uses, f.Value, pos)
+		}
+	}
+	return uses
+}
@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestValidate(t *testing.T) {
+	is := is.New(t)
+
+	doc, err := Parse(`query A($id: ID!, $unused: Int, $id: ID) {
+  merchant(id: $id) { ...F ...Missing }
+}
+query A { b(x: $x) }
+fragment F on Merchant { payouts(first: $first) { ...G } }
+fragment G on Payout { ...F }`)
+	is.NoErr(err)
+
+	var messages []string
+	for _, err := range Validate(doc) {
+		messages = append(messages, err.Error())
+	}
+	is.Equal(messages, []string{
+		`1:19: variable $unused is declared but not used`,
+		`1:33: variable $id is declared twice`,
+		`2:28: unknown fragment "Missing"`,
+		`4:1: duplicate operation "A"`,
+		`4:11: variable $x is not declared by operation "A"`,
+		`5:1: fragment "F" spreads itself`,
+		`5:26: variable $first is not declared by operation "A"`,
+		`6:1: fragment "G" spreads itself`,
+	})
+}
+
+func TestValidateValid(t *testing.T) {
+	is := is.New(t)
+
+	doc, err := Parse(`query ($id: ID!, $skip: Boolean!) { a(input: {ids: [$id]}) @skip(if: $skip) { ...F } } fragment F on A { b }`)
+	is.NoErr(err)
+	is.Equal(len(Validate(doc)), 0)
+}
@@ -0,0 +1,92 @@
+// Package persisted reads persisted query manifests, which map operation
+// names to documents registered with a gateway ahead of time, and builds
+// operations sending only the ID of their document.
+//
+// Manifests use the format of Apollo's persisted query lists:
+//
+//	{
+//	  "format": "apollo-persisted-query-manifest",
+//	  "version": 1,
+//	  "operations": [
+//	    {"id": "…", "name": "Merchant", "type": "query", "body": "query Merchant { … }"}
+//	  ]
+//	}
+package persisted
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// ManifestFormat identifies Apollo persisted query manifests.
+const ManifestFormat = "apollo-persisted-query-manifest"
+
+type (
+	// Manifest lists persisted operations.
+	Manifest struct {
+		Format     string      `json:"format"`
+		Version    int         `json:"version"`
+		Operations []Operation `json:"operations"`
+	}
+
+	// Operation is a persisted operation. ID defaults to the SHA-256 of
+	// the body when the manifest does not set one.
+	Operation struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+		Body string `json:"body"`
+	}
+)
+
+// DocumentID returns the hex encoded SHA-256 of body, the ID most
+// gateways use for persisted documents.
+func DocumentID(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadManifest reads the manifest at path.
+func LoadManifest(path string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadManifest(f)
+}
+
+// ReadManifest decodes a manifest from r and fills in missing IDs.
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, errors.Wrap(err, "decoding manifest")
+	}
+	if m.Format != "" && m.Format != ManifestFormat {
+		return nil, errors.Errorf("unsupported manifest format %q", m.Format)
+	}
+	for i := range m.Operations {
+		if m.Operations[i].Body == "" {
+			return nil, errors.Errorf("operation %q has no body", m.Operations[i].Name)
+		}
+		if m.Operations[i].ID == "" {
+			m.Operations[i].ID = DocumentID(m.Operations[i].Body)
+		}
+	}
+	return &m, nil
+}
+
+// Lookup finds the operation with the given name.
+func (m *Manifest) Lookup(name string) (Operation, bool) {
+	for _, op := range m.Operations {
+		if op.Name == name {
+			return op, true
+		}
+	}
+	return Operation{}, false
+}
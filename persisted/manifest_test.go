@@ -0,0 +1,44 @@
+package persisted
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestReadManifest(t *testing.T) {
+	is := is.New(t)
+
+	m, err := ReadManifest(strings.NewReader(`{
+		"format": "apollo-persisted-query-manifest",
+		"version": 1,
+		"operations": [
+			{"id": "abc", "name": "Merchant", "type": "query", "body": "query Merchant { merchant { id } }"},
+			{"name": "Ping", "type": "query", "body": "query Ping { ping }"}
+		]
+	}`))
+	is.NoErr(err)
+	is.Equal(len(m.Operations), 2)
+
+	op, ok := m.Lookup("Merchant")
+	is.True(ok)
+	is.Equal(op.ID, "abc")
+
+	op, ok = m.Lookup("Ping")
+	is.True(ok)
+	is.Equal(op.ID, DocumentID("query Ping { ping }"))
+
+	_, ok = m.Lookup("Missing")
+	is.True(!ok)
+}
+
+func TestReadManifestInvalid(t *testing.T) {
+	is := is.New(t)
+
+	_, err := ReadManifest(strings.NewReader(`{"format": "relay"}`))
+	is.Equal(err.Error(), `unsupported manifest format "relay"`)
+
+	_, err = ReadManifest(strings.NewReader(`{"operations": [{"name": "Empty"}]}`))
+	is.Equal(err.Error(), `operation "Empty" has no body`)
+}
@@ -0,0 +1,63 @@
+package persisted
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/sumup/graphql"
+	"github.com/sumup/graphql/parser"
+)
+
+// NewOperation builds the operation of the manifest with the given name,
+// a *graphql.Mutation for mutations and a *graphql.Request otherwise,
+// executed by document ID: only the ID is sent, in the payload field key,
+// usually graphql.DocumentIDKey, as gateways that only accept registered
+// operations require.
+//
+//	op, err := manifest.NewOperation("Merchant", graphql.DocumentIDKey)
+//	op.Var("id", id)
+//	err = client.Run(ctx, op, &resp)
+func (m *Manifest) NewOperation(name, key string) (graphql.Operation, error) {
+	persisted, ok := m.Lookup(name)
+	if !ok {
+		return nil, errors.Errorf("manifest has no operation %q", name)
+	}
+	var op graphql.Operation
+	if persisted.Type == parser.Mutation {
+		op = graphql.NewMutation(persisted.Body)
+	} else {
+		op = graphql.NewRequest(persisted.Body)
+	}
+	op.Request().SetDocumentID(key, persisted.ID)
+	return op, nil
+}
+
+// Persist switches op to be executed by the document ID the manifest
+// registers for its operation, found by name, so only the ID is sent in
+// the payload field key. It fails when the manifest has no operation of
+// that name or its document differs from the one of op, ignoring
+// formatting.
+func (m *Manifest) Persist(op graphql.Operation, key string) error {
+	query := op.Request().Query()
+	name, err := parser.OperationName(query, "")
+	if err != nil {
+		return errors.Wrap(err, "finding operation name")
+	}
+	persisted, ok := m.Lookup(name)
+	if !ok {
+		return errors.Errorf("manifest has no operation %q", name)
+	}
+	if compact(persisted.Body) != compact(query) {
+		return errors.Errorf("operation %q differs from the manifest", name)
+	}
+	op.Request().SetDocumentID(key, persisted.ID)
+	return nil
+}
+
+// compact strips the formatting of a document, keeping it as it is when
+// it does not parse.
+func compact(doc string) string {
+	if compacted, err := parser.Compact(doc); err == nil {
+		return compacted
+	}
+	return doc
+}
@@ -0,0 +1,83 @@
+package persisted
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/sumup/graphql"
+)
+
+func TestManifestOperations(t *testing.T) {
+	is := is.New(t)
+
+	m, err := ReadManifest(strings.NewReader(`{
+		"operations": [
+			{"id": "abc", "name": "Merchant", "type": "query", "body": "query Merchant($id: ID!) { merchant(id: $id) { name } }"},
+			{"id": "def", "name": "Close", "type": "mutation", "body": "mutation Close { close { successful } }"}
+		]
+	}`))
+	is.NoErr(err)
+
+	var payload map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload = nil
+		is.NoErr(json.NewDecoder(r.Body).Decode(&payload))
+		_, _ = io.WriteString(w, `{"data":{"close":{"successful":true}}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL)
+
+	op, err := m.NewOperation("Merchant", graphql.DocumentIDKey)
+	is.NoErr(err)
+	op.Var("id", "m1")
+	is.NoErr(client.Run(context.Background(), op, nil))
+	is.Equal(payload, map[string]interface{}{"documentId": "abc", "variables": map[string]interface{}{"id": "m1"}})
+
+	op, err = m.NewOperation("Close", graphql.RelayDocIDKey)
+	is.NoErr(err)
+	_, mutation := op.(*graphql.Mutation)
+	is.True(mutation)
+
+	_, err = m.NewOperation("Missing", graphql.DocumentIDKey)
+	is.Equal(err.Error(), `manifest has no operation "Missing"`)
+
+	req := graphql.NewRequest(`
+		query Merchant($id: ID!) {
+			merchant(id: $id) { name }
+		}`)
+	is.NoErr(m.Persist(req, graphql.DocumentIDKey))
+	is.Equal(req.Request().DocumentID(), "abc")
+
+	err = m.Persist(graphql.NewRequest("query Merchant { merchant(id: 1) { id } }"), graphql.DocumentIDKey)
+	is.Equal(err.Error(), `operation "Merchant" differs from the manifest`)
+}
+
+func TestPersistedMutationUseGET(t *testing.T) {
+	is := is.New(t)
+
+	m, err := ReadManifest(strings.NewReader(`{
+		"operations": [
+			{"id": "def", "name": "Pay", "type": "mutation", "body": "mutation Pay { pay { id } }"}
+		]
+	}`))
+	is.NoErr(err)
+
+	var method string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		_, _ = io.WriteString(w, `{"data":{"pay":{"id":"p1"}}}`)
+	}))
+	defer srv.Close()
+
+	req := graphql.NewRequest("mutation Pay { pay { id } }")
+	is.NoErr(m.Persist(req, graphql.DocumentIDKey))
+	is.NoErr(graphql.NewClient(srv.URL, graphql.UseGET()).Run(context.Background(), req, nil))
+	is.Equal(method, http.MethodPost)
+}
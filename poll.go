@@ -0,0 +1,86 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxPollBackoff bounds the delay between polls as a multiple of the
+// initial interval.
+const maxPollBackoff = 8
+
+// PollTimeoutError is the cause of the *ExecutionError returned by Poll
+// when the condition is not met in time.
+type PollTimeoutError struct {
+	// Attempts is the number of times the operation was run.
+	Attempts int
+	// Elapsed is the time spent polling.
+	Elapsed time.Duration
+}
+
+func (e *PollTimeoutError) Error() string {
+	return fmt.Sprintf("poll timed out after %d attempts in %s", e.Attempts, e.Elapsed.Round(time.Millisecond))
+}
+
+// Poll runs op, usually a query of the status of a job started by a
+// mutation, decoding into resp until until returns true for it. The
+// delay between runs starts at interval and grows by half on each run,
+// up to 8 times interval. Poll stops at the first error, or when timeout
+// elapses, even during a run, returning an *ExecutionError caused by a
+// *PollTimeoutError; a timeout of 0 leaves the deadline to ctx. resp is
+// reset to its zero value before each run, so it only holds the fields
+// of the last response. interval must be positive.
+//
+//	var status struct{ Job struct{ State string } }
+//	err := client.Poll(ctx, statusReq, &status, func(interface{}) bool {
+//		return status.Job.State != "PENDING"
+//	}, time.Second, time.Minute)
+func (c *Client) Poll(ctx context.Context, op Operation, resp interface{}, until func(resp interface{}) bool, interval, timeout time.Duration) Error {
+	if interval <= 0 {
+		return NewExecutionError(errors.Errorf("interval %s must be positive", interval))
+	}
+	start := time.Now()
+	// Every run is bound by the timeout, so a hung one cannot outlast it.
+	pollCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		pollCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	// timedOut reports whether the timeout, rather than ctx, ended pollCtx.
+	timedOut := func() bool {
+		return ctx.Err() == nil && errors.Is(pollCtx.Err(), context.DeadlineExceeded)
+	}
+	delay := interval
+	for attempts := 1; ; attempts++ {
+		if v := reflect.ValueOf(resp); v.Kind() == reflect.Ptr && !v.IsNil() {
+			v.Elem().Set(reflect.Zero(v.Elem().Type()))
+		}
+		if err := c.Run(pollCtx, op, resp); err != nil {
+			if timedOut() {
+				return NewExecutionError(&PollTimeoutError{Attempts: attempts, Elapsed: time.Since(start)})
+			}
+			return err
+		}
+		if until(resp) {
+			return nil
+		}
+		wait := time.NewTimer(delay)
+		select {
+		case <-wait.C:
+		case <-pollCtx.Done():
+			wait.Stop()
+			if timedOut() {
+				return NewExecutionError(&PollTimeoutError{Attempts: attempts, Elapsed: time.Since(start)})
+			}
+			return NewExecutionError(ctx.Err())
+		}
+		if delay += delay / 2; delay > maxPollBackoff*interval {
+			delay = maxPollBackoff * interval
+		}
+	}
+}
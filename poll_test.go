@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+)
+
+func TestPoll(t *testing.T) {
+	is := is.New(t)
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		state := "PENDING"
+		if calls == 3 {
+			state = "DONE"
+		}
+		fmt.Fprintf(w, `{"data":{"job":{"state":%q}}}`, state)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	var status struct{ Job struct{ State string } }
+	done := func(interface{}) bool { return status.Job.State == "DONE" }
+
+	is.NoErr(client.Poll(context.Background(), NewRequest("query { job { state } }"), &status, done, time.Millisecond, time.Second))
+	is.Equal(calls, 3)
+
+	calls = -100
+	err := client.Poll(context.Background(), NewRequest("query { job { state } }"), &status, done, time.Millisecond, 20*time.Millisecond)
+	var timeoutErr *PollTimeoutError
+	is.True(errors.As(err, &timeoutErr))
+	is.True(timeoutErr.Attempts > 1)
+	is.True(timeoutErr.Elapsed >= 20*time.Millisecond)
+
+	// A run hanging past the timeout is cut short.
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(300 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	begin := time.Now()
+	err = NewClient(slow.URL).Poll(context.Background(), NewRequest("query { job { state } }"), &status, done, time.Millisecond, 20*time.Millisecond)
+	is.True(errors.As(err, &timeoutErr))
+	is.Equal(timeoutErr.Attempts, 1)
+	is.True(time.Since(begin) < 200*time.Millisecond)
+}
+
+func TestPollFreshResponse(t *testing.T) {
+	is := is.New(t)
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			fmt.Fprint(w, `{"data":{"job":{"state":"PENDING","error":"retrying"}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"job":{"state":"DONE"}}}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	var status struct{ Job struct{ State, Error string } }
+	done := func(interface{}) bool { return status.Job.State == "DONE" }
+	is.NoErr(client.Poll(context.Background(), NewRequest("query { job { state error } }"), &status, done, time.Millisecond, time.Second))
+	// Fields missing from the last response do not keep stale values.
+	is.Equal(status.Job.Error, "")
+
+	err := client.Poll(context.Background(), NewRequest("query { job { state } }"), &status, done, 0, time.Second)
+	is.True(err != nil)
+	is.Equal(calls, 2)
+}
@@ -0,0 +1,222 @@
+// Package queue delivers operations, usually mutations, in the background
+// with retries. Operations are persisted in a Store before they are sent,
+// so with a durable store such as FileStore they survive restarts, which
+// suits devices and services with intermittent connectivity.
+//
+// Operations are delivered one at a time in the order they were
+// enqueued: an operation is only sent once the previous one succeeded or
+// was given up. Delivery is at least once: an operation whose response
+// was lost is sent again, so mutations should carry an idempotency key.
+//
+//	store, err := queue.NewFileStore("/var/lib/app/outbox")
+//	q := queue.New(client, store, queue.DefaultConfig())
+//	go q.Run(ctx)
+//	err = q.Enqueue(graphql.NewMutation(`mutation { ... }`))
+package queue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/sumup/graphql"
+)
+
+// Config configures the delivery.
+type Config struct {
+	// MaxAttempts gives up an operation after the given number of
+	// failed attempts, 0 retries forever.
+	MaxAttempts int
+
+	// MinBackoff and MaxBackoff bound the exponential delay between
+	// attempts of the same operation.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Retryable decides whether a failed operation is attempted again,
+	// Retryable by default.
+	Retryable func(graphql.Error) bool
+
+	// OnFailure is called with operations that are given up.
+	OnFailure func(Item, graphql.Error)
+}
+
+// DefaultConfig returns the configuration used when none is given:
+// operations failing with a transient error are attempted up to 10
+// times, waiting one second up to one minute between attempts.
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts: 10,
+		MinBackoff:  time.Second,
+		MaxBackoff:  time.Minute,
+		Retryable:   Retryable,
+	}
+}
+
+// Queue delivers the operations of a Store.
+type Queue struct {
+	client graphql.GraphClient
+	store  Store
+	cfg    Config
+	notify chan struct{}
+}
+
+// New creates a Queue delivering the operations of store with client.
+// Operations left in the store by a previous process are delivered too.
+func New(client graphql.GraphClient, store Store, cfg Config) *Queue {
+	if cfg.Retryable == nil {
+		cfg.Retryable = Retryable
+	}
+	return &Queue{
+		client: client,
+		store:  store,
+		cfg:    cfg,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// varsConverter converts variables as a client encodes them, see
+// graphql.Client.ConvertVars.
+type varsConverter interface {
+	ConvertVars(graphql.Operation) map[string]interface{}
+}
+
+// Enqueue stores op for delivery. The document, variables, headers,
+// tags, document ID and priority are kept, copied so later changes to op
+// do not affect it; operations with files cannot be enqueued. Variables
+// are stored as they are encoded, converted by the client when it is a
+// *graphql.Client, so every Store replays them the same way.
+func (q *Queue) Enqueue(op graphql.Operation) error {
+	if len(op.Files()) > 0 {
+		return errors.New("queue: operations with files cannot be enqueued")
+	}
+	_, mutation := op.(*graphql.Mutation)
+	req := op.Request()
+	item := Item{
+		Query:           req.Query(),
+		Header:          op.Headers().Clone(),
+		HeaderOverrides: req.HeaderOverrides(),
+		RemovedHeaders:  req.RemovedHeaders(),
+		DocumentIDKey:   req.DocumentIDKey(),
+		DocumentID:      req.DocumentID(),
+		Priority:        req.Priority(),
+		Mutation:        mutation,
+	}
+	vars := op.Vars()
+	if conv, ok := q.client.(varsConverter); ok {
+		vars = conv.ConvertVars(op)
+	}
+	var err error
+	if item.Variables, err = encodedVars(vars); err != nil {
+		return errors.Wrap(err, "queue: encoding variables")
+	}
+	if tags := req.Tags(); len(tags) > 0 {
+		item.Tags = make(map[string]string, len(tags))
+		for key, value := range tags {
+			item.Tags[key] = value
+		}
+	}
+	if _, err := q.store.Append(item); err != nil {
+		return errors.Wrap(err, "queue: storing operation")
+	}
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// encodedVars returns vars as decoded from their JSON encoding, numbers
+// as json.Number so they keep their precision.
+func encodedVars(vars map[string]interface{}) (map[string]interface{}, error) {
+	if vars == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(vars)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var encoded map[string]interface{}
+	if err := dec.Decode(&encoded); err != nil {
+		return nil, err
+	}
+	return encoded, nil
+}
+
+// Run delivers operations until ctx is done, which is the only error it
+// returns apart from store failures.
+func (q *Queue) Run(ctx context.Context) error {
+	for {
+		item, err := q.store.Peek()
+		if err != nil {
+			return errors.Wrap(err, "queue: reading operation")
+		}
+		if item == nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-q.notify:
+			}
+			continue
+		}
+
+		gerr := q.client.Run(graphql.WithAttempt(ctx, item.Attempts+1), item.Operation(), nil)
+		if gerr == nil {
+			if err := q.store.Remove(item.ID); err != nil {
+				return errors.Wrap(err, "queue: removing operation")
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		item.Attempts++
+		if !q.cfg.Retryable(gerr) || (q.cfg.MaxAttempts > 0 && item.Attempts >= q.cfg.MaxAttempts) {
+			if err := q.store.Remove(item.ID); err != nil {
+				return errors.Wrap(err, "queue: removing operation")
+			}
+			if q.cfg.OnFailure != nil {
+				q.cfg.OnFailure(*item, gerr)
+			}
+			continue
+		}
+		if err := q.store.Update(*item); err != nil {
+			return errors.Wrap(err, "queue: updating operation")
+		}
+
+		timer := time.NewTimer(q.backoff(item.Attempts))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (q *Queue) backoff(attempts int) time.Duration {
+	d := q.cfg.MinBackoff
+	for i := 1; i < attempts && d < q.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if q.cfg.MaxBackoff > 0 && d > q.cfg.MaxBackoff {
+		d = q.cfg.MaxBackoff
+	}
+	return d
+}
+
+// Retryable reports whether err is likely transient, see
+// graphql.IsTransient: transport failures such as network errors and
+// timeouts, rate limiting and server errors. Other execution errors, e.g.
+// variables that cannot be encoded or responses that cannot be decoded,
+// and GraphQL errors, since the server processed the request, are not
+// retried.
+func Retryable(err graphql.Error) bool {
+	return graphql.IsTransient(err)
+}
@@ -0,0 +1,247 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/sumup/graphql"
+)
+
+func TestQueue(t *testing.T) {
+	is := is.New(t)
+
+	var (
+		mu       sync.Mutex
+		received []string
+		calls    int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var body struct {
+			Variables struct{ N string }
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		is.Equal(r.Header.Get("Idempotency-Key"), body.Variables.N)
+		received = append(received, body.Variables.N)
+		_, _ = io.WriteString(w, `{"data":{"refund":{"successful":true}}}`)
+	}))
+	defer srv.Close()
+
+	store, err := NewFileStore(t.TempDir())
+	is.NoErr(err)
+	cfg := DefaultConfig()
+	cfg.MinBackoff = time.Millisecond
+	q := New(graphql.NewClient(srv.URL), store, cfg)
+
+	for _, n := range []string{"1", "2", "3"} {
+		m := graphql.NewMutation(`mutation ($n: ID!) { refund(id: $n) { successful } }`)
+		m.Var("n", n)
+		m.Header("Idempotency-Key", n)
+		is.NoErr(q.Enqueue(m))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- q.Run(ctx) }()
+	for {
+		item, err := store.Peek()
+		is.NoErr(err)
+		if item == nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	is.Equal(<-done, context.Canceled)
+
+	mu.Lock()
+	defer mu.Unlock()
+	is.Equal(received, []string{"1", "2", "3"})
+	is.Equal(calls, 4)
+}
+
+func TestQueueGivesUp(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"errors":[{"message":"invalid"}]}`)
+	}))
+	defer srv.Close()
+
+	failed := make(chan graphql.Error, 1)
+	cfg := DefaultConfig()
+	cfg.OnFailure = func(item Item, err graphql.Error) {
+		is.Equal(item.Attempts, 1)
+		failed <- err
+	}
+	store := NewMemoryStore()
+	q := New(graphql.NewClient(srv.URL), store, cfg)
+	is.NoErr(q.Enqueue(graphql.NewRequest("{ ok }")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = q.Run(ctx) }()
+
+	err := <-failed
+	is.Equal(err.Error(), "invalid")
+	item, perr := store.Peek()
+	is.NoErr(perr)
+	is.True(item == nil)
+}
+
+func TestFileStoreSurvivesReopen(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+
+	s, err := NewFileStore(dir)
+	is.NoErr(err)
+	first, err := s.Append(Item{Query: "{ a }"})
+	is.NoErr(err)
+	_, err = s.Append(Item{Query: "{ b }", Variables: map[string]interface{}{"x": "y"}})
+	is.NoErr(err)
+	first.Attempts = 2
+	is.NoErr(s.Update(first))
+
+	s, err = NewFileStore(dir)
+	is.NoErr(err)
+	item, err := s.Peek()
+	is.NoErr(err)
+	is.Equal(item.Query, "{ a }")
+	is.Equal(item.Attempts, 2)
+	is.NoErr(s.Remove(item.ID))
+
+	third, err := s.Append(Item{Query: "{ c }"})
+	is.NoErr(err)
+	is.Equal(third.ID, uint64(3))
+	item, err = s.Peek()
+	is.NoErr(err)
+	is.Equal(item.Variables, map[string]interface{}{"x": "y"})
+}
+
+func TestEnqueueKeepsOperation(t *testing.T) {
+	is := is.New(t)
+
+	store := NewMemoryStore()
+	q := New(graphql.NewClient("https://example.com/graphql"), store, DefaultConfig())
+	m := graphql.NewMutation(`mutation ($n: ID!) { refund(id: $n) { successful } }`)
+	m.Var("n", "1")
+	m.SetHeader("Content-Type", "application/json; charset=utf-8")
+	m.DelHeader("User-Agent")
+	m.Tag("kind", "refund")
+	m.Request().SetDocumentID(graphql.DocumentIDKey, "refund-v1")
+	m.Request().SetPriority(graphql.PriorityHigh)
+	is.NoErr(q.Enqueue(m))
+	// Later changes to the operation do not affect the queued one.
+	m.Var("n", "2")
+
+	item, err := store.Peek()
+	is.NoErr(err)
+	is.Equal(item.Variables, map[string]interface{}{"n": "1"})
+
+	req := item.Operation().Request()
+	is.Equal(req.HeaderOverrides().Get("Content-Type"), "application/json; charset=utf-8")
+	is.Equal(req.RemovedHeaders(), []string{"User-Agent"})
+	is.Equal(req.Tags(), map[string]string{"kind": "refund"})
+	is.Equal(req.DocumentID(), "refund-v1")
+	is.Equal(req.DocumentIDKey(), graphql.DocumentIDKey)
+	is.Equal(req.Priority(), graphql.PriorityHigh)
+}
+
+func TestRetryable(t *testing.T) {
+	is := is.New(t)
+
+	status := func(code int) graphql.Error {
+		return graphql.NewRequestError(&http.Response{StatusCode: code})
+	}
+	is.True(Retryable(graphql.NewExecutionError(&url.Error{Op: "Post", URL: "https://example.com", Err: io.ErrUnexpectedEOF})))
+	is.True(Retryable(graphql.NewExecutionError(context.DeadlineExceeded)))
+	is.True(Retryable(status(http.StatusBadGateway)))
+	is.True(!Retryable(graphql.NewExecutionError(errors.New("decoding response: invalid character"))))
+	is.True(!Retryable(status(http.StatusBadRequest)))
+}
+
+func TestQueueGivesUpByDefault(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	failed := make(chan Item, 1)
+	cfg := DefaultConfig()
+	cfg.MinBackoff, cfg.MaxBackoff = time.Millisecond, time.Millisecond
+	cfg.OnFailure = func(item Item, err graphql.Error) { failed <- item }
+	q := New(graphql.NewClient(srv.URL), NewMemoryStore(), cfg)
+	is.NoErr(q.Enqueue(graphql.NewRequest("{ ok }")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = q.Run(ctx) }()
+	is.Equal((<-failed).Attempts, 10)
+}
+
+func TestStoresReplayAlike(t *testing.T) {
+	is := is.New(t)
+
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct{ Variables json.RawMessage }
+		is.NoErr(json.NewDecoder(r.Body).Decode(&payload))
+		mu.Lock()
+		bodies = append(bodies, string(payload.Variables))
+		mu.Unlock()
+		_, _ = io.WriteString(w, `{"data":{"refund":{"successful":true}}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithMoneyFormat(graphql.MoneyMinorUnits))
+
+	dir := t.TempDir()
+	fileStore, err := NewFileStore(dir)
+	is.NoErr(err)
+	for _, store := range []Store{NewMemoryStore(), fileStore} {
+		m := graphql.NewMutation(`mutation ($id: ID!, $amount: Money!) { refund(id: $id, amount: $amount) { successful } }`)
+		m.Var("id", int64(1<<62+1))
+		m.Var("amount", graphql.Money{Amount: 1050, Currency: "EUR"})
+		is.NoErr(New(client, store, DefaultConfig()).Enqueue(m))
+		if store == Store(fileStore) {
+			// Replay after a restart.
+			store, err = NewFileStore(dir)
+			is.NoErr(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- New(client, store, DefaultConfig()).Run(ctx) }()
+		for {
+			item, err := store.Peek()
+			is.NoErr(err)
+			if item == nil {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		<-done
+	}
+	is.Equal(bodies, []string{`{"amount":1050,"id":4611686018427387905}`, `{"amount":1050,"id":4611686018427387905}`})
+}
@@ -0,0 +1,274 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/sumup/graphql"
+)
+
+type (
+	// Item is an operation waiting for delivery.
+	Item struct {
+		// ID is assigned by the store in the order items are appended.
+		ID        uint64                 `json:"id"`
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables,omitempty"`
+		Header    http.Header            `json:"header,omitempty"`
+		// HeaderOverrides and RemovedHeaders are the headers set with
+		// SetHeader and removed with DelHeader.
+		HeaderOverrides http.Header       `json:"headerOverrides,omitempty"`
+		RemovedHeaders  []string          `json:"removedHeaders,omitempty"`
+		Tags            map[string]string `json:"tags,omitempty"`
+		// DocumentID is sent under DocumentIDKey instead of the query
+		// when set.
+		DocumentIDKey string           `json:"documentIdKey,omitempty"`
+		DocumentID    string           `json:"documentId,omitempty"`
+		Priority      graphql.Priority `json:"priority,omitempty"`
+		Mutation      bool             `json:"mutation,omitempty"`
+		Attempts      int              `json:"attempts,omitempty"`
+	}
+
+	// Store persists the items of a Queue. Implementations must be safe
+	// for concurrent use.
+	Store interface {
+		// Append stores a new item, assigning its ID.
+		Append(Item) (Item, error)
+		// Peek gets the item with the lowest ID, or nil when the store
+		// is empty.
+		Peek() (*Item, error)
+		// Update replaces the stored item with the same ID.
+		Update(Item) error
+		// Remove deletes the item with the given ID.
+		Remove(id uint64) error
+	}
+
+	// MemoryStore keeps items in memory. They are lost when the process
+	// exits.
+	MemoryStore struct {
+		mu    sync.Mutex
+		next  uint64
+		items []Item
+	}
+
+	// FileStore keeps each item in a JSON file of a directory.
+	FileStore struct {
+		mu   sync.Mutex
+		dir  string
+		next uint64
+	}
+)
+
+// Operation rebuilds the operation of the item.
+func (i Item) Operation() graphql.Operation {
+	var op graphql.Operation
+	if i.Mutation {
+		op = graphql.NewMutation(i.Query)
+	} else {
+		op = graphql.NewRequest(i.Query)
+	}
+	for key, value := range i.Variables {
+		op.Var(key, value)
+	}
+	for key, values := range i.Header {
+		for _, value := range values {
+			op.Headers().Add(key, value)
+		}
+	}
+	req := op.Request()
+	for _, key := range i.RemovedHeaders {
+		req.DelHeader(key)
+	}
+	for key, values := range i.HeaderOverrides {
+		for _, value := range values {
+			req.SetHeader(key, value)
+		}
+	}
+	for key, value := range i.Tags {
+		req.Tag(key, value)
+	}
+	if i.DocumentID != "" {
+		req.SetDocumentID(i.DocumentIDKey, i.DocumentID)
+	}
+	req.SetPriority(i.Priority)
+	return op
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{next: 1}
+}
+
+func (s *MemoryStore) Append(item Item) (Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item.ID = s.next
+	s.next++
+	s.items = append(s.items, item)
+	return item, nil
+}
+
+func (s *MemoryStore) Peek() (*Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.items) == 0 {
+		return nil, nil
+	}
+	item := s.items[0]
+	return &item, nil
+}
+
+func (s *MemoryStore) Update(item Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == item.ID {
+			s.items[i] = item
+			return nil
+		}
+	}
+	return errors.Errorf("item %d not found", item.ID)
+}
+
+func (s *MemoryStore) Remove(id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// NewFileStore opens the store kept in dir, creating the directory when
+// it does not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	s := &FileStore{dir: dir, next: 1}
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		s.next = ids[len(ids)-1] + 1
+	}
+	return s, nil
+}
+
+func (s *FileStore) Append(item Item) (Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item.ID = s.next
+	if err := s.write(item); err != nil {
+		return Item{}, err
+	}
+	s.next++
+	return item, nil
+}
+
+func (s *FileStore) Peek() (*Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, err := s.ids()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(s.path(ids[0]))
+	if err != nil {
+		return nil, err
+	}
+	// Numbers are kept as written, so large integers keep their
+	// precision.
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var item Item
+	if err := dec.Decode(&item); err != nil {
+		return nil, errors.Wrapf(err, "decoding %s", s.path(ids[0]))
+	}
+	return &item, nil
+}
+
+func (s *FileStore) Update(item Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := os.Stat(s.path(item.ID)); err != nil {
+		return err
+	}
+	return s.write(item)
+}
+
+func (s *FileStore) Remove(id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) path(id uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d.json", id))
+}
+
+// write replaces the file of item atomically, so a crash never leaves a
+// partially written item behind.
+func (s *FileStore) write(item Item) error {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(s.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(item.ID))
+}
+
+// ids lists the IDs of the stored items in ascending order.
+func (s *FileStore) ids() ([]uint64, error) {
+	entries, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, ".json"), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
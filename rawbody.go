@@ -0,0 +1,45 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/pkg/errors"
+
+	"github.com/sumup/graphql/parser"
+)
+
+// MediaTypeGraphQL is the media type of bare GraphQL documents.
+const MediaTypeGraphQL = "application/graphql"
+
+// UseGraphQLBody posts the bare document with the application/graphql
+// content type, as some legacy servers require, passing the variables as
+// JSON in the variables parameter of the URL and the name of the
+// operation in the operationName one. Requests executed by
+// document ID, or with files when UseMultipartForm is set, are encoded as
+// usual.
+func UseGraphQLBody() ClientOption {
+	return func(client *Client) {
+		client.graphqlBody = true
+	}
+}
+
+// encodeGraphQL encodes req as a bare document and its variables as URL
+// parameters.
+func encodeGraphQL(req *Req) ([]byte, string, url.Values, Error) {
+	params := make(url.Values)
+	if name, err := parser.OperationName(req.q, ""); err == nil && name != "" {
+		params.Set("operationName", name)
+	}
+	if len(req.vars) > 0 {
+		vars, err := json.Marshal(req.vars)
+		if err != nil {
+			if verr := checkVars(req.vars); verr != nil {
+				return nil, "", nil, NewExecutionError(verr)
+			}
+			return nil, "", nil, NewExecutionError(errors.Wrap(err, "encode variables"))
+		}
+		params.Set("variables", string(vars))
+	}
+	return []byte(req.q), MediaTypeGraphQL + "; charset=utf-8", params, nil
+}
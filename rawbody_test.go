@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestUseGraphQLBody(t *testing.T) {
+	is := is.New(t)
+
+	var (
+		method, contentType, body string
+		query                     url.Values
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, contentType, query = r.Method, r.Header.Get("Content-Type"), r.URL.Query()
+		b, err := io.ReadAll(r.Body)
+		is.NoErr(err)
+		body = string(b)
+		_, _ = io.WriteString(w, `{"data":{"merchant":{"name":"Cafe"}}}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, UseGraphQLBody())
+	req := NewRequest("query ($id: ID!) { merchant(id: $id) { name } }")
+	req.Var("id", "m1")
+	var resp struct{ Merchant struct{ Name string } }
+	is.NoErr(client.Run(context.Background(), req, &resp))
+	is.Equal(resp.Merchant.Name, "Cafe")
+	is.Equal(method, http.MethodPost)
+	is.Equal(contentType, "application/graphql; charset=utf-8")
+	is.Equal(body, "query ($id: ID!) { merchant(id: $id) { name } }")
+	is.Equal(query, url.Values{"variables": {`{"id":"m1"}`}})
+
+	is.NoErr(client.Run(context.Background(), NewRequest("query Merchant { merchant { name } }"), nil))
+	is.Equal(query, url.Values{"operationName": {"Merchant"}})
+
+	// Queries may still be sent as GET requests.
+	client = NewClient(srv.URL, UseGraphQLBody(), UseGET())
+	is.NoErr(client.Run(context.Background(), NewRequest("{ merchant { name } }"), nil))
+	is.Equal(method, http.MethodGet)
+	is.NoErr(client.Run(context.Background(), NewMutation("mutation { close { successful } }"), nil))
+	is.Equal(method, http.MethodPost)
+	is.Equal(body, "mutation { close { successful } }")
+	is.Equal(len(query), 0)
+}
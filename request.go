@@ -1,12 +1,30 @@
 package graphql
 
 import (
+	"encoding/json"
 	"io"
 	"net/http"
+	"sort"
+)
+
+const (
+	// DocumentIDKey is the payload key used by most gateways for
+	// pre-registered documents.
+	DocumentIDKey = "documentId"
+	// RelayDocIDKey is the payload key used by Relay for pre-registered
+	// documents.
+	RelayDocIDKey = "doc_id"
+
+	// redactedValue replaces the value of redacted variables in dumps.
+	redactedValue = "[REDACTED]"
 )
 
 // Request is a GraphQL request.
 type (
+	// Operation is what Client runs. The settings added since, e.g.
+	// SetHeader, Tag or RedactVar, are methods of the *Req returned by
+	// Request rather than of Operation, so implementations outside this
+	// package keep satisfying it.
 	Operation interface {
 		Request() *Req
 		File(string, string, io.Reader)
@@ -29,6 +47,39 @@ type (
 		// Header represent any request headers that will be set
 		// when the request is made.
 		Header http.Header
+
+		// overrides replace any value the client would set for the
+		// same key, removals drop the key from the request entirely.
+		overrides http.Header
+		removals  map[string]struct{}
+
+		// redacted lists the variables hidden by String and MarshalJSON.
+		redacted map[string]struct{}
+
+		// documentID is sent under documentIDKey instead of the query
+		// text when set.
+		documentID    string
+		documentIDKey string
+
+		// tags is metadata for middleware, it is not sent to the server.
+		tags map[string]string
+
+		// timeFormat overrides the time format of the client when set.
+		timeFormat TimeFormat
+
+		// priority ranks the request for the concurrency limit.
+		priority Priority
+	}
+
+	// payload is the JSON body sent for an operation.
+	payload struct {
+		Query string `json:"query"`
+		// OperationName is only sent with WithOperationName.
+		OperationName string                 `json:"operationName,omitempty"`
+		Variables     map[string]interface{} `json:"variables"`
+
+		documentID    string
+		documentIDKey string
 	}
 
 	// File represents a file to upload.
@@ -71,6 +122,34 @@ func (r *Request) Headers() http.Header {
 	return r.Request().Header
 }
 
+func (r *Request) SetHeader(key, value string) {
+	r.Request().SetHeader(key, value)
+}
+
+func (r *Request) DelHeader(key string) {
+	r.Request().DelHeader(key)
+}
+
+func (r *Request) RedactVar(key string) {
+	r.Request().RedactVar(key)
+}
+
+func (r *Request) Tag(key, value string) {
+	r.Request().Tag(key, value)
+}
+
+func (r *Request) Tags() map[string]string {
+	return r.Request().Tags()
+}
+
+func (r *Request) String() string {
+	return r.Request().String()
+}
+
+func (r *Request) MarshalJSON() ([]byte, error) {
+	return r.Request().MarshalJSON()
+}
+
 func (r *Request) File(fieldname, filename string, reader io.Reader) {
 	r.Req.File(fieldname, filename, reader)
 }
@@ -99,6 +178,34 @@ func (m *Mutation) Headers() http.Header {
 	return m.Request().Header
 }
 
+func (m *Mutation) SetHeader(key, value string) {
+	m.Request().SetHeader(key, value)
+}
+
+func (m *Mutation) DelHeader(key string) {
+	m.Request().DelHeader(key)
+}
+
+func (m *Mutation) RedactVar(key string) {
+	m.Request().RedactVar(key)
+}
+
+func (m *Mutation) Tag(key, value string) {
+	m.Request().Tag(key, value)
+}
+
+func (m *Mutation) Tags() map[string]string {
+	return m.Request().Tags()
+}
+
+func (m *Mutation) String() string {
+	return m.Request().String()
+}
+
+func (m *Mutation) MarshalJSON() ([]byte, error) {
+	return m.Request().MarshalJSON()
+}
+
 func (m *Mutation) File(fieldname, filename string, reader io.Reader) {
 	m.Req.File(fieldname, filename, reader)
 }
@@ -116,6 +223,39 @@ func newReq(q string) *Req {
 	return req
 }
 
+// clone returns a deep copy of the request. Files are shared since their
+// readers can only be consumed once.
+func (req *Req) clone() *Req {
+	c := *req
+	c.Header = req.Header.Clone()
+	c.overrides = req.overrides.Clone()
+	if req.vars != nil {
+		c.vars = make(map[string]interface{}, len(req.vars))
+		for key, value := range req.vars {
+			c.vars[key] = value
+		}
+	}
+	if req.removals != nil {
+		c.removals = make(map[string]struct{}, len(req.removals))
+		for key := range req.removals {
+			c.removals[key] = struct{}{}
+		}
+	}
+	if req.redacted != nil {
+		c.redacted = make(map[string]struct{}, len(req.redacted))
+		for key := range req.redacted {
+			c.redacted[key] = struct{}{}
+		}
+	}
+	if req.tags != nil {
+		c.tags = make(map[string]string, len(req.tags))
+		for key, value := range req.tags {
+			c.tags[key] = value
+		}
+	}
+	return &c
+}
+
 // Var sets a variable.
 func (req *Req) Var(key string, value interface{}) {
 	if req.vars == nil {
@@ -129,6 +269,224 @@ func (req *Req) Vars() map[string]interface{} {
 	return req.vars
 }
 
+// SetHeader sets a header that replaces any value the client would
+// otherwise send for the same key, such as Content-Type or Accept.
+func (req *Req) SetHeader(key, value string) {
+	key = http.CanonicalHeaderKey(key)
+	if req.overrides == nil {
+		req.overrides = make(http.Header)
+	}
+	req.overrides.Set(key, value)
+	delete(req.removals, key)
+}
+
+// DelHeader removes a header from the request, including the ones
+// the client sets by default.
+func (req *Req) DelHeader(key string) {
+	key = http.CanonicalHeaderKey(key)
+	if req.removals == nil {
+		req.removals = make(map[string]struct{})
+	}
+	req.removals[key] = struct{}{}
+	req.Header.Del(key)
+	req.overrides.Del(key)
+}
+
+// singleValueHeaders are replaced rather than added to by the plain
+// headers of a request, since sending them twice is invalid.
+var singleValueHeaders = map[string]struct{}{
+	"Accept":              {},
+	"Authorization":       {},
+	"Content-Type":        {},
+	"Host":                {},
+	"Proxy-Authorization": {},
+	"User-Agent":          {},
+}
+
+// applyHeaders writes the request headers on top of h: plain headers
+// are added, overrides replace and removals delete existing values.
+// Keys are canonicalized, single-value headers such as Accept are
+// replaced and values already present are not repeated.
+func (req *Req) applyHeaders(h http.Header) {
+	for key, values := range req.Header {
+		key = http.CanonicalHeaderKey(key)
+		if _, single := singleValueHeaders[key]; single && len(values) > 0 {
+			h[key] = []string{values[len(values)-1]}
+			continue
+		}
+		for _, value := range values {
+			if !containsValue(h[key], value) {
+				h[key] = append(h[key], value)
+			}
+		}
+	}
+	for key, values := range req.overrides {
+		h[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+	for key := range req.removals {
+		h.Del(key)
+	}
+}
+
+// setsHeader reports whether the request sets values of key, canonical,
+// with Header or SetHeader.
+func (req *Req) setsHeader(key string) bool {
+	if len(req.overrides[key]) > 0 {
+		return true
+	}
+	for k, values := range req.Header {
+		if len(values) > 0 && http.CanonicalHeaderKey(k) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactVar hides the value of a variable when the request is dumped
+// with String or MarshalJSON, and in the logged variables of multipart
+// requests. The value is still sent to the server.
+func (req *Req) RedactVar(key string) {
+	if req.redacted == nil {
+		req.redacted = make(map[string]struct{})
+	}
+	req.redacted[key] = struct{}{}
+}
+
+// Tag attaches metadata such as the owning team or a priority to the
+// request. Tags are not sent to the server; middleware reads them from the
+// http.Request context with TagsFromContext.
+func (req *Req) Tag(key, value string) {
+	if req.tags == nil {
+		req.tags = make(map[string]string)
+	}
+	req.tags[key] = value
+}
+
+// Tags gets the tags of this request.
+func (req *Req) Tags() map[string]string {
+	return req.tags
+}
+
+// MarshalJSON returns the JSON payload that would be sent for this
+// request, with the values of redacted variables masked. The options of
+// the client, e.g. WithTimeFormat or WithOperationName, are not applied;
+// Client.Payload applies them.
+func (req *Req) MarshalJSON() ([]byte, error) {
+	p := req.payload()
+	p.Variables = req.redactedVars()
+	return json.Marshal(p)
+}
+
+// redactedVars returns the variables of the request with the values of
+// redacted ones masked.
+func (req *Req) redactedVars() map[string]interface{} {
+	if len(req.redacted) == 0 || req.vars == nil {
+		return req.vars
+	}
+	vars := make(map[string]interface{}, len(req.vars))
+	for key, value := range req.vars {
+		if _, ok := req.redacted[key]; ok {
+			value = redactedValue
+		}
+		vars[key] = value
+	}
+	return vars
+}
+
+// String returns the JSON payload of the request for debugging.
+func (req *Req) String() string {
+	b, err := req.MarshalJSON()
+	if err != nil {
+		return "graphql: " + err.Error()
+	}
+	return string(b)
+}
+
+func (req *Req) payload() payload {
+	return payload{
+		Query:         req.q,
+		Variables:     req.vars,
+		documentID:    req.documentID,
+		documentIDKey: req.documentIDKey,
+	}
+}
+
+// SetDocumentID executes the request by a document ID registered with
+// the server ahead of time instead of sending the query text. key is the
+// payload field the server reads the ID from, usually DocumentIDKey or
+// RelayDocIDKey.
+func (req *Req) SetDocumentID(key, id string) {
+	req.documentIDKey = key
+	req.documentID = id
+}
+
+// SetTimeFormat sets how the times of the variables of this request are
+// encoded, overriding WithTimeFormat.
+func (req *Req) SetTimeFormat(format TimeFormat) {
+	req.timeFormat = format
+}
+
+// SetPriority ranks the request among the calls waiting for a slot of
+// WithMaxConcurrency, PriorityNormal by default.
+func (req *Req) SetPriority(priority Priority) {
+	req.priority = priority
+}
+
+// Priority gets the priority set with SetPriority.
+func (req *Req) Priority() Priority {
+	return req.priority
+}
+
+// DocumentID gets the document ID set with SetDocumentID.
+func (req *Req) DocumentID() string {
+	return req.documentID
+}
+
+// DocumentIDKey gets the payload field set with SetDocumentID.
+func (req *Req) DocumentIDKey() string {
+	return req.documentIDKey
+}
+
+// HeaderOverrides gets a copy of the headers set with SetHeader.
+func (req *Req) HeaderOverrides() http.Header {
+	return req.overrides.Clone()
+}
+
+// RemovedHeaders gets the keys removed with DelHeader, sorted.
+func (req *Req) RemovedHeaders() []string {
+	if len(req.removals) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(req.removals))
+	for key := range req.removals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MarshalJSON encodes the payload, replacing the query with the document
+// ID when one is set.
+func (p payload) MarshalJSON() ([]byte, error) {
+	if p.documentID == "" {
+		type plain payload
+		return json.Marshal(plain(p))
+	}
+	return json.Marshal(map[string]interface{}{
+		p.documentIDKey: p.documentID,
+		"variables":     p.Variables,
+	})
+}
+
 // Files gets the files in this request.
 func (req *Req) Files() []File {
 	return req.files
@@ -0,0 +1,158 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRequestString(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("query ($id: ID!) { item(id: $id) { name } }")
+	req.Var("id", "123")
+
+	is.Equal(req.String(), `{"query":"query ($id: ID!) { item(id: $id) { name } }","variables":{"id":"123"}}`)
+}
+
+func TestRequestBodyOperationName(t *testing.T) {
+	is := is.New(t)
+
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		is.NoErr(err)
+		body = string(b)
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	req := NewRequest("query Item { item { name } }")
+	is.NoErr(NewClient(srv.URL).Run(context.Background(), req, nil))
+	is.Equal(body, req.String()+"\n")
+
+	client := NewClient(srv.URL, WithOperationName())
+	is.NoErr(client.Run(context.Background(), req, nil))
+	is.Equal(body, `{"query":"query Item { item { name } }","operationName":"Item","variables":null}`+"\n")
+	payload, err := client.Payload(req)
+	is.NoErr(err)
+	is.Equal(string(payload)+"\n", body)
+}
+
+func TestMultipartLogRedactsVars(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.FormValue("variables"), `{"card":"4111"}`+"\n")
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	var logged []string
+	client := NewClient(srv.URL, UseMultipartForm())
+	client.Log = func(s string) { logged = append(logged, s) }
+	req := NewRequest("mutation ($card: String!) { pay(card: $card) }")
+	req.Var("card", "4111")
+	req.RedactVar("card")
+	is.NoErr(client.Run(context.Background(), req, nil))
+	is.True(strings.Contains(strings.Join(logged, "\n"), `>> variables: {"card":"[REDACTED]"}`))
+	is.True(!strings.Contains(strings.Join(logged, "\n"), "4111"))
+}
+
+func TestClientPayload(t *testing.T) {
+	is := is.New(t)
+
+	client := NewClient("https://example.com/graphql", WithMoneyFormat(MoneyMinorUnits), WithOperationName())
+	mutation := NewMutation("mutation Charge($amount: Money!, $card: String!) { charge(amount: $amount, card: $card) { successful } }")
+	mutation.Var("amount", Money{Amount: 1050, Currency: "EUR"})
+	mutation.Var("card", "4111")
+	mutation.RedactVar("card")
+
+	b, err := client.Payload(mutation)
+	is.NoErr(err)
+	is.Equal(string(b), `{"query":"mutation Charge($amount: Money!, $card: String!) { charge(amount: $amount, card: $card) { successful } }","operationName":"Charge","variables":{"amount":1050,"card":"[REDACTED]"}}`)
+}
+
+func TestRequestMarshalJSONRedactsVars(t *testing.T) {
+	is := is.New(t)
+
+	mutation := NewMutation("mutation ($password: String!) { login(password: $password) }")
+	mutation.Var("password", "hunter2")
+	mutation.RedactVar("password")
+
+	b, err := json.Marshal(mutation)
+	is.NoErr(err)
+	is.Equal(string(b), `{"query":"mutation ($password: String!) { login(password: $password) }","variables":{"password":"[REDACTED]"}}`)
+	is.Equal(mutation.Vars()["password"], "hunter2") // the sent value is untouched
+}
+
+func TestRequestDocumentID(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("query Item($id: ID!) { item(id: $id) { name } }")
+	req.Var("id", "123")
+	req.Request().SetDocumentID(RelayDocIDKey, "c1a2b3")
+
+	is.Equal(req.Request().DocumentID(), "c1a2b3")
+	is.Equal(req.String(), `{"doc_id":"c1a2b3","variables":{"id":"123"}}`)
+}
+
+func TestApplyHeaders(t *testing.T) {
+	is := is.New(t)
+
+	h := http.Header{
+		"Accept":       {"application/json"},
+		"Content-Type": {"application/json"},
+		"X-Feature":    {"a"},
+	}
+	req := NewRequest("query {}")
+	req.Request().Header = http.Header{
+		"accept":    {"application/graphql-response+json"},
+		"x-feature": {"a", "b"},
+	}
+	req.Request().Header.Add("Content-Type", "application/json")
+	req.Request().applyHeaders(h)
+	is.Equal(h, http.Header{
+		"Accept":       {"application/graphql-response+json"},
+		"Content-Type": {"application/json"},
+		"X-Feature":    {"a", "b"},
+	})
+}
+
+// minimalOp implements Operation with only the methods it has always
+// required, as operations defined outside this package do.
+type minimalOp struct {
+	req *Req
+}
+
+func (o minimalOp) Request() *Req                        { return o.req }
+func (o minimalOp) File(field, name string, r io.Reader) { o.req.File(field, name, r) }
+func (o minimalOp) Files() []File                        { return o.req.files }
+func (o minimalOp) Var(key string, value interface{})    { o.req.Var(key, value) }
+func (o minimalOp) Vars() map[string]interface{}         { return o.req.Vars() }
+func (o minimalOp) Header(key, value string)             { o.req.Header.Set(key, value) }
+func (o minimalOp) Headers() http.Header                 { return o.req.Header }
+
+func TestMinimalOperation(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("Content-Type"), "application/json; charset=utf-8")
+		_, _ = io.WriteString(w, `{"data":{"ping":"pong"}}`)
+	}))
+	defer srv.Close()
+
+	op := minimalOp{req: newReq("{ ping }")}
+	// The rest of the request is reached through Request.
+	op.Request().Tag("caller", "test")
+	var resp struct{ Ping string }
+	is.NoErr(NewClient(srv.URL).Run(context.Background(), op, &resp))
+	is.Equal(resp.Ping, "pong")
+}
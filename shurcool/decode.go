@@ -0,0 +1,100 @@
+package shurcool
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+var null = []byte("null")
+
+// unmarshalGraphQL decodes the response data into v, matching object keys
+// against the graphql tags used to build the query.
+func unmarshalGraphQL(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.Errorf("shurcool: cannot decode into non-pointer %T", v)
+	}
+	return decode(data, rv.Elem())
+}
+
+func decode(data []byte, v reflect.Value) error {
+	if v.CanAddr() && v.Addr().Type().Implements(jsonUnmarshaler) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if bytes.Equal(bytes.TrimSpace(data), null) {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decode(data, v.Elem())
+	case reflect.Struct:
+		if bytes.Equal(bytes.TrimSpace(data), null) {
+			return nil
+		}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(data, &object); err != nil {
+			return err
+		}
+		return decodeStruct(object, v)
+	case reflect.Slice:
+		if bytes.Equal(bytes.TrimSpace(data), null) {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decode(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	default:
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+}
+
+func decodeStruct(object map[string]json.RawMessage, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		field := v.Field(i)
+
+		if isInlineFragment(f) {
+			if field.Kind() == reflect.Ptr {
+				if field.IsNil() {
+					field.Set(reflect.New(f.Type.Elem()))
+				}
+				field = field.Elem()
+			}
+			if err := decodeStruct(object, field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		raw, ok := object[fieldKey(f)]
+		if !ok {
+			continue
+		}
+		if err := decode(raw, field); err != nil {
+			return errors.Wrapf(err, "shurcool: decoding %s", f.Name)
+		}
+	}
+	return nil
+}
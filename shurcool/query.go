@@ -0,0 +1,175 @@
+package shurcool
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// builtinScalars maps unnamed Go kinds to the GraphQL scalar they are
+// declared as in variable definitions.
+var builtinScalars = map[reflect.Kind]string{
+	reflect.String:  "String",
+	reflect.Bool:    "Boolean",
+	reflect.Int:     "Int",
+	reflect.Int8:    "Int",
+	reflect.Int16:   "Int",
+	reflect.Int32:   "Int",
+	reflect.Int64:   "Int",
+	reflect.Uint:    "Int",
+	reflect.Uint8:   "Int",
+	reflect.Uint16:  "Int",
+	reflect.Uint32:  "Int",
+	reflect.Uint64:  "Int",
+	reflect.Float32: "Float",
+	reflect.Float64: "Float",
+}
+
+func constructQuery(v interface{}, variables map[string]interface{}) string {
+	query := query(v)
+	if len(variables) > 0 {
+		return "query(" + queryArguments(variables) + ")" + query
+	}
+	return query
+}
+
+func constructMutation(v interface{}, variables map[string]interface{}) string {
+	query := query(v)
+	if len(variables) > 0 {
+		return "mutation(" + queryArguments(variables) + ")" + query
+	}
+	return "mutation" + query
+}
+
+// queryArguments writes the variable definitions for variables, sorted
+// by name, e.g. "$a:Int!$b:[String!]".
+func queryArguments(variables map[string]interface{}) string {
+	keys := make([]string, 0, len(variables))
+	for key := range variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		buf.WriteString("$" + key + ":")
+		writeArgumentType(&buf, reflect.TypeOf(variables[key]), true)
+	}
+	return buf.String()
+}
+
+// writeArgumentType writes the GraphQL type of t. Pointers are nullable,
+// every other type is required.
+func writeArgumentType(buf *bytes.Buffer, t reflect.Type, required bool) {
+	if t == nil {
+		return
+	}
+	if t.Kind() == reflect.Ptr {
+		writeArgumentType(buf, t.Elem(), false)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		buf.WriteString("[")
+		writeArgumentType(buf, t.Elem(), true)
+		buf.WriteString("]")
+	default:
+		name := t.Name()
+		if scalar, ok := builtinScalars[t.Kind()]; ok && name == t.Kind().String() {
+			name = scalar
+		}
+		buf.WriteString(name)
+	}
+
+	if required {
+		buf.WriteString("!")
+	}
+}
+
+// query builds the selection set described by the struct v points to.
+func query(v interface{}) string {
+	var buf bytes.Buffer
+	writeQuery(&buf, reflect.TypeOf(v), false)
+	return buf.String()
+}
+
+func writeQuery(buf *bytes.Buffer, t reflect.Type, inline bool) {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		writeQuery(buf, t.Elem(), false)
+	case reflect.Struct:
+		// Types that decode themselves are scalars and have no selection.
+		if reflect.PtrTo(t).Implements(jsonUnmarshaler) {
+			return
+		}
+		if !inline {
+			buf.WriteString("{")
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if i != 0 {
+				buf.WriteString(",")
+			}
+			f := t.Field(i)
+			tag, ok := f.Tag.Lookup("graphql")
+			inlineField := f.Anonymous && !ok
+			if !inlineField {
+				if ok {
+					buf.WriteString(tag)
+				} else {
+					buf.WriteString(lowerCamelCase(f.Name))
+				}
+			}
+			writeQuery(buf, f.Type, inlineField)
+		}
+		if !inline {
+			buf.WriteString("}")
+		}
+	}
+}
+
+// lowerCamelCase lowers the leading upper case run of name, keeping the
+// last letter of an initialism upper case when a word follows it:
+// "ID" becomes "id" and "URLPath" becomes "urlPath".
+func lowerCamelCase(name string) string {
+	runes := []rune(name)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// fieldKey returns the response key of a struct field: the alias or
+// field name of its graphql tag, or its lower camel cased Go name.
+func fieldKey(f reflect.StructField) string {
+	tag, ok := f.Tag.Lookup("graphql")
+	if !ok {
+		return lowerCamelCase(f.Name)
+	}
+	tag = strings.TrimSpace(tag)
+	if i := strings.IndexAny(tag, "(@"); i >= 0 {
+		tag = tag[:i]
+	}
+	if i := strings.Index(tag, ":"); i >= 0 {
+		tag = tag[:i]
+	}
+	return strings.TrimSpace(tag)
+}
+
+// isInlineFragment reports whether the field describes an inline
+// fragment or an embedded struct sharing the parent's selection.
+func isInlineFragment(f reflect.StructField) bool {
+	tag, ok := f.Tag.Lookup("graphql")
+	if !ok {
+		return f.Anonymous
+	}
+	return strings.HasPrefix(strings.TrimSpace(tag), "...")
+}
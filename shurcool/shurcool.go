@@ -0,0 +1,62 @@
+// Package shurcool exposes a github.com/shurcooL/graphql style API on top
+// of graphql.Client, easing the migration of code that describes
+// operations with annotated structs.
+//
+//	var q struct {
+//	    User struct {
+//	        Name string
+//	    } `graphql:"user(id: $id)"`
+//	}
+//	client := shurcool.NewClient(graphql.NewClient(endpoint))
+//	err := client.Query(ctx, &q, map[string]interface{}{"id": "1"})
+package shurcool
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/sumup/graphql"
+)
+
+// Client executes struct based operations with a graphql.Client.
+type Client struct {
+	client graphql.GraphClient
+}
+
+// NewClient wraps client so it can be used with shurcooL style structs.
+func NewClient(client graphql.GraphClient) *Client {
+	return &Client{
+		client: client,
+	}
+}
+
+// Query executes a query described by q, which must be a pointer to a
+// struct, and decodes the response data into it.
+func (c *Client) Query(ctx context.Context, q interface{}, variables map[string]interface{}) error {
+	return c.do(ctx, constructQuery(q, variables), q, variables)
+}
+
+// Mutate executes a mutation described by m, which must be a pointer to
+// a struct, and decodes the response data into it.
+//
+// The mutation is sent as a plain request, it does not expect the
+// successful/messages/result payload convention of graphql.NewMutation.
+func (c *Client) Mutate(ctx context.Context, m interface{}, variables map[string]interface{}) error {
+	return c.do(ctx, constructMutation(m, variables), m, variables)
+}
+
+func (c *Client) do(ctx context.Context, query string, v interface{}, variables map[string]interface{}) error {
+	req := graphql.NewRequest(query)
+	for key, value := range variables {
+		req.Var(key, value)
+	}
+
+	var data json.RawMessage
+	if err := c.client.Run(ctx, req, &data); err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return unmarshalGraphQL(data, v)
+}
@@ -0,0 +1,98 @@
+package shurcool
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/sumup/graphql"
+)
+
+type Repository struct {
+	Name string
+}
+
+func TestConstructQuery(t *testing.T) {
+	is := is.New(t)
+
+	var q struct {
+		Viewer struct {
+			Login     string
+			AvatarURL string `graphql:"avatarUrl(size: $size)"`
+		}
+		Repo *Repository `graphql:"repo: repository(owner: $owner)"`
+	}
+	variables := map[string]interface{}{
+		"size":  1,
+		"owner": "sumup",
+		"tags":  []*string{},
+	}
+
+	is.Equal(
+		constructQuery(&q, variables),
+		`query($owner:String!$size:Int!$tags:[String]!){viewer{login,avatarUrl(size: $size)},repo: repository(owner: $owner){name}}`,
+	)
+	is.Equal(constructMutation(&q, nil), `mutation{viewer{login,avatarUrl(size: $size)},repo: repository(owner: $owner){name}}`)
+}
+
+func TestQuery(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		is.NoErr(err)
+		var body struct {
+			Query     string
+			Variables map[string]interface{}
+		}
+		is.NoErr(json.Unmarshal(b, &body))
+		is.Equal(body.Query, `query($id:String!){node(id: $id){id,... on Repository{name}},repo: node(id: $id){id}}`)
+		is.Equal(body.Variables["id"], "1")
+
+		_, _ = io.WriteString(w, `{"data":{"node":{"id":"1","name":"graphql"},"repo":null}}`)
+	}))
+	defer srv.Close()
+
+	var q struct {
+		Node struct {
+			ID         string
+			Repository `graphql:"... on Repository"`
+		} `graphql:"node(id: $id)"`
+		Repo *struct {
+			ID string
+		} `graphql:"repo: node(id: $id)"`
+	}
+
+	client := NewClient(graphql.NewClient(srv.URL))
+	err := client.Query(context.Background(), &q, map[string]interface{}{"id": "1"})
+	is.NoErr(err)
+	is.Equal(q.Node.ID, "1")
+	is.Equal(q.Node.Name, "graphql")
+	is.True(q.Repo == nil)
+}
+
+func TestMutateError(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"errors":[{"message":"not allowed"}]}`)
+	}))
+	defer srv.Close()
+
+	var m struct {
+		AddStar struct {
+			Starred bool
+		} `graphql:"addStar(id: $id)"`
+	}
+
+	client := NewClient(graphql.NewClient(srv.URL))
+	err := client.Mutate(context.Background(), &m, map[string]interface{}{"id": "1"})
+	is.True(err != nil)
+	is.Equal(err.Error(), "not allowed")
+}
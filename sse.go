@@ -0,0 +1,445 @@
+package graphql
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MediaTypeEventStream is the media type of Server-Sent Events streams.
+const MediaTypeEventStream = "text/event-stream"
+
+// SubscriptionEvent is a result delivered by Subscribe: a response, or
+// the errors the server reported for it.
+type SubscriptionEvent struct {
+	// Resp is a new value of the type of the resp passed to Subscribe.
+	Resp interface{}
+	Err  Error
+}
+
+// ErrSubscriptionOverflow is the cause of the *ExecutionError ending a
+// subscription whose buffer is full with OverflowError.
+var ErrSubscriptionOverflow = errors.New("subscription buffer full")
+
+// OverflowPolicy decides what happens to the events of a subscription
+// whose buffer is full, see SubscribeBuffer.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock stops reading the stream until the consumer receives
+	// an event, so the server or the connection buffers the rest. It is
+	// the default.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered event to make room
+	// for the new one.
+	OverflowDropOldest
+	// OverflowError ends the subscription with ErrSubscriptionOverflow,
+	// delivered after the buffered events.
+	OverflowError
+)
+
+// SubscribeOption configures a single subscription started by Subscribe.
+type SubscribeOption func(*subscription)
+
+// SubscribeBuffer buffers up to size events that the consumer has not
+// received yet, and sets what happens to the events arriving when the
+// buffer is full. Without it, events are unbuffered and OverflowBlock
+// applies.
+func SubscribeBuffer(size int, policy OverflowPolicy) SubscribeOption {
+	return func(s *subscription) {
+		s.buffer = size
+		if size < 0 {
+			s.buffer = 0
+		}
+		s.overflow = policy
+	}
+}
+
+// SubscribeReconnect reconnects the subscription when its connection
+// fails or its stream ends before the server completes it, and posts the
+// operation again. The first attempt waits minBackoff, and the delay
+// doubles after each failed attempt up to maxBackoff. The subscription
+// ends with the error of the last attempt after maxAttempts consecutive
+// failed attempts, when positive, or at once when the error is terminal
+// rather than transient, see IsTransient: the server rejected the
+// operation, e.g. with a 401 status or GraphQL errors, and would reject it
+// again.
+func SubscribeReconnect(minBackoff, maxBackoff time.Duration, maxAttempts int) SubscribeOption {
+	return func(s *subscription) {
+		s.reconnect = true
+		s.minBackoff = minBackoff
+		s.maxBackoff = maxBackoff
+		s.maxAttempts = maxAttempts
+	}
+}
+
+// SubscribeCursor sets the variable name of the operation to
+// cursor(resp) when reconnecting, resp being the last response
+// delivered, so the server resumes the stream after it rather than
+// missing the events in between. It only applies with
+// SubscribeReconnect; the variable is set on the operation passed to
+// Subscribe.
+func SubscribeCursor(name string, cursor func(resp interface{}) interface{}) SubscribeOption {
+	return func(s *subscription) {
+		s.cursorVar = name
+		s.cursor = cursor
+	}
+}
+
+// SubscriptionHooks are called on the lifecycle events of a
+// subscription, so services can report on the health of their streams.
+// Nil hooks are skipped. They are called from the goroutine delivering
+// the events, so they hold up the subscription until they return.
+type SubscriptionHooks struct {
+	// Connected is called when the server accepts the operation and
+	// starts streaming its events, again after each reconnection. Over
+	// SSE, connecting and subscribing are the same request.
+	Connected func(op Operation)
+	// Event is called with each event before it is delivered.
+	Event func(op Operation, event SubscriptionEvent)
+	// Reconnecting is called before each reconnection attempt of
+	// SubscribeReconnect, with the error ending the previous connection
+	// or failing the previous attempt; nil when the stream ended cleanly.
+	Reconnecting func(op Operation, attempt int, err Error)
+	// Closed is called when the subscription ends, right before the
+	// channel is closed, with the error ending it, if any.
+	Closed func(op Operation, err Error)
+}
+
+// SubscribeHooks sets the hooks called on the lifecycle events of the
+// subscription. Errors of the request starting it are returned by
+// Subscribe and call no hook.
+func SubscribeHooks(hooks SubscriptionHooks) SubscribeOption {
+	return func(s *subscription) {
+		s.hooks = hooks
+	}
+}
+
+// subscription is the state of a subscription started by Subscribe.
+type subscription struct {
+	client *Client
+	ctx    context.Context
+	op     Operation
+	t      reflect.Type
+	events chan SubscriptionEvent
+	// last is the last response delivered.
+	last interface{}
+	// err is the error ending the subscription.
+	err Error
+
+	buffer   int
+	overflow OverflowPolicy
+
+	reconnect   bool
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	maxAttempts int
+	cursorVar   string
+	cursor      func(resp interface{}) interface{}
+
+	hooks SubscriptionHooks
+}
+
+// acceptKey overrides the Accept header of the requests of a context.
+type acceptKey struct{}
+
+// Subscribe starts op, a subscription, over Server-Sent Events as the
+// GraphQL over SSE protocol (graphql-sse) does in its distinct
+// connections mode: the operation is posted and the server streams a
+// "next" event per result until a "complete" event. resp is a pointer
+// showing the type to decode into; each event holds a new one.
+//
+// Live queries, queries with the @live directive as GraphQL Yoga serves
+// them, are started with Subscribe too: the server streams the whole
+// result again whenever it changes. Results sent as JSON patches are not
+// supported.
+//
+// The channel is closed when the server completes the subscription, the
+// stream ends or ctx is done, which is how a subscription is stopped;
+// with SubscribeReconnect, a stream ending early is resumed instead.
+// The timeout of the client does not apply. Errors of the request itself
+// are returned at once. Errors, of the request and of the events, are
+// counted in Stats and passed to WithErrorTranslator as the ones of Run.
+// IsTransient tells whether an error ending the subscription is worth
+// subscribing again for.
+func (c *Client) Subscribe(ctx context.Context, op Operation, resp interface{}, opts ...SubscribeOption) (<-chan SubscriptionEvent, Error) {
+	events, err := c.subscribe(ctx, op, resp, opts)
+	c.countError(err)
+	return events, c.translate(err)
+}
+
+func (c *Client) subscribe(ctx context.Context, op Operation, resp interface{}, opts []SubscribeOption) (<-chan SubscriptionEvent, Error) {
+	t := reflect.TypeOf(resp)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil, NewExecutionError(errors.Errorf("resp must be a pointer, got %T", resp))
+	}
+	s := &subscription{client: c, ctx: ctx, op: op, t: t}
+	for _, opt := range opts {
+		opt(s)
+	}
+	res, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	s.events = make(chan SubscriptionEvent, s.buffer)
+	go s.run(res)
+	return s.events, nil
+}
+
+// connect posts the operation, accepting an event stream.
+func (s *subscription) connect() (*http.Response, Error) {
+	c := s.client
+	res, err := c.send(context.WithValue(s.ctx, acceptKey{}, MediaTypeEventStream+", "+MediaTypeJSON), s.op)
+	if err != nil {
+		return nil, err
+	}
+	if !successful(res) {
+		return nil, c.decodeErrorResponse(s.ctx, s.op, res, nil)
+	}
+	return res, nil
+}
+
+// run delivers the events of res, then of the connections replacing it,
+// until the subscription ends.
+func (s *subscription) run(res *http.Response) {
+	defer func() {
+		if s.hooks.Closed != nil {
+			s.hooks.Closed(s.op, s.err)
+		}
+		close(s.events)
+	}()
+	s.connected()
+	for {
+		done, err := s.stream(res)
+		if done || s.ctx.Err() != nil {
+			return
+		}
+		if !s.reconnect {
+			s.fail(err)
+			return
+		}
+		for attempt := 1; ; attempt++ {
+			if (err != nil && !IsTransient(err)) || (s.maxAttempts > 0 && attempt > s.maxAttempts) {
+				s.fail(err)
+				return
+			}
+			if s.hooks.Reconnecting != nil {
+				s.hooks.Reconnecting(s.op, attempt, err)
+			}
+			if !s.wait(attempt) {
+				return
+			}
+			if s.cursor != nil && s.last != nil {
+				s.op.Var(s.cursorVar, s.cursor(s.last))
+			}
+			if res, err = s.connect(); err == nil {
+				s.connected()
+				break
+			}
+		}
+	}
+}
+
+func (s *subscription) connected() {
+	if s.hooks.Connected != nil {
+		s.hooks.Connected(s.op)
+	}
+}
+
+// fail ends the subscription with err, delivering it unless nil.
+func (s *subscription) fail(err Error) {
+	if err != nil {
+		s.err = err
+		s.deliver(SubscriptionEvent{Err: err})
+	}
+}
+
+// wait waits the backoff before the reconnection attempt, reporting
+// false when ctx is done first.
+func (s *subscription) wait(attempt int) bool {
+	d := s.minBackoff
+	for i := 1; i < attempt && d < s.maxBackoff; i++ {
+		d *= 2
+	}
+	if s.maxBackoff > 0 && d > s.maxBackoff {
+		d = s.maxBackoff
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// stream delivers the events of res. It reports done when the
+// subscription ends: the server completed it, answered with a single
+// result, or the events are no longer received. Otherwise the stream
+// ended early, with err unless it was closed cleanly.
+func (s *subscription) stream(res *http.Response) (done bool, err Error) {
+	c := s.client
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType != MediaTypeEventStream {
+		// The server answered with a single result, usually errors.
+		body, err := c.readBody(s.ctx, res)
+		if err != nil {
+			return false, err
+		}
+		s.deliverEvent(res, body)
+		return true, nil
+	}
+
+	defer res.Body.Close()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-s.ctx.Done():
+			res.Body.Close()
+		case <-stop:
+		}
+	}()
+	stream := bufio.NewReader(res.Body)
+	for {
+		event, data, err := readEvent(stream)
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return true, nil
+			}
+			if err == io.EOF {
+				return false, nil
+			}
+			return false, NewExecutionError(errors.Wrap(err, "reading event stream"))
+		}
+		switch event {
+		case "next", "", "message":
+			// Servers predating graphql-sse, e.g. GraphQL Yoga, send
+			// results as unnamed events.
+			if !s.deliverEvent(res, data) {
+				return true, nil
+			}
+		case "complete":
+			return true, nil
+		}
+	}
+}
+
+// deliverEvent decodes the result data into a new value of the type of
+// resp and delivers it, reporting whether the subscription goes on.
+func (s *subscription) deliverEvent(res *http.Response, data []byte) bool {
+	if s.client.logs(LogBody) {
+		s.client.logf(LogBody, []LogField{{"body", string(data)}}, "<< %s", data)
+	}
+	event := SubscriptionEvent{Resp: reflect.New(s.t.Elem()).Interface()}
+	gr := &graphResponse{Data: event.Resp}
+	if err := json.Unmarshal(data, gr); err != nil {
+		event.Err = NewExecutionError(errors.Wrap(err, "decoding response"))
+	} else if len(gr.Errors) > 0 {
+		event.Err = NewGraphQLError(gr.Errors, res)
+	} else {
+		s.last = event.Resp
+	}
+	return s.deliver(event)
+}
+
+// deliver delivers event, its error counted and translated, applying the
+// overflow policy when the buffer is full. It reports whether the
+// subscription goes on.
+func (s *subscription) deliver(event SubscriptionEvent) bool {
+	if event.Err != nil {
+		s.client.countError(event.Err)
+		event.Err = s.client.translate(event.Err)
+	}
+	if s.hooks.Event != nil {
+		s.hooks.Event(s.op, event)
+	}
+	if s.buffer > 0 {
+		switch s.overflow {
+		case OverflowDropOldest:
+			for {
+				select {
+				case s.events <- event:
+					return true
+				default:
+				}
+				select {
+				case <-s.events:
+				default:
+				}
+			}
+		case OverflowError:
+			select {
+			case s.events <- event:
+				return true
+			default:
+			}
+			s.err = NewExecutionError(ErrSubscriptionOverflow)
+			s.client.countError(s.err)
+			event = SubscriptionEvent{Err: s.client.translate(s.err)}
+			if s.hooks.Event != nil {
+				s.hooks.Event(s.op, event)
+			}
+			s.send(event)
+			return false
+		}
+	}
+	return s.send(event)
+}
+
+// send blocks until event is received or ctx is done, reporting whether
+// it was received.
+func (s *subscription) send(event SubscriptionEvent) bool {
+	select {
+	case s.events <- event:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// readEvent reads the next event of an SSE stream, skipping comments and
+// fields other than event and data. Data lines are joined with newlines.
+func readEvent(r *bufio.Reader) (event string, data []byte, err error) {
+	var (
+		buf     bytes.Buffer
+		hasData bool
+	)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if event != "" || hasData {
+				return event, buf.Bytes(), nil
+			}
+			continue
+		}
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			if hasData {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString(value)
+			hasData = true
+		}
+	}
+}
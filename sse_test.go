@@ -0,0 +1,318 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+)
+
+func TestSubscribe(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Header.Get("Accept"), "text/event-stream, application/json")
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "event: next\ndata: {\"data\":{\"transaction\":{\"amount\":1}}}\n\n")
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, "event: next\r\ndata: {\"data\":null,\r\ndata: \"errors\":[{\"message\":\"denied\"}]}\r\n\r\n")
+		fmt.Fprint(w, "event: next\ndata: {\"data\":{\"transaction\":{\"amount\":2}}}\n\n")
+		fmt.Fprint(w, "event: complete\ndata:\n\n")
+		fmt.Fprint(w, "event: next\ndata: {\"data\":{\"transaction\":{\"amount\":3}}}\n\n")
+	}))
+	defer srv.Close()
+
+	type transaction struct {
+		Transaction struct{ Amount int }
+	}
+	events, err := NewClient(srv.URL).Subscribe(context.Background(), NewRequest("subscription { transaction { amount } }"), &transaction{})
+	is.NoErr(err)
+
+	var got []string
+	for event := range events {
+		if event.Err != nil {
+			got = append(got, event.Err.Error())
+			continue
+		}
+		got = append(got, fmt.Sprint(event.Resp.(*transaction).Transaction.Amount))
+	}
+	is.Equal(got, []string{"1", "denied", "2"})
+}
+
+func TestSubscribeSingleResult(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"errors":[{"message":"unknown field"}]}`)
+	}))
+	defer srv.Close()
+
+	events, err := NewClient(srv.URL).Subscribe(context.Background(), NewRequest("subscription { nope }"), &struct{}{})
+	is.NoErr(err)
+	event, ok := <-events
+	is.True(ok)
+	is.Equal(event.Err.Error(), "unknown field")
+	_, ok = <-events
+	is.True(!ok)
+}
+
+func TestSubscribeCancel(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: next\ndata: {\"data\":{}}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := NewClient(srv.URL).Subscribe(ctx, NewRequest("subscription { tick }"), &struct{}{})
+	is.NoErr(err)
+	<-events
+	cancel()
+	for range events {
+	}
+}
+
+func TestSubscribeErrorPath(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Fail") != "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: next\ndata: {\"errors\":[{\"message\":\"denied\"}]}\n\n")
+	}))
+	defer srv.Close()
+
+	var translated int
+	client := NewClient(srv.URL, WithErrorTranslator(func(err Error) Error {
+		translated++
+		return nil
+	}))
+	req := NewRequest("subscription { transaction { amount } }")
+	req.Header("X-Fail", "1")
+	_, err := client.Subscribe(context.Background(), req, &struct{}{})
+	is.True(err != nil)
+
+	events, err := client.Subscribe(context.Background(), NewRequest("subscription { transaction { amount } }"), &struct{}{})
+	is.NoErr(err)
+	for event := range events {
+		is.Equal(event.Err.Error(), "denied")
+	}
+	// The errors of the request and of the events are translated and
+	// counted as the ones of Run.
+	is.Equal(translated, 2)
+	is.Equal(client.Stats().Errors, map[string]int64{"request": 1, "graphql": 1})
+}
+
+func TestSubscribeBuffer(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 1; i <= 4; i++ {
+			fmt.Fprintf(w, "event: next\ndata: {\"data\":{\"tick\":%d}}\n\n", i)
+		}
+		fmt.Fprint(w, "event: complete\ndata:\n\n")
+	}))
+	defer srv.Close()
+
+	type tick struct{ Tick int }
+	receive := func(policy OverflowPolicy) []string {
+		events, err := NewClient(srv.URL).Subscribe(context.Background(), NewRequest("subscription { tick }"), &tick{}, SubscribeBuffer(2, policy))
+		is.NoErr(err)
+		// A slow consumer lets the buffer fill up.
+		time.Sleep(50 * time.Millisecond)
+		var got []string
+		for event := range events {
+			if event.Err != nil {
+				is.True(errors.Is(event.Err, ErrSubscriptionOverflow))
+				got = append(got, event.Err.Error())
+				continue
+			}
+			got = append(got, fmt.Sprint(event.Resp.(*tick).Tick))
+		}
+		return got
+	}
+	is.Equal(receive(OverflowBlock), []string{"1", "2", "3", "4"})
+	is.Equal(receive(OverflowDropOldest), []string{"3", "4"})
+	is.Equal(receive(OverflowError), []string{"1", "2", "subscription buffer full"})
+}
+
+func TestSubscribeReconnect(t *testing.T) {
+	is := is.New(t)
+
+	var connections int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]interface{}
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch atomic.AddInt32(&connections, 1) {
+		case 1:
+			is.Equal(body.Variables["after"], nil)
+			// The stream drops without completing.
+			fmt.Fprint(w, "event: next\ndata: {\"data\":{\"tick\":1}}\n\n")
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			is.Equal(body.Variables["after"], float64(1))
+			fmt.Fprint(w, "event: next\ndata: {\"data\":{\"tick\":2}}\n\n")
+			fmt.Fprint(w, "event: complete\ndata:\n\n")
+		}
+	}))
+	defer srv.Close()
+
+	type tick struct{ Tick int }
+	events, err := NewClient(srv.URL).Subscribe(context.Background(), NewRequest("subscription($after: Int) { tick(after: $after) }"), &tick{},
+		SubscribeReconnect(time.Millisecond, 4*time.Millisecond, 0),
+		SubscribeCursor("after", func(resp interface{}) interface{} {
+			return resp.(*tick).Tick
+		}))
+	is.NoErr(err)
+	var got []int
+	for event := range events {
+		is.NoErr(event.Err)
+		got = append(got, event.Resp.(*tick).Tick)
+	}
+	is.Equal(got, []int{1, 2})
+	is.Equal(atomic.LoadInt32(&connections), int32(3))
+}
+
+func TestSubscribeReconnectGivesUp(t *testing.T) {
+	is := is.New(t)
+
+	var connections int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&connections, 1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: next\ndata: {\"data\":{}}\n\n")
+	}))
+	defer srv.Close()
+
+	events, err := NewClient(srv.URL).Subscribe(context.Background(), NewRequest("subscription { tick }"), &struct{}{},
+		SubscribeReconnect(time.Millisecond, time.Millisecond, 2))
+	is.NoErr(err)
+	event := <-events
+	is.NoErr(event.Err)
+	event = <-events
+	is.True(event.Err != nil)
+	is.Equal(event.Err.Response().StatusCode, http.StatusServiceUnavailable)
+	_, ok := <-events
+	is.True(!ok)
+	is.Equal(atomic.LoadInt32(&connections), int32(3))
+}
+
+func TestSubscribeHooks(t *testing.T) {
+	is := is.New(t)
+
+	var connections int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&connections, 1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: next\ndata: {\"data\":{}}\n\n")
+	}))
+	defer srv.Close()
+
+	var got []string
+	hooks := SubscriptionHooks{
+		Connected: func(op Operation) {
+			got = append(got, "connected")
+		},
+		Event: func(op Operation, event SubscriptionEvent) {
+			got = append(got, fmt.Sprintf("event %v", event.Err))
+		},
+		Reconnecting: func(op Operation, attempt int, err Error) {
+			got = append(got, fmt.Sprintf("reconnecting %d %v", attempt, err))
+		},
+		Closed: func(op Operation, err Error) {
+			got = append(got, fmt.Sprintf("closed %v", err))
+		},
+	}
+	events, err := NewClient(srv.URL).Subscribe(context.Background(), NewRequest("subscription { tick }"), &struct{}{},
+		SubscribeReconnect(time.Millisecond, time.Millisecond, 1), SubscribeHooks(hooks))
+	is.NoErr(err)
+	for range events {
+	}
+	is.Equal(got, []string{
+		"connected",
+		"event <nil>",
+		"reconnecting 1 <nil>",
+		"event request failed with status: 503 Service Unavailable",
+		"closed request failed with status: 503 Service Unavailable",
+	})
+}
+
+func TestSubscribeReconnectTerminal(t *testing.T) {
+	is := is.New(t)
+
+	var connections int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&connections, 1) > 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: next\ndata: {\"data\":{}}\n\n")
+	}))
+	defer srv.Close()
+
+	events, err := NewClient(srv.URL).Subscribe(context.Background(), NewRequest("subscription { tick }"), &struct{}{},
+		SubscribeReconnect(time.Millisecond, time.Millisecond, 0))
+	is.NoErr(err)
+	<-events
+	event := <-events
+	// The server rejects the operation, so it is not attempted again.
+	is.True(IsUnauthenticated(event.Err))
+	is.True(!IsTransient(event.Err))
+	_, ok := <-events
+	is.True(!ok)
+	is.Equal(atomic.LoadInt32(&connections), int32(2))
+}
+
+func TestSubscribeLiveQuery(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, http.MethodGet)
+		is.Equal(r.URL.Query().Get("query"), "query @live { balance }")
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ":\n\n")
+		fmt.Fprint(w, "data: {\"data\":{\"balance\":10}}\n\n")
+		fmt.Fprint(w, "data: {\"data\":{\"balance\":7}}\n\n")
+		fmt.Fprint(w, "event: complete\n\n")
+	}))
+	defer srv.Close()
+
+	type balance struct{ Balance int }
+	events, err := NewClient(srv.URL, UseGET()).Subscribe(context.Background(), NewRequest("query @live { balance }"), &balance{})
+	is.NoErr(err)
+	var got []int
+	for event := range events {
+		is.NoErr(event.Err)
+		got = append(got, event.Resp.(*balance).Balance)
+	}
+	is.Equal(got, []int{10, 7})
+}
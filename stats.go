@@ -0,0 +1,117 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Stats is a snapshot of the counters of a client since it was created,
+// e.g. for debug endpoints.
+type Stats struct {
+	// Requests is the number of HTTP requests sent.
+	Requests int64 `json:"requests"`
+	// Errors counts the failed calls by class of error: "request",
+	// "execution" or "graphql".
+	Errors map[string]int64 `json:"errors"`
+	// ErrorCodes counts the failed calls by the code of their error.
+	ErrorCodes map[string]int64 `json:"errorCodes"`
+	// BytesSent and BytesReceived are the sizes of the request bodies and
+	// of the response bodies read.
+	BytesSent     int64 `json:"bytesSent"`
+	BytesReceived int64 `json:"bytesReceived"`
+	// CacheHits counts the responses served by a shared HTTP cache, as
+	// reported by an Age header or an X-Cache header starting with HIT.
+	CacheHits int64 `json:"cacheHits"`
+	// Retries counts the calls made again after a failure, as reported by
+	// WithAttempt.
+	Retries int64 `json:"retries"`
+}
+
+// stats holds the counters of a client.
+type stats struct {
+	mu   sync.Mutex
+	snap Stats
+}
+
+// Stats returns a snapshot of the counters of the client.
+func (c *Client) Stats() Stats {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	snap := c.stats.snap
+	snap.Errors = make(map[string]int64, len(c.stats.snap.Errors))
+	for class, n := range c.stats.snap.Errors {
+		snap.Errors[class] = n
+	}
+	snap.ErrorCodes = make(map[string]int64, len(c.stats.snap.ErrorCodes))
+	for code, n := range c.stats.snap.ErrorCodes {
+		snap.ErrorCodes[code] = n
+	}
+	return snap
+}
+
+func (c *Client) count(update func(*Stats)) {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	update(&c.stats.snap)
+}
+
+// countRequest counts a request of sent bytes and, when it is a retry,
+// the retry.
+func (c *Client) countRequest(ctx context.Context, sent int) {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	c.count(func(s *Stats) {
+		s.Requests++
+		s.BytesSent += int64(sent)
+		if attempt > 1 {
+			s.Retries++
+		}
+	})
+}
+
+// countResponse counts res when a cache served it.
+func (c *Client) countResponse(res *http.Response) {
+	if res.Header.Get("Age") == "" && !strings.HasPrefix(strings.ToUpper(res.Header.Get("X-Cache")), "HIT") {
+		return
+	}
+	c.count(func(s *Stats) { s.CacheHits++ })
+}
+
+// countReceived counts the bytes of a response body.
+func (c *Client) countReceived(received int) {
+	c.count(func(s *Stats) { s.BytesReceived += int64(received) })
+}
+
+// countError counts err, if any, by class and code.
+func (c *Client) countError(err Error) {
+	if err == nil {
+		return
+	}
+	class := "graphql"
+	switch err.(type) {
+	case *RequestError:
+		class = "request"
+	case *ExecutionError:
+		class = "execution"
+	}
+	code := err.Code()
+	c.count(func(s *Stats) {
+		if s.Errors == nil {
+			s.Errors = make(map[string]int64)
+			s.ErrorCodes = make(map[string]int64)
+		}
+		s.Errors[class]++
+		if code != "" {
+			s.ErrorCodes[code]++
+		}
+	})
+}
+
+type attemptKey struct{}
+
+// WithAttempt marks the calls made with ctx as the given attempt of an
+// operation, counting those past the first as retries in Stats.
+func WithAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
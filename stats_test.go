@@ -0,0 +1,53 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestStats(t *testing.T) {
+	is := is.New(t)
+
+	responses := []func(w http.ResponseWriter){
+		func(w http.ResponseWriter) {
+			_, _ = io.WriteString(w, `{"data":{}}`)
+		},
+		func(w http.ResponseWriter) {
+			w.Header().Set("X-Cache", "Hit from cloudfront")
+			_, _ = io.WriteString(w, `{"errors":[{"message":"missing","extensions":{"code":"NOT_FOUND"}}]}`)
+		},
+		func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		},
+	}
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responses[calls](w)
+		calls++
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	ctx := context.Background()
+	req := NewRequest("query {}")
+	is.NoErr(client.Run(ctx, req, nil))
+	is.True(client.Run(ctx, req, nil) != nil)
+	is.True(client.Run(WithAttempt(ctx, 2), req, nil) != nil)
+
+	stats := client.Stats()
+	is.Equal(stats.Requests, int64(3))
+	is.Equal(stats.Retries, int64(1))
+	is.Equal(stats.CacheHits, int64(1))
+	is.Equal(stats.Errors, map[string]int64{"graphql": 1, "request": 1})
+	is.Equal(stats.ErrorCodes, map[string]int64{"not_found": 1, "Service Unavailable": 1})
+	is.Equal(stats.BytesSent, int64(3*len(`{"query":"query {}","variables":null}`+"\n")))
+	is.Equal(stats.BytesReceived, int64(len(`{"data":{}}`)+len(`{"errors":[{"message":"missing","extensions":{"code":"NOT_FOUND"}}]}`)))
+
+	stats.Errors["graphql"] = 10
+	is.Equal(client.Stats().Errors["graphql"], int64(1)) // snapshots are copies
+}
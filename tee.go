@@ -0,0 +1,55 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// DefaultTeeBytes is the number of body bytes passed to a response tee
+// registered without a limit.
+const DefaultTeeBytes = 64 << 10
+
+// WithResponseTee registers tee to be called after every call that got a
+// response, with the operation, the status and the first maxBytes of the
+// raw body, for audit logs or offline analysis. A maxBytes of 0 or less
+// means DefaultTeeBytes. The body is a copy tee may keep.
+//
+// The body of error responses the client would leave unread is read for
+// the tee and restored, so RequestError.Response still has it.
+func WithResponseTee(tee func(op Operation, status int, body []byte), maxBytes int) ClientOption {
+	return func(client *Client) {
+		if maxBytes <= 0 {
+			maxBytes = DefaultTeeBytes
+		}
+		client.tee = tee
+		client.teeBytes = maxBytes
+	}
+}
+
+// teeResponse passes the body of res to the tee, if any.
+func (c *Client) teeResponse(op Operation, res *http.Response, body []byte) {
+	if c.tee == nil {
+		return
+	}
+	if len(body) > c.teeBytes {
+		body = body[:c.teeBytes]
+	}
+	c.tee(op, res.StatusCode, append([]byte(nil), body...))
+}
+
+// teeUnread reads the body of res, left unread otherwise, for the tee,
+// if any, and restores it.
+func (c *Client) teeUnread(ctx context.Context, op Operation, res *http.Response) Error {
+	if c.tee == nil {
+		return nil
+	}
+	body, err := c.readBody(ctx, res)
+	if err != nil {
+		return err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	c.teeResponse(op, res, body)
+	return nil
+}
@@ -0,0 +1,49 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithResponseTee(t *testing.T) {
+	is := is.New(t)
+
+	status, body := http.StatusOK, `{"data":{"value":"some data"}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	defer srv.Close()
+
+	type teed struct {
+		op     Operation
+		status int
+		body   string
+	}
+	var calls []teed
+	client := NewClient(srv.URL, WithResponseTee(func(op Operation, status int, body []byte) {
+		calls = append(calls, teed{op, status, string(body)})
+	}, 16))
+
+	req := NewRequest("query {}")
+	var resp struct{ Value string }
+	is.NoErr(client.Run(context.Background(), req, &resp))
+	is.Equal(resp.Value, "some data")
+
+	status, body = http.StatusBadGateway, "upstream is down"
+	err := client.Run(context.Background(), req, nil)
+	rerr, ok := err.(*RequestError)
+	is.True(ok)
+	restored, rdErr := io.ReadAll(rerr.Response().Body)
+	is.NoErr(rdErr)
+	is.Equal(string(restored), "upstream is down")
+
+	is.Equal(len(calls), 2)
+	is.Equal(calls[0], teed{req, http.StatusOK, `{"data":{"value"`})
+	is.Equal(calls[1], teed{req, http.StatusBadGateway, "upstream is down"})
+}
@@ -0,0 +1,102 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TimeFormat selects how time.Time variables are encoded, since the
+// DateTime scalars of backends differ.
+type TimeFormat int
+
+const (
+	// TimeRFC3339Nano encodes times as RFC 3339 strings with fractional
+	// seconds, as encoding/json does.
+	TimeRFC3339Nano TimeFormat = iota + 1
+	// TimeRFC3339 encodes times as RFC 3339 strings without fractional
+	// seconds.
+	TimeRFC3339
+	// TimeUnix encodes times as numbers of seconds since the Unix epoch.
+	TimeUnix
+	// TimeUnixMilli encodes times as numbers of milliseconds since the
+	// Unix epoch.
+	TimeUnixMilli
+)
+
+// WithTimeFormat sets how the time.Time and Time values of variables,
+// including nested ones, are encoded. Operations may override it with
+// Req.SetTimeFormat. Responses are best decoded into Time, which accepts
+// all the formats.
+func WithTimeFormat(format TimeFormat) ClientOption {
+	return func(client *Client) {
+		client.timeFormat = format
+	}
+}
+
+// Time is a time.Time that decodes from RFC 3339 strings as well as Unix
+// timestamps in seconds or milliseconds. Numbers above 1e11, which would
+// be seconds past the year 5000, are taken as milliseconds.
+type Time struct {
+	time.Time
+}
+
+// unixMilliThreshold separates timestamps in seconds from timestamps in
+// milliseconds, see Time.
+const unixMilliThreshold = 1e11
+
+// UnmarshalJSON decodes an RFC 3339 string or a Unix timestamp.
+func (t *Time) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		return t.Time.UnmarshalJSON(b)
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return errors.Errorf("time must be a string or a number, got %s", b)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	if f >= unixMilliThreshold || f <= -unixMilliThreshold {
+		t.Time = time.Unix(0, int64(f*float64(time.Millisecond)))
+	} else {
+		t.Time = time.Unix(0, int64(f*float64(time.Second)))
+	}
+	return nil
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	graphqlTimeType = reflect.TypeOf(Time{})
+)
+
+// timeConverter encodes time.Time and Time values in format.
+func timeConverter(format TimeFormat) varConverter {
+	return func(v reflect.Value) (interface{}, bool) {
+		var t time.Time
+		switch v.Type() {
+		case timeType:
+			t = v.Interface().(time.Time)
+		case graphqlTimeType:
+			t = v.Interface().(Time).Time
+		default:
+			return nil, false
+		}
+		switch format {
+		case TimeRFC3339:
+			return t.Format(time.RFC3339), true
+		case TimeUnix:
+			return t.Unix(), true
+		case TimeUnixMilli:
+			return t.UnixNano() / int64(time.Millisecond), true
+		}
+		return t.Format(time.RFC3339Nano), true
+	}
+}
@@ -0,0 +1,70 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestWithTimeFormat(t *testing.T) {
+	is := is.New(t)
+
+	var vars map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct{ Variables map[string]interface{} }
+		is.NoErr(json.NewDecoder(r.Body).Decode(&payload))
+		vars = payload.Variables
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	at := time.Date(2024, 5, 1, 12, 30, 0, 500000000, time.UTC)
+	type input struct {
+		From  time.Time  `json:"from"`
+		Until *time.Time `json:"until,omitempty"`
+		Seen  Time       `json:"seen"`
+	}
+	req := NewRequest("query ($at: DateTime, $input: Input) { a }")
+	req.Var("at", at)
+	req.Var("input", input{From: at, Seen: Time{at}})
+
+	is.NoErr(NewClient(srv.URL).Run(ctx, req, nil))
+	is.Equal(vars["at"], "2024-05-01T12:30:00.5Z")
+
+	client := NewClient(srv.URL, WithTimeFormat(TimeUnix))
+	is.NoErr(client.Run(ctx, req, nil))
+	is.Equal(vars["at"], float64(1714566600))
+	is.Equal(vars["input"], map[string]interface{}{"from": float64(1714566600), "seen": float64(1714566600)})
+	is.Equal(req.Vars()["at"], at)
+
+	req.Request().SetTimeFormat(TimeUnixMilli)
+	req.Var("input", input{From: at, Until: &at})
+	is.NoErr(client.Run(ctx, req, nil))
+	is.Equal(vars["at"], float64(1714566600500))
+	is.Equal(vars["input"].(map[string]interface{})["until"], float64(1714566600500))
+
+	req.Request().SetTimeFormat(TimeRFC3339)
+	is.NoErr(client.Run(ctx, req, nil))
+	is.Equal(vars["at"], "2024-05-01T12:30:00Z")
+}
+
+func TestTimeUnmarshalJSON(t *testing.T) {
+	is := is.New(t)
+
+	var times []Time
+	is.NoErr(json.Unmarshal([]byte(`["2024-05-01T12:30:00Z", 1714566600, 1714566600500, 1714566600.5, null]`), &times))
+	at := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	is.True(times[0].Equal(at))
+	is.True(times[1].Equal(at))
+	is.True(times[2].Equal(at.Add(500 * time.Millisecond)))
+	is.True(times[3].Equal(at.Add(500 * time.Millisecond)))
+	is.True(times[4].IsZero())
+	is.True(json.Unmarshal([]byte(`true`), &times[0]) != nil)
+}
@@ -0,0 +1,70 @@
+package graphql
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// unixEndpoint splits an endpoint like
+// unix:///var/run/gateway.sock?path=/graphql into the path of the socket
+// and the http endpoint the requests are sent to, keeping the other query
+// parameters. ok is false for other endpoints.
+func unixEndpoint(endpoint string) (socket, httpEndpoint string, ok bool) {
+	if !strings.HasPrefix(endpoint, "unix://") {
+		return "", "", false
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Path == "" {
+		return "", "", false
+	}
+	query := u.Query()
+	path := query.Get("path")
+	query.Del("path")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	target := url.URL{Scheme: "http", Host: "unix", Path: path, RawQuery: query.Encode()}
+	return u.Path, target.String(), true
+}
+
+// dialUnix gets a client whose connections are made to the Unix domain
+// socket at socket, whatever the host of the requests. A nil client is
+// replaced by a new one, and an *http.Client using an *http.Transport, or
+// the default one, by a copy using a copy of the transport; ok is false
+// for other clients, which are returned as they are.
+func dialUnix(client CustomHttpClient, socket string) (_ CustomHttpClient, ok bool) {
+	if client == nil {
+		return &http.Client{Transport: unixTransport(http.DefaultTransport.(*http.Transport), socket)}, true
+	}
+	hc, ok := client.(*http.Client)
+	if !ok {
+		return client, false
+	}
+	var base *http.Transport
+	switch t := hc.Transport.(type) {
+	case nil:
+		base = http.DefaultTransport.(*http.Transport)
+	case *http.Transport:
+		base = t
+	default:
+		return client, false
+	}
+	copied := *hc
+	copied.Transport = unixTransport(base, socket)
+	return &copied, true
+}
+
+// unixTransport copies base, dialing socket instead of the host of the
+// requests.
+func unixTransport(base *http.Transport, socket string) *http.Transport {
+	transport := base.Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", socket)
+	}
+	return transport
+}
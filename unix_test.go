@@ -0,0 +1,54 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestUnixEndpoint(t *testing.T) {
+	is := is.New(t)
+
+	socket := filepath.Join(t.TempDir(), "gateway.sock")
+	l, err := net.Listen("unix", socket)
+	is.NoErr(err)
+	var path, region string
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, region = r.URL.Path, r.URL.Query().Get("region")
+		_, _ = io.WriteString(w, `{"data":{"merchant":{"name":"Bakery"}}}`)
+	})}
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Close()
+
+	client, cerr := NewClientE("unix://" + socket + "?path=/graphql&region=eu")
+	is.NoErr(cerr)
+	var resp struct{ Merchant struct{ Name string } }
+	is.NoErr(client.Run(context.Background(), NewRequest("{ merchant { name } }"), &resp))
+	is.Equal(resp.Merchant.Name, "Bakery")
+	is.Equal(path, "/graphql")
+	is.Equal(region, "eu")
+
+	// Provided clients are copied to dial the socket.
+	provided := &http.Client{}
+	client, cerr = NewClientE("unix://"+socket+"?path=/graphql", WithHTTPClient(provided))
+	is.NoErr(cerr)
+	is.NoErr(client.Run(context.Background(), NewRequest("{ merchant { name } }"), nil))
+	is.Equal(path, "/graphql")
+	is.Equal(provided.Transport, nil)
+
+	// Other clients cannot be made to.
+	_, cerr = NewClientE("unix://"+socket, WithHTTPClient(&http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}))
+	is.True(cerr != nil)
+
+	socket, endpoint, ok := unixEndpoint("unix:///run/gw.sock")
+	is.True(ok)
+	is.Equal(socket, "/run/gw.sock")
+	is.Equal(endpoint, "http://unix/")
+	_, _, ok = unixEndpoint("https://example.com/graphql")
+	is.True(!ok)
+}
@@ -0,0 +1,170 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Upload is a file passed as a variable of the Upload scalar:
+//
+//	req.Var("avatar", graphql.Upload{Filename: "me.png", ContentType: "image/png", R: f})
+//
+// It is sent as null in the variables and as a file part of the
+// multipart/form-data body, listed in the "map" field under its path
+// following the GraphQL multipart request specification, e.g.
+// {"0": ["variables.avatar"]}. Uploads may be nested in lists and input
+// objects and require a client created with UseMultipartForm.
+type Upload struct {
+	Filename    string
+	ContentType string
+	R           io.Reader
+}
+
+// MarshalJSON encodes the upload as null, its content being sent apart.
+func (Upload) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// upload is an Upload found in the variables at path.
+type upload struct {
+	path   string
+	upload Upload
+}
+
+var uploadType = reflect.TypeOf(Upload{})
+
+// findUploads lists the uploads of vars with their paths, in the order of
+// the variable names.
+func findUploads(vars map[string]interface{}) []upload {
+	var uploads []upload
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		uploads = appendUploads(uploads, "variables."+name, reflect.ValueOf(vars[name]), make(visitSet))
+	}
+	return uploads
+}
+
+// appendUploads appends the uploads found in v, skipping the values
+// referencing themselves, which encoding the variables reports.
+func appendUploads(uploads []upload, path string, v reflect.Value, visiting visitSet) []upload {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return uploads
+		}
+		if v.Kind() == reflect.Ptr {
+			key, ok := visiting.enter(v)
+			if !ok {
+				return uploads
+			}
+			defer delete(visiting, key)
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return uploads
+	}
+	if v.Type() == uploadType {
+		return append(uploads, upload{path: path, upload: v.Interface().(Upload)})
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return uploads
+		}
+		key, ok := visiting.enter(v)
+		if !ok {
+			return uploads
+		}
+		defer delete(visiting, key)
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			uploads = appendUploads(uploads, path+"."+key, v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())), visiting)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return uploads
+		}
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return uploads
+			}
+			key, ok := visiting.enter(v)
+			if !ok {
+				return uploads
+			}
+			defer delete(visiting, key)
+		}
+		for i := 0; i < v.Len(); i++ {
+			uploads = appendUploads(uploads, path+"."+strconv.Itoa(i), v.Index(i), visiting)
+		}
+	case reflect.Struct:
+		// Fields are found under their path in the variables JSON, so
+		// the ones of embedded structs are flattened.
+		for _, field := range jsonFields(v.Type()) {
+			if value, ok := fieldByIndex(v, field.index); ok {
+				uploads = appendUploads(uploads, path+"."+field.name, value, visiting)
+			}
+		}
+	}
+	return uploads
+}
+
+// writeUploads writes the "map" field and the file parts of uploads,
+// named after their index.
+func writeUploads(writer *multipart.Writer, uploads []upload) error {
+	fileMap := make(map[string][]string, len(uploads))
+	for i, u := range uploads {
+		fileMap[strconv.Itoa(i)] = []string{u.path}
+	}
+	mapField, err := writer.CreateFormField("map")
+	if err != nil {
+		return errors.Wrap(err, "create map field")
+	}
+	if err := json.NewEncoder(mapField).Encode(fileMap); err != nil {
+		return errors.Wrap(err, "encode map")
+	}
+	for i, u := range uploads {
+		if u.upload.R == nil {
+			return errors.Errorf("upload %s has no reader", u.path)
+		}
+		contentType := u.upload.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%d"; filename="%s"`, i, escapeQuotes(u.upload.Filename)))
+		h.Set("Content-Type", contentType)
+		part, err := writer.CreatePart(h)
+		if err != nil {
+			return errors.Wrap(err, "create upload part")
+		}
+		if _, err := io.Copy(part, u.upload.R); err != nil {
+			return errors.Wrapf(err, "preparing upload %s", u.path)
+		}
+	}
+	return nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// escapeQuotes escapes a file name as mime/multipart does.
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,78 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestUpload(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.NoErr(r.ParseMultipartForm(1 << 20))
+		is.Equal(r.FormValue("variables"), `{"input":{"title":"report","attachments":[null,null]},"logo":null}`+"\n")
+		is.Equal(r.FormValue("map"), `{"0":["variables.input.attachments.0"],"1":["variables.input.attachments.1"],"2":["variables.logo"]}`+"\n")
+
+		for field, want := range map[string]string{"0": "a", "1": "b", "2": "png"} {
+			f, h, err := r.FormFile(field)
+			is.NoErr(err)
+			b, _ := io.ReadAll(f)
+			is.Equal(string(b), want)
+			if field == "2" {
+				is.Equal(h.Filename, `lo"go.png`)
+				is.Equal(h.Header.Get("Content-Type"), "image/png")
+			} else {
+				is.Equal(h.Header.Get("Content-Type"), "application/octet-stream")
+			}
+		}
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	type input struct {
+		Title       string    `json:"title"`
+		Attachments []*Upload `json:"attachments"`
+	}
+	req := NewMutation("mutation ($input: Input!, $logo: Upload) { a }")
+	req.Var("input", input{
+		Title: "report",
+		Attachments: []*Upload{
+			{Filename: "a.txt", R: strings.NewReader("a")},
+			{Filename: "b.txt", R: strings.NewReader("b")},
+		},
+	})
+	req.Var("logo", Upload{Filename: `lo"go.png`, ContentType: "image/png", R: strings.NewReader("png")})
+
+	err := NewClient(srv.URL).Run(context.Background(), req, nil)
+	is.Equal(err.Error(), "cannot send files with PostFields option")
+	is.NoErr(NewClient(srv.URL, UseMultipartForm()).Run(context.Background(), req, nil))
+}
+
+func TestUploadEmbedded(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.NoErr(r.ParseMultipartForm(1 << 20))
+		is.Equal(r.FormValue("variables"), `{"in":{"file":null,"title":"report"}}`+"\n")
+		is.Equal(r.FormValue("map"), `{"0":["variables.in.file"]}`+"\n")
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	type Base struct {
+		File *Upload `json:"file"`
+	}
+	type input struct {
+		Base
+		Title string `json:"title"`
+	}
+	req := NewMutation("mutation ($in: Input!) { a }")
+	req.Var("in", input{Base: Base{File: &Upload{Filename: "a.txt", R: strings.NewReader("a")}}, Title: "report"})
+	is.NoErr(NewClient(srv.URL, UseMultipartForm()).Run(context.Background(), req, nil))
+}
@@ -0,0 +1,119 @@
+package graphql
+
+import (
+	"encoding/hex"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// UUID is a UUID scalar. It has the layout of github.com/google/uuid.UUID
+// and of other [16]byte UUID types, which convert to and from it, and is
+// encoded as the usual 36 character string.
+//
+// Response fields of type UUID, or of types decoding themselves such as
+// uuid.UUID, decode from UUID strings in every response. Fields of
+// [16]byte types without their own decoding only do in mutation
+// payloads; use UUID for them in queries.
+type UUID [16]byte
+
+// ParseUUID parses the string form of a UUID, with or without braces or
+// the urn:uuid: prefix.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	switch len(s) {
+	case 36 + 9:
+		if s[:9] != "urn:uuid:" {
+			return u, errors.Errorf("invalid UUID %q", s)
+		}
+		s = s[9:]
+	case 36 + 2:
+		if s[0] != '{' || s[37] != '}' {
+			return u, errors.Errorf("invalid UUID %q", s)
+		}
+		s = s[1:37]
+	case 32:
+		if _, err := hex.Decode(u[:], []byte(s)); err != nil {
+			return UUID{}, errors.Errorf("invalid UUID %q", s)
+		}
+		return u, nil
+	}
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, errors.Errorf("invalid UUID %q", s)
+	}
+	hexDigits := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36]
+	if _, err := hex.Decode(u[:], []byte(hexDigits)); err != nil {
+		return UUID{}, errors.Errorf("invalid UUID %q", s)
+	}
+	return u, nil
+}
+
+// String formats the UUID, e.g. "6ba7b810-9dad-11d1-80b4-00c04fd430c8".
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// MarshalText formats the UUID.
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText parses the UUID.
+func (u *UUID) UnmarshalText(b []byte) error {
+	parsed, err := ParseUUID(string(b))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+// WithUUIDArrays sends [16]byte variables, including named types without
+// their own encoding, as UUID strings rather than arrays of numbers. It
+// does not change how responses are decoded, see UUID.
+func WithUUIDArrays() ClientOption {
+	return func(client *Client) {
+		client.uuidArrays = true
+	}
+}
+
+// isUUIDArray reports whether t has the layout of a UUID.
+func isUUIDArray(t reflect.Type) bool {
+	return t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8
+}
+
+// uuidConverter sends [16]byte values as UUID strings.
+func uuidConverter(v reflect.Value) (interface{}, bool) {
+	if !isUUIDArray(v.Type()) || isMarshaler(v.Type()) {
+		return nil, false
+	}
+	var u UUID
+	reflect.Copy(reflect.ValueOf(&u).Elem(), v)
+	return u.String(), true
+}
+
+// decodeUUIDHook lets mapstructure decode the strings of mutation
+// payloads into [16]byte UUID types.
+func decodeUUIDHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	s, ok := data.(string)
+	if !ok || !isUUIDArray(to) {
+		return data, nil
+	}
+	u, err := ParseUUID(s)
+	if err != nil {
+		return nil, err
+	}
+	out := reflect.New(to).Elem()
+	reflect.Copy(out, reflect.ValueOf(u))
+	return out.Interface(), nil
+}
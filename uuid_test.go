@@ -0,0 +1,89 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// externalUUID stands for UUID types of other packages without their own
+// encoding.
+type externalUUID [16]byte
+
+func TestParseUUID(t *testing.T) {
+	is := is.New(t)
+
+	const s = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	for _, in := range []string{s, "{" + s + "}", "urn:uuid:" + s, strings.ReplaceAll(s, "-", ""), strings.ToUpper(s)} {
+		u, err := ParseUUID(in)
+		is.NoErr(err)
+		is.Equal(u.String(), s)
+	}
+	_, err := ParseUUID("6ba7b810-9dad-11d1-80b4")
+	is.Equal(err.Error(), `invalid UUID "6ba7b810-9dad-11d1-80b4"`)
+
+	var resp struct{ ID UUID }
+	is.NoErr(json.Unmarshal([]byte(`{"id":"`+s+`"}`), &resp))
+	b, err := json.Marshal(resp)
+	is.NoErr(err)
+	is.Equal(string(b), `{"ID":"`+s+`"}`)
+}
+
+func TestWithUUIDArrays(t *testing.T) {
+	is := is.New(t)
+
+	const s = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	var vars map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct{ Variables map[string]interface{} }
+		is.NoErr(json.NewDecoder(r.Body).Decode(&payload))
+		vars = payload.Variables
+		_, _ = io.WriteString(w, `{"data":{"createMerchant":{"successful":true,"result":{"id":"`+s+`"}}}}`)
+	}))
+	defer srv.Close()
+
+	u, _ := ParseUUID(s)
+	req := NewMutation("mutation ($id: UUID!, $ids: [UUID!]) { createMerchant { result { id } } }")
+	req.Var("id", externalUUID(u))
+	req.Var("ids", []externalUUID{externalUUID(u)})
+
+	var resp struct {
+		CreateMerchant struct {
+			Result struct{ ID externalUUID }
+		}
+	}
+	is.NoErr(NewClient(srv.URL, WithUUIDArrays()).Run(context.Background(), req, &resp))
+	is.Equal(vars["id"], s)
+	is.Equal(vars["ids"], []interface{}{s})
+	is.Equal(resp.CreateMerchant.Result.ID, externalUUID(u))
+}
+
+func TestUUIDQueryResponse(t *testing.T) {
+	is := is.New(t)
+
+	const s = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{"merchant":{"id":"`+s+`"}}}`)
+	}))
+	defer srv.Close()
+	client := NewClient(srv.URL, WithUUIDArrays())
+
+	var resp struct {
+		Merchant struct{ ID UUID }
+	}
+	is.NoErr(client.Run(context.Background(), NewRequest("query { merchant { id } }"), &resp))
+	is.Equal(resp.Merchant.ID.String(), s)
+
+	// Plain [16]byte fields only decode from strings in mutation payloads.
+	var plain struct {
+		Merchant struct{ ID externalUUID }
+	}
+	err := client.Run(context.Background(), NewRequest("query { merchant { id } }"), &plain)
+	is.True(err != nil)
+}
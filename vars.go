@@ -0,0 +1,266 @@
+package graphql
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// varConverter converts a variable value, or a value nested in it, before
+// it is encoded to JSON. It reports whether it handled the value.
+type varConverter func(v reflect.Value) (interface{}, bool)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// varConverters gets the converters applied to the variables of req.
+func (c *Client) varConverters(req *Req) []varConverter {
+	var convs []varConverter
+	if format := req.timeFormat; format != 0 {
+		convs = append(convs, timeConverter(format))
+	} else if c.timeFormat != 0 {
+		convs = append(convs, timeConverter(c.timeFormat))
+	}
+	if c.numericStrings {
+		convs = append(convs, numericConverter)
+	}
+	if c.uuidArrays {
+		convs = append(convs, uuidConverter)
+	}
+	if c.moneyFormat != 0 {
+		convs = append(convs, moneyConverter(c.moneyFormat))
+	}
+	if c.geoFormat != 0 {
+		convs = append(convs, geoConverter(c.geoFormat))
+	}
+	if len(c.enums) > 0 {
+		convs = append(convs, c.enumConverter)
+	}
+	return convs
+}
+
+// withConvertedVars returns req, or a copy of it whose variables went
+// through the converters of the client.
+func (c *Client) withConvertedVars(req *Req) *Req {
+	convs := c.varConverters(req)
+	if len(convs) == 0 || len(req.vars) == 0 {
+		return req
+	}
+	converted := *req
+	converted.vars = make(map[string]interface{}, len(req.vars))
+	for key, value := range req.vars {
+		converted.vars[key] = convertVar(reflect.ValueOf(value), convs)
+	}
+	return &converted
+}
+
+// Payload returns the JSON payload c posts for op as application/json,
+// with the options of c applied, e.g. WithTimeFormat, and the values of
+// redacted variables masked as Req.MarshalJSON does. Clients using
+// UseGET, UseGraphQLBody or UseMultipartForm send op in another form.
+func (c *Client) Payload(op Operation) ([]byte, error) {
+	req := c.withConvertedVars(op.Request())
+	p := c.payload(req)
+	p.Variables = req.redactedVars()
+	return json.Marshal(p)
+}
+
+// ConvertVars returns the variables of op converted as configured on c,
+// e.g. by WithTimeFormat, as they are encoded in requests.
+func (c *Client) ConvertVars(op Operation) map[string]interface{} {
+	return c.withConvertedVars(op.Request()).vars
+}
+
+// convertVar applies convs to v and the values nested in it. Maps,
+// slices and structs are rebuilt as the generic values encoding/json
+// would produce, following json tags; values implementing json.Marshaler
+// or encoding.TextMarshaler are kept as they are unless a converter
+// handles them. Values referencing themselves are left unconverted, so
+// encoding them reports the cycle as it did before conversion.
+func convertVar(v reflect.Value, convs []varConverter) interface{} {
+	conv := &conversion{convs: convs, visiting: make(visitSet)}
+	return conv.value(v)
+}
+
+// conversion holds the state of convertVar: the pointers, maps and
+// slices on the path to the value being converted.
+type conversion struct {
+	convs    []varConverter
+	visiting visitSet
+}
+
+// visit identifies a pointer, map or slice by what it points to.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// visitSet holds the pointers, maps and slices on the path to a value
+// while walking nested values, to detect those referencing themselves.
+type visitSet map[visit]struct{}
+
+// enter adds v to the set, reporting false when it already is in it,
+// i.e. v references itself.
+func (s visitSet) enter(v reflect.Value) (visit, bool) {
+	key := visit{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	if _, ok := s[key]; ok {
+		return key, false
+	}
+	s[key] = struct{}{}
+	return key, true
+}
+
+func (conv *conversion) value(v reflect.Value) interface{} {
+	// marshalerPtr is a pointer whose type, unlike the one of its
+	// element, marshals itself.
+	var marshalerPtr reflect.Value
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr {
+			key, ok := conv.visiting.enter(v)
+			if !ok {
+				return v.Interface()
+			}
+			defer delete(conv.visiting, key)
+			if isMarshaler(v.Type()) {
+				marshalerPtr = v
+			}
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	for _, c := range conv.convs {
+		if out, ok := c(v); ok {
+			return out
+		}
+	}
+	if isMarshaler(v.Type()) {
+		return v.Interface()
+	}
+	if marshalerPtr.IsValid() {
+		return marshalerPtr.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		key, ok := conv.visiting.enter(v)
+		if !ok {
+			return v.Interface()
+		}
+		defer delete(conv.visiting, key)
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = conv.value(iter.Value())
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+			return v.Interface()
+		}
+		if v.Kind() == reflect.Slice {
+			key, ok := conv.visiting.enter(v)
+			if !ok {
+				return v.Interface()
+			}
+			defer delete(conv.visiting, key)
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = conv.value(v.Index(i))
+		}
+		return s
+	case reflect.Struct:
+		m := make(map[string]interface{}, v.NumField())
+		conv.fields(v, m)
+		return m
+	}
+	return v.Interface()
+}
+
+// fields adds the fields of the struct v to m as encoding/json encodes
+// them, see jsonFields. Fields with the string option are encoded as
+// encoding/json does, unconverted.
+func (conv *conversion) fields(v reflect.Value, m map[string]interface{}) {
+	for _, field := range jsonFields(v.Type()) {
+		value, ok := fieldByIndex(v, field.index)
+		if !ok || field.omitEmpty && isEmptyValue(value) {
+			continue
+		}
+		if field.quoted {
+			m[field.name] = quotedValue(value)
+			continue
+		}
+		m[field.name] = conv.value(value)
+	}
+}
+
+// quotedValue encodes v as a field with the string option: its JSON
+// encoding within a string, or null for nil pointers.
+func quotedValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return v.Interface()
+	}
+	return string(b)
+}
+
+// jsonField gets the JSON name of an exported struct field and whether
+// it is omitted when empty. ok is false for fields encoding/json skips.
+func jsonField(field reflect.StructField) (name string, omitEmpty, ok bool) {
+	tag := field.Tag.Get("json")
+	if field.PkgPath != "" || tag == "-" {
+		return "", false, false
+	}
+	name, opts := tag, ""
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		name, opts = tag[:i], tag[i:]
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(opts, ",omitempty"), true
+}
+
+// isMarshaler reports whether values of t encode themselves.
+func isMarshaler(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// isEmptyValue reports whether v is omitted by omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+)
+
+type rawName string
+
+func (n rawName) MarshalText() ([]byte, error) {
+	return []byte("name:" + string(n)), nil
+}
+
+func TestConvertVar(t *testing.T) {
+	is := is.New(t)
+
+	type Embedded struct {
+		E string `json:"e"`
+	}
+	type input struct {
+		Embedded
+		Name     rawName `json:"name"`
+		Skipped  string  `json:"-"`
+		Empty    string  `json:"empty,omitempty"`
+		Plain    int
+		List     []int             `json:"list"`
+		Bytes    []byte            `json:"bytes"`
+		Labels   map[string]string `json:"labels"`
+		Optional *int              `json:"optional"`
+		hidden   string
+	}
+	timesTen := func(v reflect.Value) (interface{}, bool) {
+		if v.Kind() == reflect.Int {
+			return v.Int() * 10, true
+		}
+		return nil, false
+	}
+
+	in := input{
+		Embedded: Embedded{E: "e"},
+		Name:     "n",
+		Skipped:  "x",
+		Plain:    1,
+		List:     []int{2, 3},
+		Bytes:    []byte("b"),
+		Labels:   map[string]string{"k": "v"},
+		hidden:   "h",
+	}
+	got, err := json.Marshal(convertVar(reflect.ValueOf(&in), []varConverter{timesTen}))
+	is.NoErr(err)
+	is.Equal(string(got), `{"Plain":10,"bytes":"Yg==","e":"e","labels":{"k":"v"},"list":[20,30],"name":"name:n","optional":null}`)
+}
+
+func TestConvertVarCycle(t *testing.T) {
+	is := is.New(t)
+
+	type node struct {
+		Name string
+		Next *node
+	}
+	n := &node{Name: "a"}
+	n.Next = &node{Name: "b", Next: n}
+	shared := &node{Name: "c"}
+
+	client := NewClient("https://example.com/graphql", WithTimeFormat(TimeUnixMilli))
+	req := NewRequest("query ($n: Node) { ok }")
+	req.Var("n", n)
+	err := client.Run(context.Background(), req, nil)
+	var verr *VariableError
+	is.True(errors.As(err, &verr))
+	is.Equal(verr.Name, "n")
+	err = NewClient("https://example.com/graphql").Run(context.Background(), req, nil)
+	is.True(errors.As(err, &verr))
+
+	// Values referenced twice without a cycle are converted.
+	got, merr := json.Marshal(convertVar(reflect.ValueOf([]*node{shared, shared}), nil))
+	is.NoErr(merr)
+	is.Equal(string(got), `[{"Name":"c","Next":null},{"Name":"c","Next":null}]`)
+}
+
+type convertBase struct {
+	ID string `json:"id"`
+}
+
+type ConvertTagged struct {
+	Name string `json:"name"`
+}
+
+type ConvertOther struct {
+	Name string `json:"name"`
+}
+
+type ConvertPlain struct {
+	Name  string
+	Label string
+}
+
+func TestConvertVarFollowsEncodingJSON(t *testing.T) {
+	is := is.New(t)
+
+	type input struct {
+		convertBase
+		*ConvertTagged
+		ConvertOther
+		ConvertPlain
+		Amount   int64   `json:"amount,string"`
+		Flag     *bool   `json:"flag,string"`
+		Text     string  `json:"text,string"`
+		Rate     float64 `json:"rate,string,omitempty"`
+		Label    string
+		Embedded *convertBase `json:"embedded"`
+	}
+	flag := true
+	for _, in := range []input{
+		{convertBase: convertBase{ID: "x1"}, ConvertTagged: &ConvertTagged{Name: "n"}, ConvertPlain: ConvertPlain{Name: "p", Label: "l"}, Amount: 5, Flag: &flag, Text: "t", Rate: 0.5, Label: "outer"},
+		{convertBase: convertBase{ID: "x2"}, Amount: 1 << 60},
+	} {
+		b, err := json.Marshal(in)
+		is.NoErr(err)
+		var want interface{}
+		is.NoErr(json.Unmarshal(b, &want))
+		b, err = json.Marshal(convertVar(reflect.ValueOf(in), []varConverter{func(reflect.Value) (interface{}, bool) { return nil, false }}))
+		is.NoErr(err)
+		var got interface{}
+		is.NoErr(json.Unmarshal(b, &got))
+		is.Equal(got, want)
+	}
+}
+
+func TestConvertVarEmbeddedAndQuoted(t *testing.T) {
+	is := is.New(t)
+
+	type input struct {
+		convertBase
+		Amount int64     `json:"amount,string"`
+		At     time.Time `json:"at"`
+	}
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct{ Variables json.RawMessage }
+		is.NoErr(json.NewDecoder(r.Body).Decode(&payload))
+		body = string(payload.Variables)
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	req := NewRequest("query ($in: Input) { ok }")
+	req.Var("in", input{convertBase: convertBase{ID: "x1"}, Amount: 5, At: time.Unix(60, 0)})
+	is.NoErr(NewClient(srv.URL, WithTimeFormat(TimeUnix)).Run(context.Background(), req, nil))
+	is.Equal(body, `{"in":{"amount":"5","at":60,"id":"x1"}}`)
+}
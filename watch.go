@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WatchUpdate is a result delivered by Watch: a changed response, or
+// the error of a run.
+type WatchUpdate struct {
+	// Resp is a new value of the type of the resp passed to Watch.
+	Resp interface{}
+	Err  Error
+}
+
+// Watch runs op, usually a query, every interval until ctx is done and
+// delivers its response only when it differs from the previous one, as a
+// poor man's subscription for servers without one. resp is a pointer
+// showing the type to decode into; each update holds a new one. Errors
+// are delivered too, and the next response after an error is always
+// delivered. The channel is closed once ctx is done, or after the error
+// of an invalid resp or of an interval that is not positive.
+//
+//	for update := range client.Watch(ctx, req, &balanceResp{}, 10*time.Second) {
+//		if update.Err != nil {
+//			continue
+//		}
+//		show(update.Resp.(*balanceResp))
+//	}
+func (c *Client) Watch(ctx context.Context, op Operation, resp interface{}, interval time.Duration) <-chan WatchUpdate {
+	updates := make(chan WatchUpdate)
+	t := reflect.TypeOf(resp)
+	go func() {
+		defer close(updates)
+		var invalid error
+		switch {
+		case t == nil || t.Kind() != reflect.Ptr:
+			invalid = errors.Errorf("resp must be a pointer, got %T", resp)
+		case interval <= 0:
+			invalid = errors.Errorf("interval %s must be positive", interval)
+		}
+		if invalid != nil {
+			select {
+			case updates <- WatchUpdate{Err: NewExecutionError(invalid)}:
+			case <-ctx.Done():
+			}
+			return
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var (
+			last    [sha256.Size]byte
+			changed = true
+		)
+		for {
+			update := WatchUpdate{Resp: reflect.New(t.Elem()).Interface()}
+			if update.Err = c.Run(ctx, op, update.Resp); update.Err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				update.Resp = nil
+				changed = true
+			} else if data, err := json.Marshal(update.Resp); err != nil {
+				update = WatchUpdate{Err: NewExecutionError(errors.Wrap(err, "hashing response"))}
+			} else if sum := sha256.Sum256(data); changed || sum != last {
+				last, changed = sum, false
+			} else {
+				update = WatchUpdate{}
+			}
+			if update.Resp != nil || update.Err != nil {
+				select {
+				case updates <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return updates
+}
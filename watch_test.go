@@ -0,0 +1,69 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestWatch(t *testing.T) {
+	is := is.New(t)
+
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	bodies := []string{`{"data":{"balance":1}}`, `{"data":{"balance":1}}`, `{"data":{"balance":2}}`, ``, `{"data":{"balance":2}}`}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body := bodies[len(bodies)-1]
+		if calls < len(bodies) {
+			body = bodies[calls]
+		}
+		calls++
+		if body == "" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = io.WriteString(w, body)
+	}))
+	defer srv.Close()
+
+	type balance struct{ Balance int }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := NewClient(srv.URL).Watch(ctx, NewRequest("query { balance }"), &balance{}, time.Millisecond)
+
+	var got []interface{}
+	for update := range updates {
+		if update.Err != nil {
+			got = append(got, update.Err.Code())
+		} else {
+			got = append(got, update.Resp.(*balance).Balance)
+		}
+		if len(got) == 4 {
+			cancel()
+		}
+	}
+	// The unchanged response is skipped, the one after the error is not.
+	is.Equal(got, []interface{}{1, 2, "Bad Gateway", 2})
+}
+
+func TestWatchInvalidInterval(t *testing.T) {
+	is := is.New(t)
+
+	type balance struct{ Balance int }
+	updates := NewClient("https://example.com/graphql").Watch(context.Background(), NewRequest("query { balance }"), &balance{}, 0)
+	update, ok := <-updates
+	is.True(ok)
+	is.True(update.Err != nil)
+	_, ok = <-updates
+	is.True(!ok)
+}